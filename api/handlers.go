@@ -0,0 +1,372 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/pipeline"
+	"github.com/mariotoffia/gocryptoadmin/processors"
+	"github.com/mariotoffia/gocryptoadmin/store"
+	"github.com/mariotoffia/gocryptoadmin/txhistory"
+)
+
+// ImportResponse is the result of a upload.
+type ImportResponse struct {
+	Record store.ImportRecord `json:"record"`
+	// Imported is `false` when the same export already has been imported.
+	Imported bool `json:"imported"`
+}
+
+// BalancesResponse is the balance per exchange and _all_.
+type BalancesResponse struct {
+	Balances map[string]common.AccountStatus `json:"balances"`
+}
+
+// Gain is a realized gain from a sell paired with its buys.
+type Gain struct {
+	Exchange string           `json:"exchange"`
+	Pair     string           `json:"pair"`
+	Size     float64          `json:"size"`
+	Bought   time.Time        `json:"bought"`
+	Sold     time.Time        `json:"sold"`
+	Asset    common.AssetType `json:"asset"`
+	// Cost is the translated buy price including fees (positive).
+	Cost float64 `json:"cost"`
+	// Proceeds is the translated sell price minus fees.
+	Proceeds float64 `json:"proceeds"`
+	Gain     float64 `json:"gain"`
+}
+
+// GainsResponse is the realized gains in _Asset_.
+type GainsResponse struct {
+	Asset common.AssetType `json:"asset"`
+	Gains []Gain           `json:"gains"`
+	Total float64          `json:"total"`
+}
+
+// PriceSyncRequest requests candles for the _Pairs_ from the _Exchanges_.
+type PriceSyncRequest struct {
+	Exchanges []string  `json:"exchanges"`
+	Pairs     []string  `json:"pairs"`
+	Since     time.Time `json:"since"`
+	// Interval is a `time.ParseDuration` string, default is _24h_.
+	Interval string `json:"interval,omitempty"`
+}
+
+// PriceSyncResponse reports the number of read and the number of new candles.
+type PriceSyncResponse struct {
+	Read  int `json:"read"`
+	Added int `json:"added"`
+}
+
+func (srv *Server) handleListImports(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, srv.store.GetImports())
+}
+
+func (srv *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+
+	exchange := r.URL.Query().Get("exchange")
+
+	if !srv.reader.HasReader(exchange) {
+
+		writeError(w, http.StatusBadRequest, fmt.Errorf("no reader registered for exchange: %s", exchange))
+		return
+
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+
+		writeError(w, http.StatusBadRequest, err)
+		return
+
+	}
+
+	source := r.URL.Query().Get("source")
+	if source == "" {
+		source = exchange
+	}
+
+	rec, imported, err := srv.store.Import(source, exchange, data, func(data []byte) []common.TransactionLog {
+		return srv.reader.ReadBufferAsExchange(exchange, data)
+	})
+
+	if err != nil {
+
+		writeError(w, http.StatusInternalServerError, err)
+		return
+
+	}
+
+	status := http.StatusOK
+	if imported {
+		status = http.StatusCreated
+	}
+
+	writeJSON(w, status, ImportResponse{Record: rec, Imported: imported})
+}
+
+func (srv *Server) handleTransactions(w http.ResponseWriter, r *http.Request) {
+
+	q, err := parseQuery(r)
+	if err != nil {
+
+		writeError(w, http.StatusBadRequest, err)
+		return
+
+	}
+
+	writeJSON(w, http.StatusOK, srv.store.GetTransactions(q))
+}
+
+func (srv *Server) handleBalances(w http.ResponseWriter, r *http.Request) {
+
+	q, err := parseQuery(r)
+	if err != nil {
+
+		writeError(w, http.StatusBadRequest, err)
+		return
+
+	}
+
+	acc := processors.NewMultiExchangeAccountingProcessor()
+
+	for _, tx := range srv.store.GetTransactions(q) {
+		acc.Process(tx.Clone())
+	}
+
+	writeJSON(w, http.StatusOK, BalancesResponse{Balances: acc.GetBalances()})
+}
+
+func (srv *Server) handleGains(w http.ResponseWriter, r *http.Request) {
+
+	if len(srv.translations) == 0 || len(srv.assets) == 0 {
+
+		writeError(w, http.StatusConflict, fmt.Errorf("no price translations configured"))
+		return
+
+	}
+
+	asset := common.AssetType(r.URL.Query().Get("asset"))
+	if asset == "" {
+		asset = srv.assets[0]
+	}
+
+	if !containsAsset(srv.assets, asset) {
+
+		writeError(w, http.StatusBadRequest, fmt.Errorf("asset: %s is not translated", asset))
+		return
+
+	}
+
+	q, err := parseQuery(r)
+	if err != nil {
+
+		writeError(w, http.StatusBadRequest, err)
+		return
+
+	}
+
+	// Gains are filtered on sell date but all earlier buys are needed. The _asset_ is
+	// the asset to report in and not a filter.
+	from, to := q.From, q.To
+	q.From, q.To = time.Time{}, time.Time{}
+	q.Assets = nil
+
+	resolver := txhistory.NewTxOHCResolver(
+		srv.store.FillCache(txhistory.NewTxOHCCache()),
+	).AddTranslations(srv.translations...)
+
+	costunit := processors.NewCostUnitProcessorByName(resolver, srv.calculator)
+	costunit.RegisterAsset(srv.assets...)
+
+	out := pipeline.New().
+		Add("costunit", pipeline.LogStage(costunit)).
+		Add("group", pipeline.GroupingStage(processors.NewTxGroupProcessor(0))).
+		Add("accounts", pipeline.AccountsStage(processors.NewMultiExchangeAccountingProcessor())).
+		Add("buysell", pipeline.BuySellStage(processors.NewTxBuySellProcessor())).
+		RunLogs(srv.store.GetTransactions(q))
+
+	resp := GainsResponse{Asset: asset, Gains: []Gain{}}
+
+	for _, pair := range out.BuySell {
+
+		sold := pair.GetSell().GetCreatedAt()
+
+		if (!from.IsZero() && sold.Before(from)) || (!to.IsZero() && !sold.Before(to)) {
+			continue
+		}
+
+		buy := pair.GetBuy().GetTranslatedTotalPrice(asset)
+		sell := pair.GetSell().GetTranslatedTotalPrice(asset)
+
+		gain := Gain{
+			Exchange: pair.GetExchange(),
+			Pair:     pair.GetAssetPair().String(),
+			Size:     pair.GetAssetSize(),
+			Bought:   pair.GetBuy().GetCreatedAt(),
+			Sold:     sold,
+			Asset:    asset,
+			Cost:     -buy,
+			Proceeds: sell,
+			Gain:     sell + buy,
+		}
+
+		resp.Gains = append(resp.Gains, gain)
+		resp.Total += gain.Gain
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (srv *Server) handlePriceSync(w http.ResponseWriter, r *http.Request) {
+
+	if srv.prices == nil {
+
+		writeError(w, http.StatusConflict, fmt.Errorf("no price reader configured"))
+		return
+
+	}
+
+	var req PriceSyncRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+
+		writeError(w, http.StatusBadRequest, err)
+		return
+
+	}
+
+	if len(req.Exchanges) == 0 || len(req.Pairs) == 0 {
+
+		writeError(w, http.StatusBadRequest, fmt.Errorf("both exchanges and pairs are required"))
+		return
+
+	}
+
+	interval := time.Hour * 24
+
+	if req.Interval != "" {
+
+		var err error
+		if interval, err = time.ParseDuration(req.Interval); err != nil {
+
+			writeError(w, http.StatusBadRequest, err)
+			return
+
+		}
+
+	}
+
+	resp := PriceSyncResponse{}
+
+	for _, p := range req.Pairs {
+
+		pair, err := common.ParseAssetPair(p)
+		if err != nil {
+
+			writeError(w, http.StatusBadRequest, err)
+			return
+
+		}
+
+		candles, err := srv.prices.ReadContext(
+			r.Context(), pair, req.Since, interval, req.Exchanges...,
+		)
+
+		if err != nil {
+
+			writeError(w, http.StatusBadGateway, err)
+			return
+
+		}
+
+		added, err := srv.store.AddCandles(candles)
+		if err != nil {
+
+			writeError(w, http.StatusInternalServerError, err)
+			return
+
+		}
+
+		resp.Read += len(candles)
+		resp.Added += added
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parseQuery parses the _exchange_, _asset_, _side_ (all comma separated), _from_ and
+// _to_ (_RFC3339_ or date) query parameters.
+func parseQuery(r *http.Request) (store.Query, error) {
+
+	values := r.URL.Query()
+	q := store.Query{
+		Exchanges: splitList(values.Get("exchange")),
+	}
+
+	for _, asset := range splitList(values.Get("asset")) {
+		q.Assets = append(q.Assets, common.AssetType(asset))
+	}
+
+	for _, side := range splitList(values.Get("side")) {
+		q.Sides = append(q.Sides, common.SideType(strings.ToUpper(side)))
+	}
+
+	var err error
+
+	if q.From, err = parseTime(values.Get("from")); err != nil {
+		return store.Query{}, err
+	}
+
+	if q.To, err = parseTime(values.Get("to")); err != nil {
+		return store.Query{}, err
+	}
+
+	return q, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time: %s", s)
+	}
+
+	return t, nil
+}
+
+func splitList(s string) []string {
+
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, ",")
+}
+
+func containsAsset(list []common.AssetType, asset common.AssetType) bool {
+
+	for i := range list {
+
+		if list[i] == asset {
+			return true
+		}
+
+	}
+
+	return false
+}
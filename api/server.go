@@ -0,0 +1,166 @@
+// Package api exposes the store and the processors as a small local _HTTP/JSON_ API,
+// e.g. to back a dashboard.
+//
+// The endpoints are described in the embedded _OpenAPI_ document that is served on
+// _/openapi.json_.
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/parsers"
+	"github.com/mariotoffia/gocryptoadmin/store"
+	"github.com/mariotoffia/gocryptoadmin/txhistory"
+	"github.com/mariotoffia/gocryptoadmin/txlog"
+)
+
+//go:embed openapi.json
+var openAPI []byte
+
+// OpenAPI returns the embedded _OpenAPI_ document.
+func OpenAPI() []byte {
+	return openAPI
+}
+
+// Server handles the _HTTP_ requests. All requests are serialized since the
+// `store.Store` is not safe for concurrent use.
+type Server struct {
+	mu           sync.Mutex
+	store        *store.Store
+	reader       *txlog.TxLogReaderImpl
+	prices       *txhistory.TxOHCReader
+	translations []parsers.ResolverExpression
+	assets       []common.AssetType
+	calculator   string
+}
+
+// NewServer creates a server backed by _s_ where uploaded exports are parsed using
+// the readers registered in _reader_.
+func NewServer(s *store.Store, reader *txlog.TxLogReaderImpl) *Server {
+
+	return &Server{
+		store:  s,
+		reader: reader,
+	}
+
+}
+
+// UsePriceReader sets the reader used when syncing prices.
+func (srv *Server) UsePriceReader(prices *txhistory.TxOHCReader) *Server {
+
+	srv.prices = prices
+	return srv
+
+}
+
+// UseTranslations sets the resolver expressions, and the _assets_ to translate into,
+// that are needed to calculate gains. The candles are taken from the store.
+func (srv *Server) UseTranslations(
+	expr []parsers.ResolverExpression,
+	assets ...common.AssetType,
+) *Server {
+
+	srv.translations = expr
+	srv.assets = assets
+	return srv
+
+}
+
+// UsePriceCalculator selects the price calculator, by name, when translating (default
+// is midpoint).
+func (srv *Server) UsePriceCalculator(name string) *Server {
+
+	srv.calculator = name
+	return srv
+
+}
+
+// Handler returns the `http.Handler` with all endpoints.
+func (srv *Server) Handler() http.Handler {
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/openapi.json", srv.method(http.MethodGet, srv.handleOpenAPI))
+
+	listImports := srv.method(http.MethodGet, srv.handleListImports)
+	upload := srv.method(http.MethodPost, srv.handleUpload)
+
+	mux.HandleFunc("/imports", func(w http.ResponseWriter, r *http.Request) {
+
+		if r.Method == http.MethodGet {
+			listImports(w, r)
+		} else {
+			upload(w, r)
+		}
+
+	})
+
+	mux.HandleFunc("/transactions", srv.method(http.MethodGet, srv.handleTransactions))
+	mux.HandleFunc("/balances", srv.method(http.MethodGet, srv.handleBalances))
+	mux.HandleFunc("/gains", srv.method(http.MethodGet, srv.handleGains))
+	mux.HandleFunc("/prices/sync", srv.method(http.MethodPost, srv.handlePriceSync))
+
+	return mux
+}
+
+// ErrorResponse is the body of all non successful responses.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// method restricts the _handler_ to _method_, serializes the requests and turns
+// panics from the processors into internal server errors.
+func (srv *Server) method(method string, handler http.HandlerFunc) http.HandlerFunc {
+
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		if r.Method != method {
+
+			w.Header().Set("Allow", method)
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+			return
+
+		}
+
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+
+		defer func() {
+
+			if rec := recover(); rec != nil {
+				writeError(w, http.StatusInternalServerError, fmt.Errorf("%v", rec))
+			}
+
+		}()
+
+		handler(w, r)
+	}
+
+}
+
+func (srv *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPI)
+
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		panic(err)
+	}
+
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
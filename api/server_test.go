@@ -0,0 +1,323 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/parsers"
+	"github.com/mariotoffia/gocryptoadmin/processors"
+	"github.com/mariotoffia/gocryptoadmin/store"
+	"github.com/mariotoffia/gocryptoadmin/txhistory"
+	"github.com/mariotoffia/gocryptoadmin/txlog"
+	"github.com/mariotoffia/gocryptoadmin/txlog/coinbasepro"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePriceReader struct {
+	exchange string
+}
+
+func (r *fakePriceReader) SetExchangeName(name string) {
+	r.exchange = name
+}
+
+func (r *fakePriceReader) Read(
+	pair common.AssetPair,
+	since time.Time,
+	interval time.Duration,
+) []common.TxOHCHistory {
+
+	return []common.TxOHCHistory{
+		{Exchange: r.exchange, AssetPair: pair, DateTime: since, Resolution: 1440, Open: 1, Close: 2},
+		{Exchange: r.exchange, AssetPair: pair, DateTime: since.Add(interval), Resolution: 1440, Open: 2, Close: 3},
+	}
+
+}
+
+func newServer(t *testing.T) (*Server, *httptest.Server) {
+
+	s, err := store.Open(t.TempDir())
+	require.NoError(t, err)
+
+	reader := txlog.NewTxLogReader(processors.NewChronologicalTxEntryProcessor()).
+		RegisterReader("cbx", coinbasepro.NewTransactionLogReader())
+
+	srv := NewServer(s, reader)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	return srv, ts
+}
+
+func upload(t *testing.T, ts *httptest.Server, file string) *http.Response {
+
+	data, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+
+	resp, err := http.Post(ts.URL+"/imports?exchange=cbx&source=export.csv", "text/csv", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	return resp
+}
+
+func decode(t *testing.T, resp *http.Response, v interface{}) {
+
+	defer resp.Body.Close()
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+
+}
+
+func TestUploadAndListTransactions(t *testing.T) {
+
+	_, ts := newServer(t)
+
+	var imp ImportResponse
+
+	resp := upload(t, ts, "../txlog/testfiles/cbx/cbx_buysell.csv")
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	decode(t, resp, &imp)
+
+	assert.True(t, imp.Imported)
+	assert.Equal(t, "export.csv", imp.Record.Source)
+	assert.Equal(t, 3, imp.Record.Count)
+
+	// Same export again is not imported
+	resp = upload(t, ts, "../txlog/testfiles/cbx/cbx_buysell.csv")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	decode(t, resp, &imp)
+	assert.False(t, imp.Imported)
+
+	var records []store.ImportRecord
+
+	resp, err := http.Get(ts.URL + "/imports")
+	require.NoError(t, err)
+	decode(t, resp, &records)
+	assert.Equal(t, 1, len(records))
+
+	var tx []common.TransactionLog
+
+	resp, err = http.Get(ts.URL + "/transactions?exchange=cbx&side=sell")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	decode(t, resp, &tx)
+
+	require.Equal(t, 2, len(tx))
+	assert.Equal(t, "382592", tx[0].ID)
+	assert.Equal(t, "382593", tx[1].ID)
+
+	resp, err = http.Get(ts.URL + "/transactions?to=2019-06-26T12:00:00Z")
+	require.NoError(t, err)
+	decode(t, resp, &tx)
+
+	require.Equal(t, 1, len(tx))
+	assert.Equal(t, common.SideTypeBuy, tx[0].Side)
+
+	resp, err = http.Get(ts.URL + "/transactions?from=yesterday")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp.Body.Close()
+
+}
+
+func TestUploadUnknownExchange(t *testing.T) {
+
+	_, ts := newServer(t)
+
+	resp, err := http.Post(ts.URL+"/imports?exchange=xyz", "text/csv", strings.NewReader("a,b"))
+	require.NoError(t, err)
+
+	var e ErrorResponse
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	decode(t, resp, &e)
+	assert.Equal(t, "no reader registered for exchange: xyz", e.Error)
+
+}
+
+func TestBalances(t *testing.T) {
+
+	_, ts := newServer(t)
+	upload(t, ts, "../txlog/testfiles/cbx/cbx_buysell.csv").Body.Close()
+
+	resp, err := http.Get(ts.URL + "/balances")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var balances BalancesResponse
+	decode(t, resp, &balances)
+
+	require.Contains(t, balances.Balances, "cbx")
+	assert.InDelta(t, 1782.0-131-439, balances.Balances["cbx"][common.AssetType("XLM")], 0.000001)
+	assert.InDelta(t, -201.337884495+14.878898125+49.861345625, balances.Balances["cbx"][common.AssetTypeEuro], 0.000001)
+
+}
+
+func TestGains(t *testing.T) {
+
+	srv, ts := newServer(t)
+	upload(t, ts, "../txlog/testfiles/cbx/cbx_buysell.csv").Body.Close()
+
+	resp, err := http.Get(ts.URL + "/gains")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	resp.Body.Close()
+
+	srv.UseTranslations(
+		parsers.NewResolverParser().Parse("*:XLM = *:EUR").GetExpressions(),
+		common.AssetTypeEuro,
+	)
+
+	resp, err = http.Get(ts.URL + "/gains?asset=SEK")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp.Body.Close()
+
+	var gains GainsResponse
+
+	resp, err = http.Get(ts.URL + "/gains")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	decode(t, resp, &gains)
+
+	// The two sells are grouped into one
+	require.Equal(t, 1, len(gains.Gains))
+	assert.Equal(t, common.AssetTypeEuro, gains.Asset)
+
+	gain := gains.Gains[0]
+	assert.Equal(t, "XLM-EUR", gain.Pair)
+	assert.InDelta(t, 570.0, gain.Size, 0.000001)
+	assert.InDelta(t, 14.878898125+49.861345625, gain.Proceeds, 0.000001)
+	assert.InDelta(t, 201.337884495*570/1782, gain.Cost, 0.000001)
+	assert.InDelta(t, gain.Proceeds-gain.Cost, gain.Gain, 0.000001)
+	assert.InDelta(t, gain.Gain, gains.Total, 0.000001)
+
+	// Filtered on sell time
+	resp, err = http.Get(ts.URL + "/gains?from=2019-06-27")
+	require.NoError(t, err)
+	decode(t, resp, &gains)
+
+	assert.Equal(t, 0, len(gains.Gains))
+	assert.Equal(t, 0.0, gains.Total)
+
+}
+
+func TestGainsInSecondTranslatedAsset(t *testing.T) {
+
+	srv, ts := newServer(t)
+	upload(t, ts, "../txlog/testfiles/cbx/cbx_buysell.csv").Body.Close()
+
+	day, _ := time.Parse(time.RFC3339, "2019-06-26T00:00:00Z")
+
+	_, err := srv.store.AddCandles([]common.TxOHCHistory{{
+		Exchange: "ecb", Resolution: 1440, DateTime: day, Open: 10, High: 10, Low: 10, Close: 10,
+		AssetPair: common.AssetPair{Asset: common.AssetTypeEuro, CostUnit: common.AssetTypeSvenskKrona},
+	}})
+	require.NoError(t, err)
+
+	srv.UseTranslations(
+		append(
+			parsers.NewResolverParser().Parse("*:XLM = *:EUR").GetExpressions(),
+			parsers.NewResolverParser().Parse("EUR = ecb:SEK").GetExpressions()...,
+		),
+		common.AssetTypeEuro, common.AssetTypeSvenskKrona,
+	)
+
+	var eur, sek GainsResponse
+
+	resp, err := http.Get(ts.URL + "/gains?asset=EUR")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	decode(t, resp, &eur)
+
+	// The report asset is not a filter, i.e. XLM-EUR is still reported
+	resp, err = http.Get(ts.URL + "/gains?asset=SEK")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	decode(t, resp, &sek)
+
+	require.Equal(t, 1, len(eur.Gains))
+	require.Equal(t, 1, len(sek.Gains))
+
+	assert.Equal(t, common.AssetTypeSvenskKrona, sek.Asset)
+	assert.Equal(t, "XLM-EUR", sek.Gains[0].Pair)
+	assert.InDelta(t, eur.Gains[0].Proceeds*10, sek.Gains[0].Proceeds, 0.000001)
+	assert.InDelta(t, eur.Total*10, sek.Total, 0.000001)
+
+}
+
+func TestPriceSync(t *testing.T) {
+
+	srv, ts := newServer(t)
+
+	req := `{"exchanges":["fake"],"pairs":["BTC-EUR"],"since":"2021-01-01T00:00:00Z"}`
+
+	resp, err := http.Post(ts.URL+"/prices/sync", "application/json", strings.NewReader(req))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	resp.Body.Close()
+
+	srv.UsePriceReader(txhistory.NewTxOHCReader().Register("fake", &fakePriceReader{}))
+
+	var sync PriceSyncResponse
+
+	resp, err = http.Post(ts.URL+"/prices/sync", "application/json", strings.NewReader(req))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	decode(t, resp, &sync)
+
+	assert.Equal(t, PriceSyncResponse{Read: 2, Added: 2}, sync)
+	assert.Equal(t, 2, len(srv.store.GetCandles("fake")))
+
+	// Already stored candles are not added again
+	resp, err = http.Post(ts.URL+"/prices/sync", "application/json", strings.NewReader(req))
+	require.NoError(t, err)
+	decode(t, resp, &sync)
+
+	assert.Equal(t, PriceSyncResponse{Read: 2, Added: 0}, sync)
+
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+
+	_, ts := newServer(t)
+
+	resp, err := http.Post(ts.URL+"/transactions", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Equal(t, http.MethodGet, resp.Header.Get("Allow"))
+
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+
+	_, ts := newServer(t)
+
+	resp, err := http.Get(ts.URL + "/openapi.json")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var doc struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+
+	decode(t, resp, &doc)
+
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+
+	for _, path := range []string{
+		"/openapi.json", "/imports", "/transactions", "/balances", "/gains", "/prices/sync",
+	} {
+		assert.Contains(t, doc.Paths, path)
+	}
+
+}
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"encoding/json"
 	"sort"
 	"time"
 )
@@ -72,6 +73,11 @@ func NextAccountLog(previous AccountEntry, tx TransactionEntry) *AccountLog {
 	// Total is negative when buy and positive on sell
 	acc.status[costUnit] = acc.status[costUnit] + tx.GetTotalPrice()
 
+	// Fee charged in another asset is not part of the total.
+	if feeAsset := tx.GetFeeAsset(); feeAsset != "" && feeAsset != costUnit && tx.GetFee() != 0 {
+		acc.status[feeAsset] = acc.status[feeAsset] - tx.GetFee()
+	}
+
 	if asset != costUnit && tx.GetPricePerUnit() != 1.0 {
 
 		if side == SideTypeSell || side == SideTypeTransfer {
@@ -129,6 +135,10 @@ func (acc *AccountLog) GetTranslatedAssets() []AssetType {
 	return acc.tx.GetTranslatedAssets()
 }
 
+func (acc *AccountLog) GetProvenance(asset AssetType) []TranslationProvenance {
+	return acc.tx.GetProvenance(asset)
+}
+
 func (acc *AccountLog) GetExchange() string {
 	return acc.tx.GetExchange()
 }
@@ -157,6 +167,10 @@ func (acc *AccountLog) GetFee() float64 {
 	return acc.tx.GetFee()
 }
 
+func (acc *AccountLog) GetFeeAsset() AssetType {
+	return acc.tx.GetFeeAsset()
+}
+
 func (acc *AccountLog) GetTotalPrice() float64 {
 	return acc.tx.GetTotalPrice()
 }
@@ -225,6 +239,10 @@ func (acc *AccountLog) SplitSize(
 	// have more or less in the account.
 	szd.status[costUnit] = szd.status[costUnit] - acc.GetTotalPrice()*percent
 
+	if feeAsset := acc.GetFeeAsset(); feeAsset != "" && feeAsset != costUnit && acc.GetFee() != 0 {
+		szd.status[feeAsset] = szd.status[feeAsset] + acc.GetFee()*percent
+	}
+
 	if asset != costUnit && acc.GetPricePerUnit() != 1.0 {
 
 		if side == SideTypeSell || side == SideTypeTransfer {
@@ -241,6 +259,45 @@ func (acc *AccountLog) SplitSize(
 	return szd, ofl
 }
 
+// accountLogJSON is the _JSON_ representation of a `AccountLog`.
+type accountLogJSON struct {
+	Tx     TransactionEntry `json:"tx"`
+	Status AccountStatus    `json:"account"`
+}
+
+// MarshalJSON renders the transaction under _tx_ and the account status under _account_.
+func (acc *AccountLog) MarshalJSON() ([]byte, error) {
+	return json.Marshal(accountLogJSON{Tx: acc.tx, Status: acc.status})
+}
+
+// UnmarshalJSON is the inverse of `MarshalJSON`. The transaction is always unmarshalled
+// as a `TransactionLog`, hence a group is not restored.
+func (acc *AccountLog) UnmarshalJSON(data []byte) error {
+
+	var v struct {
+		Tx     *TransactionLog `json:"tx"`
+		Status AccountStatus   `json:"account"`
+	}
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	if v.Tx == nil {
+		v.Tx = &TransactionLog{}
+	}
+
+	if v.Status == nil {
+		v.Status = AccountStatus{}
+	}
+
+	acc.tx = v.Tx
+	acc.status = v.Status
+	acc.setSortedKeys()
+
+	return nil
+}
+
 func (acc *AccountLog) setSortedKeys() {
 
 	keys := make([]string, len(acc.status))
@@ -0,0 +1,34 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountLogSplitSizeWithSeparateFeeAsset(t *testing.T) {
+
+	opening := NewAccountLog(&TransactionLog{})
+	opening.status[AssetTypeBTC] = 1
+	opening.status[AssetTypeBNB] = 1
+
+	acc := NextAccountLog(opening, &TransactionLog{
+		ID: "1", Side: SideTypeBuy, AssetSize: 2, PricePerUnit: 0.01, TotalPrice: -0.02,
+		Fee: 0.2, FeeAsset: AssetTypeBNB,
+		AssetPair: AssetPair{Asset: AssetTypeLTC, CostUnit: AssetTypeBTC},
+	})
+
+	assert.InDelta(t, 0.98, acc.status[AssetTypeBTC], 1e-9)
+	assert.InDelta(t, 0.8, acc.status[AssetTypeBNB], 1e-9)
+	assert.InDelta(t, 2, acc.status[AssetTypeLTC], 1e-9)
+
+	sized, overflow := acc.SplitSize(1)
+
+	status := sized.(*AccountLog).status
+	assert.InDelta(t, 0.99, status[AssetTypeBTC], 1e-9)
+	assert.InDelta(t, 0.9, status[AssetTypeBNB], 1e-9)
+	assert.InDelta(t, 1, status[AssetTypeLTC], 1e-9)
+
+	assert.Equal(t, acc.status, overflow.(*AccountLog).status)
+
+}
@@ -0,0 +1,250 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AdjustmentAction is the kind of manual adjustment.
+type AdjustmentAction string
+
+const (
+	// AdjustmentActionAdd adds a new transaction.
+	AdjustmentActionAdd AdjustmentAction = "add"
+	// AdjustmentActionDelete removes a transaction.
+	AdjustmentActionDelete AdjustmentAction = "delete"
+	// AdjustmentActionPatch changes one or more fields on a transaction.
+	AdjustmentActionPatch AdjustmentAction = "patch"
+)
+
+// Adjustment is a manual correction of the imported transactions. The transaction is
+// identified by _Exchange_ and _ID_.
+type Adjustment struct {
+	Action   AdjustmentAction `json:"action"`
+	Exchange string           `json:"exchange"`
+	ID       string           `json:"id"`
+	// Reason is mandatory and explains why the adjustment was needed.
+	Reason string `json:"reason"`
+	// Tx is the transaction to add when `AdjustmentActionAdd`.
+	Tx *TransactionLog `json:"tx,omitempty"`
+	// Patch are the fields to set when `AdjustmentActionPatch`.
+	Patch *TransactionPatch `json:"patch,omitempty"`
+}
+
+// TransactionPatch contains the fields to set on a `TransactionLog`. Fields that
+// are `nil` are left untouched.
+type TransactionPatch struct {
+	Side           *SideType  `json:"side,omitempty"`
+	SideIdentifier *string    `json:"sideid,omitempty"`
+	CreatedAt      *time.Time `json:"created,omitempty"`
+	AssetSize      *float64   `json:"size,omitempty"`
+	PricePerUnit   *float64   `json:"price,omitempty"`
+	Fee            *float64   `json:"fee,omitempty"`
+	FeeAsset       *AssetType `json:"feeasset,omitempty"`
+	TotalPrice     *float64   `json:"total,omitempty"`
+	Asset          *AssetType `json:"asset,omitempty"`
+	CostUnit       *AssetType `json:"costunit,omitempty"`
+}
+
+// Apply sets all non `nil` fields onto _tx_.
+//
+// The total price includes the fee when charged in the cost unit. Hence, when the
+// total is not patched, it is recalculated if the size, price or side is patched. If
+// only the fee (or fee asset, cost unit) is patched, the total is adjusted with the fee difference.
+func (p *TransactionPatch) Apply(tx *TransactionLog) {
+
+	fee := includedFee(tx)
+
+	if p.Side != nil {
+		tx.Side = *p.Side
+	}
+
+	if p.SideIdentifier != nil {
+		tx.SideIdentifier = *p.SideIdentifier
+	}
+
+	if p.CreatedAt != nil {
+		tx.CreatedAt = *p.CreatedAt
+	}
+
+	if p.AssetSize != nil {
+		tx.AssetSize = *p.AssetSize
+	}
+
+	if p.PricePerUnit != nil {
+		tx.PricePerUnit = *p.PricePerUnit
+	}
+
+	if p.Fee != nil {
+		tx.Fee = *p.Fee
+	}
+
+	if p.FeeAsset != nil {
+		tx.FeeAsset = *p.FeeAsset
+	}
+
+	if p.TotalPrice != nil {
+		tx.TotalPrice = *p.TotalPrice
+	}
+
+	if p.Asset != nil {
+		tx.Asset = *p.Asset
+	}
+
+	if p.CostUnit != nil {
+		tx.CostUnit = *p.CostUnit
+	}
+
+	if p.TotalPrice != nil {
+		return
+	}
+
+	if p.AssetSize != nil || p.PricePerUnit != nil || p.Side != nil {
+
+		switch tx.Side {
+		case SideTypeBuy, SideTypeTransfer:
+			tx.TotalPrice = -(tx.AssetSize * tx.PricePerUnit) - includedFee(tx)
+		case SideTypeSell, SideTypeReceive:
+			tx.TotalPrice = tx.AssetSize*tx.PricePerUnit - includedFee(tx)
+		}
+
+		return
+
+	}
+
+	if p.Fee != nil || p.FeeAsset != nil || p.CostUnit != nil {
+		tx.TotalPrice += fee - includedFee(tx)
+	}
+
+}
+
+// includedFee returns the fee of _tx_ if it is part of the total price.
+func includedFee(tx *TransactionLog) float64 {
+
+	if tx.FeeAsset == "" || tx.FeeAsset == tx.CostUnit {
+		return tx.Fee
+	}
+
+	return 0
+}
+
+// Fields returns a sorted, human readable, list of the fields that are set.
+func (p *TransactionPatch) Fields() string {
+
+	if p == nil {
+		return ""
+	}
+
+	fields := []string{}
+
+	if p.Side != nil {
+		fields = append(fields, fmt.Sprintf("side=%s", *p.Side))
+	}
+
+	if p.SideIdentifier != nil {
+		fields = append(fields, fmt.Sprintf("sideid=%s", *p.SideIdentifier))
+	}
+
+	if p.CreatedAt != nil {
+		fields = append(fields, fmt.Sprintf("created=%s", p.CreatedAt.Format(time.RFC3339)))
+	}
+
+	if p.AssetSize != nil {
+		fields = append(fields, fmt.Sprintf("size=%f", *p.AssetSize))
+	}
+
+	if p.PricePerUnit != nil {
+		fields = append(fields, fmt.Sprintf("price=%f", *p.PricePerUnit))
+	}
+
+	if p.Fee != nil {
+		fields = append(fields, fmt.Sprintf("fee=%f", *p.Fee))
+	}
+
+	if p.FeeAsset != nil {
+		fields = append(fields, fmt.Sprintf("feeasset=%s", *p.FeeAsset))
+	}
+
+	if p.TotalPrice != nil {
+		fields = append(fields, fmt.Sprintf("total=%f", *p.TotalPrice))
+	}
+
+	if p.Asset != nil {
+		fields = append(fields, fmt.Sprintf("asset=%s", *p.Asset))
+	}
+
+	if p.CostUnit != nil {
+		fields = append(fields, fmt.Sprintf("costunit=%s", *p.CostUnit))
+	}
+
+	sort.Strings(fields)
+	return strings.Join(fields, " ")
+}
+
+// Validate checks that the adjustment is complete.
+func (adj *Adjustment) Validate() error {
+
+	if adj.Exchange == "" || adj.ID == "" {
+		return fmt.Errorf("adjustment must have both exchange and id: %s/%s", adj.Exchange, adj.ID)
+	}
+
+	if strings.TrimSpace(adj.Reason) == "" {
+		return fmt.Errorf("adjustment %s %s/%s is missing reason", adj.Action, adj.Exchange, adj.ID)
+	}
+
+	switch adj.Action {
+	case AdjustmentActionAdd:
+
+		if adj.Tx == nil {
+			return fmt.Errorf("add adjustment %s/%s is missing tx", adj.Exchange, adj.ID)
+		}
+
+	case AdjustmentActionPatch:
+
+		if adj.Patch == nil {
+			return fmt.Errorf("patch adjustment %s/%s is missing patch", adj.Exchange, adj.ID)
+		}
+
+	case AdjustmentActionDelete:
+	default:
+		return fmt.Errorf("unknown adjustment action: %s", adj.Action)
+	}
+
+	return nil
+}
+
+func (adj *Adjustment) String() string {
+
+	s := fmt.Sprintf("%s %s/%s", adj.Action, adj.Exchange, adj.ID)
+
+	if adj.Action == AdjustmentActionPatch {
+		s += " " + adj.Patch.Fields()
+	}
+
+	return fmt.Sprintf("%s reason: %s", s, adj.Reason)
+}
+
+// AdjustmentStatus is the outcome of a `Adjustment`.
+type AdjustmentStatus string
+
+const (
+	// AdjustmentStatusApplied is when the adjustment was applied.
+	AdjustmentStatusApplied AdjustmentStatus = "applied"
+	// AdjustmentStatusUnmatched is when no transaction with exchange and id was found.
+	AdjustmentStatusUnmatched AdjustmentStatus = "unmatched"
+	// AdjustmentStatusDuplicate is when a add adjustment collides with a existing
+	// transaction.
+	AdjustmentStatusDuplicate AdjustmentStatus = "duplicate"
+)
+
+// AdjustmentLog is a logged outcome of a `Adjustment`.
+type AdjustmentLog struct {
+	Adjustment
+	Status AdjustmentStatus `json:"status"`
+}
+
+func (log *AdjustmentLog) String() string {
+	return fmt.Sprintf("[%s] %s", log.Status, log.Adjustment.String())
+}
@@ -64,6 +64,7 @@ const (
 	AssetTypePOWR        AssetType = "POWR"
 	AssetTypeBCH         AssetType = "BCH"
 	AssetTypeSALT        AssetType = "SALT"
+	AssetTypeBNB         AssetType = "BNB"
 )
 
 // IsFIAT checks if the `AssetType` is plain FIAT or crypto currency
@@ -0,0 +1,11 @@
+package common
+
+import "time"
+
+// BalanceSnapshot is the balance of each asset, as reported by a exchange, at a
+// certain point in time.
+type BalanceSnapshot struct {
+	Exchange string                `json:"exchange"`
+	At       time.Time             `json:"time"`
+	Balances map[AssetType]float64 `json:"balances"`
+}
@@ -0,0 +1,137 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// InventoryVersion is the current version of the `Inventory` file format.
+const InventoryVersion = 1
+
+// Inventory is a snapshot of the open lots and the account balances at a point
+// in time, typically at year end. It is used to seed the processors in a later
+// run, so only the transactions after `At` needs to be processed.
+type Inventory struct {
+	Version int       `json:"version"`
+	At      time.Time `json:"at"`
+	// Lots are the open (not yet sold) lots in FIFO order for each queue.
+	Lots []InventoryLot `json:"lots"`
+	// Balances is keyed by exchange (or `ExchangeAll`).
+	Balances map[string]AccountStatus `json:"balances"`
+}
+
+// InventoryLot is a single open lot.
+type InventoryLot struct {
+	// Queue is the asset queue where the lot resides. This is the asset for a _BUY_
+	// and the cost unit for a crypto _SELL_.
+	Queue AssetType      `json:"queue"`
+	Tx    TransactionLog `json:"tx"`
+}
+
+// NewInventory creates a new, current version, inventory.
+func NewInventory(at time.Time, lots []InventoryLot, balances map[string]AccountStatus) *Inventory {
+
+	if lots == nil {
+		lots = []InventoryLot{}
+	}
+
+	if balances == nil {
+		balances = map[string]AccountStatus{}
+	}
+
+	return &Inventory{
+		Version:  InventoryVersion,
+		At:       at,
+		Lots:     lots,
+		Balances: balances,
+	}
+
+}
+
+// Write writes the inventory as indented _JSON_.
+func (inv *Inventory) Write(w io.Writer) error {
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(inv)
+
+}
+
+// ReadInventory reads a inventory written by `Inventory.Write`.
+func ReadInventory(r io.Reader) (*Inventory, error) {
+
+	var inv Inventory
+
+	if err := json.NewDecoder(r).Decode(&inv); err != nil {
+		return nil, err
+	}
+
+	if inv.Version != InventoryVersion {
+		return nil, fmt.Errorf("unsupported inventory version: %d", inv.Version)
+	}
+
+	return &inv, nil
+}
+
+// ToTransactionLog flattens any `TransactionEntry` into a `TransactionLog`.
+//
+// Groups are summarized as they are reported by the `TransactionEntry` interface. The
+// provenance is only kept when the _entry_ has a single provenance per asset.
+func ToTransactionLog(entry TransactionEntry) TransactionLog {
+
+	if tx, ok := entry.(*TransactionLog); ok {
+		return *tx.Clone().(*TransactionLog)
+	}
+
+	tx := TransactionLog{
+		ID:                   entry.GetID(),
+		Exchange:             entry.GetExchange(),
+		Side:                 entry.GetSide(),
+		SideIdentifier:       entry.GetSideIdentifier(),
+		CreatedAt:            entry.GetCreatedAt(),
+		AssetSize:            entry.GetAssetSize(),
+		PricePerUnit:         entry.GetPricePerUnit(),
+		Fee:                  entry.GetFee(),
+		FeeAsset:             entry.GetFeeAsset(),
+		TotalPrice:           entry.GetTotalPrice(),
+		TranslatedTotalPrice: map[string]float64{},
+		TranslatedFee:        map[string]float64{},
+		Provenance:           map[string]TranslationProvenance{},
+		AssetPair:            entry.GetAssetPair(),
+	}
+
+	for _, asset := range entry.GetTranslatedAssets() {
+
+		tx.TranslatedTotalPrice[string(asset)] = entry.GetTranslatedTotalPrice(asset)
+		tx.TranslatedFee[string(asset)] = entry.GetTranslatedFee(asset)
+
+		if p := entry.GetProvenance(asset); len(p) == 1 {
+			tx.Provenance[string(asset)] = p[0].Clone()
+		}
+
+	}
+
+	return tx
+}
+
+// NewOpeningAccountLog creates a `AccountLog` that represents the opening _status_ of
+// a account. It is meant to be used as previous entry in `NextAccountLog`.
+func NewOpeningAccountLog(exchange string, at time.Time, status AccountStatus) *AccountLog {
+
+	acc := NewAccountLog(&TransactionLog{
+		ID:        fmt.Sprintf("opening-balance-%s", exchange),
+		Exchange:  exchange,
+		Side:      SideTypeUnknown,
+		CreatedAt: at,
+	})
+
+	for k, v := range status {
+		acc.status[k] = v
+	}
+
+	acc.setSortedKeys()
+	return acc
+}
@@ -0,0 +1,59 @@
+package common
+
+import "time"
+
+const (
+	// TranslationSourceCandle denotes that the price was taken from a historic candle.
+	TranslationSourceCandle = "candle"
+	// TranslationSourceTrade denotes that the price was taken from a executed trade.
+	TranslationSourceTrade = "trade"
+)
+
+// TranslationHop is a single step when translating a amount from one
+// `AssetType` to another, e.g. _BTC-EUR_ on exchange _cbx_.
+type TranslationHop struct {
+	Exchange  string    `json:"exchange"`
+	AssetPair AssetPair `json:"pair"`
+	// CandleTime is the candle start or the trade time.
+	CandleTime time.Time `json:"candle"`
+	// Resolution is the candle resolution in minutes.
+	Resolution int `json:"resolution"`
+	// Price is the price that was used to multiply the amount with.
+	Price float64 `json:"price"`
+	// Source is either `TranslationSourceCandle` or `TranslationSourceTrade`.
+	Source string `json:"source"`
+	// TxID is the trade transaction when `TranslationSourceTrade`.
+	TxID string `json:"txid,omitempty"`
+}
+
+// TranslationProvenance describes how a translated amount, e.g. `GetTranslatedTotalPrice`,
+// was derived.
+//
+// When no hops are present, the amount was already in the _Asset_ and no
+// translation was needed.
+type TranslationProvenance struct {
+	// TxID is the `TransactionEntry.GetID` of the translated transaction.
+	TxID string `json:"txid"`
+	// Asset is the translated to `AssetType`.
+	Asset AssetType `json:"asset"`
+	// Calculator is the name of the price calculator that was used.
+	Calculator string           `json:"calculator,omitempty"`
+	Hops       []TranslationHop `json:"hops,omitempty"`
+	// FeeHops is set when the fee was charged in another asset than the cost unit and
+	// hence translated separately.
+	FeeHops []TranslationHop `json:"feehops,omitempty"`
+}
+
+// Clone creates a deep copy of the provenance.
+func (p TranslationProvenance) Clone() TranslationProvenance {
+
+	if len(p.Hops) > 0 {
+		p.Hops = append([]TranslationHop{}, p.Hops...)
+	}
+
+	if len(p.FeeHops) > 0 {
+		p.FeeHops = append([]TranslationHop{}, p.FeeHops...)
+	}
+
+	return p
+}
@@ -27,6 +27,8 @@ const (
 const (
 	// ExchangeAll represents all exchanges
 	ExchangeAll string = "all"
+	// ExchangeAny is a wildcard that matches any exchange
+	ExchangeAny string = "*"
 )
 
 type CostUnitTranslations interface {
@@ -43,6 +45,11 @@ type CostUnitTranslations interface {
 	// GetTranslatedAssets returns all `AssetType`s that can be used in
 	// `GetTranslatedTotalPrice` and `GetTranslatedFee`
 	GetTranslatedAssets() []AssetType
+	// GetProvenance returns how the translated values for _asset_ was derived.
+	//
+	// A single transaction will return at most one provenance whereas groups
+	// returns the provenance of all its transactions.
+	GetProvenance(asset AssetType) []TranslationProvenance
 }
 
 type TransactionEntry interface {
@@ -55,6 +62,9 @@ type TransactionEntry interface {
 	GetAssetSize() float64
 	GetPricePerUnit() float64
 	GetFee() float64
+	// GetFeeAsset returns the asset that the fee is charged in. This is the cost unit
+	// unless the exchange charged the fee in another asset.
+	GetFeeAsset() AssetType
 	GetTotalPrice() float64
 	GetAssetPair() AssetPair
 
@@ -79,6 +89,11 @@ type TransactionLog struct {
 	TotalPrice           float64            `csv:"total"    json:"total"`
 	TranslatedTotalPrice map[string]float64 `               json:"translatedprice"`
 	TranslatedFee        map[string]float64 `               json:"translatedfee"`
+	// FeeAsset is the asset the _Fee_ is charged in, empty means the cost unit. When it
+	// differs from the cost unit, the _Fee_ is *not* included in the _TotalPrice_.
+	FeeAsset AssetType `csv:"feeasset,omitempty" json:"feeasset,omitempty"`
+	// Provenance is keyed by same key as `TranslatedTotalPrice`.
+	Provenance map[string]TranslationProvenance `csv:"-" json:"provenance,omitempty"`
 	AssetPair
 }
 
@@ -114,6 +129,15 @@ func (tx *TransactionLog) GetFee() float64 {
 	return tx.Fee
 }
 
+func (tx *TransactionLog) GetFeeAsset() AssetType {
+
+	if tx.FeeAsset == "" {
+		return tx.CostUnit
+	}
+
+	return tx.FeeAsset
+}
+
 func (tx *TransactionLog) GetTotalPrice() float64 {
 	return tx.TotalPrice
 }
@@ -182,6 +206,7 @@ func (tx *TransactionLog) Clone() TransactionEntry {
 		AssetSize:      tx.AssetSize,
 		PricePerUnit:   tx.PricePerUnit,
 		Fee:            tx.Fee,
+		FeeAsset:       tx.FeeAsset,
 		TotalPrice:     tx.TotalPrice,
 		AssetPair: AssetPair{
 			Asset:    tx.Asset,
@@ -207,6 +232,15 @@ func (tx *TransactionLog) Clone() TransactionEntry {
 
 	}
 
+	if len(tx.Provenance) > 0 {
+
+		l.Provenance = map[string]TranslationProvenance{}
+		for k, v := range tx.Provenance {
+			l.Provenance[k] = v.Clone()
+		}
+
+	}
+
 	return l
 }
 
@@ -266,3 +300,13 @@ func (tx *TransactionLog) GetTranslatedAssets() []AssetType {
 
 	return assets
 }
+
+func (tx *TransactionLog) GetProvenance(asset AssetType) []TranslationProvenance {
+
+	if p, ok := tx.Provenance[string(asset)]; ok {
+		return []TranslationProvenance{p}
+	}
+
+	return []TranslationProvenance{}
+
+}
@@ -50,6 +50,7 @@ func NewTxBuySellLog(
 			AssetSize:            sellTx.GetAssetSize(),
 			PricePerUnit:         sellTx.GetPricePerUnit(),
 			Fee:                  sellTx.GetFee(),
+			FeeAsset:             sellTx.GetFeeAsset(),
 			TotalPrice:           sellTx.GetTotalPrice(),
 			TranslatedTotalPrice: map[string]float64{},
 			TranslatedFee:        map[string]float64{},
@@ -69,6 +70,13 @@ func NewTxBuySellLog(
 	return log
 }
 
+// GetProvenance returns the provenance of the sell followed by all buys.
+func (tx *TxBuySellLog) GetProvenance(asset AssetType) []TranslationProvenance {
+
+	return append(tx.SellTx.GetProvenance(asset), tx.BuyTx.GetProvenance(asset)...)
+
+}
+
 func (tx *TxBuySellLog) GetSell() TransactionEntry {
 	return tx.SellTx
 }
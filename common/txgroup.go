@@ -1,6 +1,7 @@
 package common
 
 import (
+	"fmt"
 	"math"
 	"time"
 
@@ -54,8 +55,20 @@ func (txg *TxGroupEntry) GetTransactionEntries() []TransactionEntry {
 
 }
 
+// AddTransactionEntry adds the _tx_ to the group. It will panic if the _tx_ is charged
+// its fee in another asset than the rest of the group, since the fees are summed.
 func (txg *TxGroupEntry) AddTransactionEntry(tx TransactionEntry) *TxGroupEntry {
 
+	if len(txg.Tx) > 0 && txg.Tx[0].GetFeeAsset() != tx.GetFeeAsset() {
+
+		panic(
+			fmt.Sprintf(
+				"cannot group fee asset %s with %s in group %s", tx.GetFeeAsset(), txg.Tx[0].GetFeeAsset(), txg.ID,
+			),
+		)
+
+	}
+
 	txg.Tx = append(txg.Tx, tx)
 	return txg
 
@@ -134,6 +147,18 @@ func (txg *TxGroupEntry) GetPricePerUnit() float64 {
 
 }
 
+// GetFeeAsset returns the fee asset of the group, all transactions share the same
+// fee asset (see `AddTransactionEntry`).
+func (txg *TxGroupEntry) GetFeeAsset() AssetType {
+
+	if len(txg.Tx) == 0 {
+		return ""
+	}
+
+	return txg.Tx[0].GetFeeAsset()
+
+}
+
 func (txg *TxGroupEntry) GetFee() float64 {
 
 	if len(txg.Tx) == 0 {
@@ -204,6 +229,18 @@ func (txg *TxGroupEntry) GetTranslatedAssets() []AssetType {
 
 }
 
+// GetProvenance returns the provenance of all transactions in the group.
+func (txg *TxGroupEntry) GetProvenance(asset AssetType) []TranslationProvenance {
+
+	list := []TranslationProvenance{}
+
+	for i := range txg.Tx {
+		list = append(list, txg.Tx[i].GetProvenance(asset)...)
+	}
+
+	return list
+}
+
 func (txg *TxGroupEntry) GetAssetPair() AssetPair {
 
 	if len(txg.Tx) == 0 {
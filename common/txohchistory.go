@@ -1,7 +1,10 @@
 package common
 
 import (
+	"context"
 	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/utils"
 )
 
 // TxOHCReader reads from it's datasource and returns the result.
@@ -11,6 +14,21 @@ type TxOHCReader interface {
 	SetExchangeName(name string)
 }
 
+// TxOHCContextReader is a `TxOHCReader` that may be cancelled using a
+// `context.Context` and returns errors instead of panicking.
+//
+// The plain `Read` is expected to be the same as `ReadContext` with a
+// background context that panics on error.
+type TxOHCContextReader interface {
+	TxOHCReader
+	ReadContext(
+		ctx context.Context,
+		pair AssetPair,
+		since time.Time,
+		interval time.Duration,
+	) ([]TxOHCHistory, error)
+}
+
 // TxOHCHistoryEntry represents a single historic transaction entry
 // for a single exchange.
 //
@@ -82,3 +100,83 @@ func (ohc *TxOHCHistory) GetVolumeCostUnit() float64 {
 func (ohc *TxOHCHistory) GetAssetPair() AssetPair {
 	return ohc.AssetPair
 }
+
+// DailyRatesLookback is the number of days, before _since_, that readers of daily rates
+// reads such that `FillDailyGapsSince` has a rate for _since_ even if it is a weekend
+// or bank holiday.
+const DailyRatesLookback = 10
+
+// FillDailyGaps fills non existing days in _entries_ with the previous day entry
+// until _until_ (inclusive). This is useful for e.g. central bank rates where
+// no rates are published on weekends and bank holidays.
+//
+// The _entries_ must be sorted in ascending order and be of daily resolution. The
+// filled entries gets the same values as the previous entry but with a new
+// `DateTime` and `ID`. If _until_ is zero time, it will only fill gaps between
+// the entries.
+func FillDailyGaps(entries []TxOHCHistory, until time.Time) []TxOHCHistory {
+
+	if len(entries) == 0 {
+		return entries
+	}
+
+	list := make([]TxOHCHistory, 0, len(entries))
+
+	fill := func(prev TxOHCHistory, before time.Time) {
+
+		for day := prev.DateTime.AddDate(0, 0, 1); day.Before(before); day = day.AddDate(0, 0, 1) {
+
+			entry := prev
+			entry.DateTime = day
+			entry.ID = utils.ToString(utils.HashFromTime(day))
+
+			list = append(list, entry)
+
+		}
+
+	}
+
+	for i := range entries {
+
+		if i > 0 {
+			fill(entries[i-1], entries[i].DateTime)
+		}
+
+		list = append(list, entries[i])
+
+	}
+
+	if !until.IsZero() {
+
+		until = until.UTC()
+		fill(
+			entries[len(entries)-1],
+			time.Date(until.Year(), until.Month(), until.Day()+1, 0, 0, 0, 0, time.UTC),
+		)
+
+	}
+
+	return list
+}
+
+// FillDailyGapsSince is same as `FillDailyGaps` but drops all entries before _since_ after
+// the gaps has been filled. Hence, if _entries_ starts before _since_ (see
+// `DailyRatesLookback`), the days from _since_ until the first entry on or after _since_
+// gets the last rate before _since_.
+func FillDailyGapsSince(entries []TxOHCHistory, since, until time.Time) []TxOHCHistory {
+
+	since = since.UTC()
+	since = time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.UTC)
+
+	list := []TxOHCHistory{}
+
+	for _, entry := range FillDailyGaps(entries, until) {
+
+		if !entry.DateTime.Before(since) {
+			list = append(list, entry)
+		}
+
+	}
+
+	return list
+}
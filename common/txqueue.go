@@ -169,6 +169,33 @@ func (q *TxAssetFIFOQueues) DequeueAll() []TransactionEntry {
 
 	return entries
 }
+
+// Entries returns all entries, in queue order, keyed by the queue asset without
+// removing them from the queues.
+func (q *TxAssetFIFOQueues) Entries() map[AssetType][]TransactionEntry {
+
+	entries := map[AssetType][]TransactionEntry{}
+
+	for asset, queue := range q.queues {
+
+		list := make([]TransactionEntry, 0, queue.Len())
+		for !queue.IsEmpty() {
+			list = append(list, queue.Deq())
+		}
+
+		for i := range list {
+			queue.Enq(list[i])
+		}
+
+		if len(list) > 0 {
+			entries[asset] = list
+		}
+
+	}
+
+	return entries
+}
+
 func (q *TxAssetFIFOQueues) TotalLen() int {
 
 	cnt := 0
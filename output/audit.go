@@ -0,0 +1,213 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+)
+
+// AuditPrinter implements `common.TxFlushableEntryProcessor` and writes an audit
+// appendix (_CSV_) that explains how each translated amount was derived.
+//
+// Each hop in a `common.TranslationProvenance` renders a row, fee hops are labeled
+// _fee1_, _fee2_ and so on. Translations without hops (the amount was already in the
+// asset) renders a single row with an empty hop. Groups renders the provenance of all
+// underlying transactions.
+//
+// Manual adjustments, registered by `UseAdjustments`, are written in a second section,
+// separated by a empty line, with the `AdjustmentHeader`.
+//
+// The appendix is written when `Flush` is invoked.
+type AuditPrinter struct {
+	w           io.Writer
+	assets      []common.AssetType
+	entries     []common.TransactionEntry
+	adjustments []common.AdjustmentLog
+}
+
+// AuditHeader is the header row that `AuditPrinter` writes.
+var AuditHeader = []string{
+	"entry", "txid", "asset", "calculator", "hop",
+	"exchange", "pair", "candle", "resolution", "price", "source", "trade",
+}
+
+// AdjustmentHeader is the header row of the adjustment section.
+var AdjustmentHeader = []string{
+	"action", "exchange", "id", "status", "reason", "patch",
+}
+
+// NewAuditPrinter creates a new printer with specified _w_ as `io.Writer`, if
+// `nil` it will set `os.Stdout` as _w_.
+//
+// If no _assets_ are submitted, all translated assets on each entry is written.
+func NewAuditPrinter(w io.Writer, assets ...common.AssetType) *AuditPrinter {
+
+	if w == nil {
+		w = os.Stdout
+	}
+
+	return &AuditPrinter{
+		w:       w,
+		assets:  assets,
+		entries: []common.TransactionEntry{},
+	}
+}
+
+// UseAdjustments registers the adjustment log, e.g. from `processors.AdjustmentProcessor.GetLog`,
+// to be written in the adjustment section.
+func (ap *AuditPrinter) UseAdjustments(log ...common.AdjustmentLog) *AuditPrinter {
+
+	ap.adjustments = append(ap.adjustments, log...)
+	return ap
+
+}
+
+func (ap *AuditPrinter) ProcessMany(tx []common.TransactionEntry) {
+
+	for i := range tx {
+		ap.Process(tx[i])
+	}
+
+}
+
+func (ap *AuditPrinter) Process(tx common.TransactionEntry) {
+	ap.entries = append(ap.entries, tx.Clone())
+}
+
+func (ap *AuditPrinter) Reset() {
+	ap.entries = []common.TransactionEntry{}
+}
+
+func (ap *AuditPrinter) Flush() []common.TransactionEntry {
+
+	entries := ap.entries
+	ap.Reset()
+
+	w := csv.NewWriter(ap.w)
+
+	if err := w.Write(AuditHeader); err != nil {
+		panic(err)
+	}
+
+	for _, entry := range entries {
+
+		for _, asset := range ap.assetsOf(entry) {
+
+			for _, p := range entry.GetProvenance(asset) {
+
+				if len(p.Hops) == 0 && len(p.FeeHops) == 0 {
+
+					ap.write(w, entry, p, "", common.TranslationHop{})
+					continue
+
+				}
+
+				for i, hop := range p.Hops {
+					ap.write(w, entry, p, strconv.Itoa(i+1), hop)
+				}
+
+				for i, hop := range p.FeeHops {
+					ap.write(w, entry, p, fmt.Sprintf("fee%d", i+1), hop)
+				}
+
+			}
+
+		}
+
+	}
+
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		panic(err)
+	}
+
+	if len(ap.adjustments) > 0 {
+		ap.writeAdjustments()
+	}
+
+	return entries
+}
+
+func (ap *AuditPrinter) writeAdjustments() {
+
+	if _, err := fmt.Fprintln(ap.w); err != nil {
+		panic(err)
+	}
+
+	w := csv.NewWriter(ap.w)
+
+	if err := w.Write(AdjustmentHeader); err != nil {
+		panic(err)
+	}
+
+	for _, log := range ap.adjustments {
+
+		row := []string{
+			string(log.Action), log.Exchange, log.ID, string(log.Status),
+			log.Reason, log.Patch.Fields(),
+		}
+
+		if err := w.Write(row); err != nil {
+			panic(err)
+		}
+
+	}
+
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		panic(err)
+	}
+
+}
+
+func (ap *AuditPrinter) assetsOf(entry common.TransactionEntry) []common.AssetType {
+
+	if len(ap.assets) > 0 {
+		return ap.assets
+	}
+
+	assets := entry.GetTranslatedAssets()
+
+	sort.Slice(assets, func(i, j int) bool {
+		return assets[i] < assets[j]
+	})
+
+	return assets
+}
+
+func (ap *AuditPrinter) write(
+	w *csv.Writer,
+	entry common.TransactionEntry,
+	p common.TranslationProvenance,
+	label string,
+	hop common.TranslationHop,
+) {
+
+	row := []string{entry.GetID(), p.TxID, string(p.Asset), p.Calculator, "", "", "", "", "", "", "", ""}
+
+	if label != "" {
+
+		row[4] = label
+		row[5] = hop.Exchange
+		row[6] = hop.AssetPair.String()
+		row[7] = hop.CandleTime.UTC().Format(time.RFC3339)
+		row[8] = strconv.Itoa(hop.Resolution)
+		row[9] = fmt.Sprintf("%f", hop.Price)
+		row[10] = hop.Source
+		row[11] = hop.TxID
+
+	}
+
+	if err := w.Write(row); err != nil {
+		panic(err)
+	}
+
+}
@@ -0,0 +1,106 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditPrinterRendersHopsForGroupsAndSplits(t *testing.T) {
+
+	created, _ := time.Parse(time.RFC3339, "2017-12-06T13:00:00.000Z")
+	candle, _ := time.Parse(time.RFC3339, "2017-12-06T00:00:00.000Z")
+
+	tx := &common.TransactionLog{
+		ID:         "1234",
+		Exchange:   "cbx",
+		Side:       common.SideTypeBuy,
+		CreatedAt:  created,
+		AssetSize:  20,
+		TotalPrice: 1743,
+		AssetPair: common.AssetPair{
+			Asset:    common.AssetTypeLTC,
+			CostUnit: common.AssetTypeEuro,
+		},
+		TranslatedTotalPrice: map[string]float64{"EUR": 1743, "SEK": 17430},
+		TranslatedFee:        map[string]float64{"EUR": 0, "SEK": 0},
+		Provenance: map[string]common.TranslationProvenance{
+			"EUR": {TxID: "1234", Asset: common.AssetTypeEuro},
+			"SEK": {
+				TxID:       "1234",
+				Asset:      common.AssetTypeSvenskKrona,
+				Calculator: "midpoint",
+				Hops: []common.TranslationHop{{
+					Exchange: "ofx",
+					AssetPair: common.AssetPair{
+						Asset:    common.AssetTypeEuro,
+						CostUnit: common.AssetTypeSvenskKrona,
+					},
+					CandleTime: candle,
+					Resolution: 1440,
+					Price:      10,
+					Source:     common.TranslationSourceCandle,
+				}},
+			},
+		},
+	}
+
+	sized, overflow := tx.SplitSize(5)
+	require.Equal(t, 1, len(sized.GetProvenance(common.AssetTypeSvenskKrona)))
+	require.Equal(t, 1, len(overflow.GetProvenance(common.AssetTypeSvenskKrona)))
+
+	group := &common.TxGroupEntry{TransactionLog: common.TransactionLog{ID: "grp"}}
+	group.AddTransactionEntry(sized).AddTransactionEntry(overflow)
+
+	var buff bytes.Buffer
+
+	proc := NewAuditPrinter(&buff)
+	proc.Process(group)
+	proc.Flush()
+
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+	require.Equal(t, 5, len(lines))
+
+	assert.Equal(t, "entry,txid,asset,calculator,hop,exchange,pair,candle,resolution,price,source,trade", lines[0])
+	assert.Equal(t, "grp,1234,EUR,,,,,,,,,", lines[1])
+	assert.Equal(t, "grp,1234,SEK,midpoint,1,ofx,EUR-SEK,2017-12-06T00:00:00Z,1440,10.000000,candle,", lines[3])
+}
+
+func TestAuditPrinterWritesAdjustments(t *testing.T) {
+
+	fee := 0.5
+
+	var buff bytes.Buffer
+
+	NewAuditPrinter(&buff).
+		UseAdjustments(
+			common.AdjustmentLog{
+				Adjustment: common.Adjustment{
+					Action: common.AdjustmentActionPatch, Exchange: "kr", ID: "T-1", Reason: "wrong fee",
+					Patch: &common.TransactionPatch{Fee: &fee},
+				},
+				Status: common.AdjustmentStatusApplied,
+			},
+			common.AdjustmentLog{
+				Adjustment: common.Adjustment{
+					Action: common.AdjustmentActionDelete, Exchange: "cbx", ID: "9", Reason: "duplicate",
+				},
+				Status: common.AdjustmentStatusUnmatched,
+			},
+		).
+		Flush()
+
+	assert.Equal(
+		t,
+		strings.Join(AuditHeader, ",")+"\n\n"+
+			strings.Join(AdjustmentHeader, ",")+"\n"+
+			"patch,kr,T-1,applied,wrong fee,fee=0.500000\n"+
+			"delete,cbx,9,unmatched,duplicate,\n",
+		buff.String(),
+	)
+}
@@ -0,0 +1,109 @@
+package beancount
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+)
+
+// Accounts are the root accounts that the postings are booked on.
+type Accounts struct {
+	// Crypto is the root for crypto held on a exchange, e.g. _Assets:Crypto:Kraken:LTC_.
+	Crypto string
+	// Fiat is the root for _FIAT_ held on a exchange, e.g. _Assets:Fiat:Kraken:EUR_.
+	Fiat string
+	// Fees is the root for fees, e.g. _Expenses:Fees:Kraken_.
+	Fees string
+	// Gains is the root for realized gains, e.g. _Income:CapitalGains:Kraken_.
+	Gains string
+	// Transfers is the clearing account for assets received or transferred.
+	Transfers string
+}
+
+// DefaultAccounts returns the default root accounts.
+func DefaultAccounts() Accounts {
+
+	return Accounts{
+		Crypto:    "Assets:Crypto",
+		Fiat:      "Assets:Fiat",
+		Fees:      "Expenses:Fees",
+		Gains:     "Income:CapitalGains",
+		Transfers: "Assets:Transfers",
+	}
+
+}
+
+// DefaultExchangeNames maps the exchange short names, used by the readers, onto
+// account names.
+func DefaultExchangeNames() map[string]string {
+
+	return map[string]string{
+		"krk": "Kraken",
+		"cbx": "CoinbasePro",
+		"btx": "Bittrex",
+		"bst": "Bitstamp",
+	}
+
+}
+
+// component sanitizes _s_ into a valid account name component. It must start with a
+// capital letter or a digit and may only contain letters, digits and dashes.
+func component(s string) string {
+
+	var b strings.Builder
+
+	for i, r := range s {
+
+		switch {
+		case i == 0 && r < unicode.MaxASCII && unicode.IsLetter(r):
+			b.WriteRune(unicode.ToUpper(r))
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+		default:
+			if i == 0 {
+				b.WriteRune('X')
+			} else {
+				b.WriteRune('-')
+			}
+		}
+
+	}
+
+	if b.Len() == 0 {
+		return "Unknown"
+	}
+
+	return b.String()
+}
+
+// commodity sanitizes _asset_ into a valid commodity. It must start with a capital
+// letter, end with a capital letter or digit and be at least two characters.
+func commodity(asset common.AssetType) string {
+
+	s := strings.ToUpper(string(asset))
+
+	var b strings.Builder
+
+	for _, r := range s {
+
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || strings.ContainsRune("'._-", r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('-')
+		}
+
+	}
+
+	s = b.String()
+
+	if len(s) < 2 || s[0] < 'A' || s[0] > 'Z' {
+		s = "X" + s
+	}
+
+	if last := s[len(s)-1]; !(last >= 'A' && last <= 'Z') && !(last >= '0' && last <= '9') {
+		s += "X"
+	}
+
+	return s
+}
@@ -0,0 +1,583 @@
+// Package beancount exports the transactions as a _Beancount_ ledger where each
+// `common.TransactionEntry` is a balanced transaction.
+//
+// All holdings are booked on per exchange accounts, e.g. _Assets:Crypto:Kraken:LTC_, and
+// all legs of a _BUY_ and _SELL_ are valued in the operating currency using the
+// `GetTranslatedTotalPrice` as price annotation. Bought assets are lots, held at total cost,
+// labelled with the exchange and transaction _ID_. When the lot assignments, from the
+// `TxBuySellProcessor`, are added, a sell reduces exactly those lots. Hence, _Beancount_
+// uses the same cost basis as the `TxBuySellProcessor`.
+//
+// A buy paid with crypto is valued at the translated price and do not reduce any lot,
+// since it does not realize a gain in the `TxBuySellProcessor`.
+//
+// Fees are booked as expenses. Hence the lot cost, and the realized gain that
+// _Beancount_ derives, is before fees. The sum of the _Income_ and _Expenses:Fees_ for
+// the sold lots is the gain in the `common.TxBuySellEntry`.
+package beancount
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/utils"
+)
+
+// Exporter collects the transactions and writes them as a _Beancount_ ledger.
+type Exporter struct {
+	asset     common.AssetType
+	accounts  Accounts
+	exchanges map[string]string
+	title     string
+	location  *time.Location
+	tx        []common.TransactionEntry
+	lots      map[string][]common.TransactionEntry
+}
+
+type postingKind int
+
+const (
+	// postingPlain is a plain amount
+	postingPlain postingKind = iota
+	// postingPrice is annotated with a total price
+	postingPrice
+	// postingAugment adds a lot at cost
+	postingAugment
+	// postingReduce reduces a lot by label, or by the booking method if no label, and
+	// is annotated with the total price when it has a value
+	postingReduce
+	// postingAuto lets _Beancount_ fill in the amount
+	postingAuto
+)
+
+type posting struct {
+	kind    postingKind
+	account string
+	units   float64
+	asset   common.AssetType
+	// value is the weight in the operating currency
+	value float64
+	label string
+}
+
+// NewExporter creates a exporter that values all trades in _asset_.
+func NewExporter(asset common.AssetType) *Exporter {
+
+	return &Exporter{
+		asset:     asset,
+		accounts:  DefaultAccounts(),
+		exchanges: DefaultExchangeNames(),
+		title:     "gocryptoadmin",
+		location:  time.UTC,
+		tx:        []common.TransactionEntry{},
+		lots:      map[string][]common.TransactionEntry{},
+	}
+
+}
+
+// UseAccounts sets the root accounts (default `DefaultAccounts`).
+func (e *Exporter) UseAccounts(accounts Accounts) *Exporter {
+
+	e.accounts = accounts
+	return e
+
+}
+
+// UseExchangeName sets the account name for a _exchange_, e.g. _krk_ is _Kraken_.
+func (e *Exporter) UseExchangeName(exchange, name string) *Exporter {
+
+	e.exchanges[exchange] = name
+	return e
+
+}
+
+// UseTitle sets the ledger title.
+func (e *Exporter) UseTitle(title string) *Exporter {
+
+	e.title = title
+	return e
+
+}
+
+// UseLocation sets the location to render the dates in (default _UTC_).
+func (e *Exporter) UseLocation(location *time.Location) *Exporter {
+
+	e.location = location
+	return e
+
+}
+
+// AddTransactions adds _tx_ to the ledger.
+func (e *Exporter) AddTransactions(tx ...common.TransactionEntry) *Exporter {
+
+	e.tx = append(e.tx, tx...)
+	return e
+
+}
+
+// AddLots adds the lot assignments, as returned by `TxBuySellProcessor.Flush`. The
+// sell in each entry reduces the buys in the entry.
+func (e *Exporter) AddLots(entries ...common.TxBuySellEntry) *Exporter {
+
+	for _, entry := range entries {
+
+		sell := entry.GetSell()
+		key := lotLabel(sell)
+
+		e.lots[key] = append(e.lots[key], entry.GetBuy().GetTransactionEntries()...)
+
+	}
+
+	return e
+
+}
+
+// Write writes the ledger onto _w_.
+func (e *Exporter) Write(w io.Writer) error {
+
+	tx := make([]common.TransactionEntry, len(e.tx))
+	copy(tx, e.tx)
+
+	sort.SliceStable(tx, func(i, j int) bool {
+		return tx[i].GetCreatedAt().Before(tx[j].GetCreatedAt())
+	})
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "option \"title\" %s\n", quote(e.title))
+	fmt.Fprintf(&b, "option \"operating_currency\" %s\n", quote(commodity(e.asset)))
+	b.WriteString("option \"booking_method\" \"FIFO\"\n")
+
+	opened := map[string]bool{}
+	accounts := []string{}
+	body := []string{}
+
+	for _, entry := range tx {
+
+		postings, err := e.postings(entry)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range postings {
+
+			if !opened[p.account] {
+
+				opened[p.account] = true
+				accounts = append(accounts, p.account)
+
+			}
+
+		}
+
+		body = append(body, e.transaction(entry, postings))
+
+	}
+
+	if len(tx) > 0 {
+
+		sort.Strings(accounts)
+
+		b.WriteString("\n")
+
+		date := e.date(tx[0].GetCreatedAt())
+		for _, account := range accounts {
+			fmt.Fprintf(&b, "%s open %s\n", date, account)
+		}
+
+	}
+
+	for _, s := range body {
+		b.WriteString("\n" + s)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// postings creates the balanced postings for _tx_.
+func (e *Exporter) postings(tx common.TransactionEntry) ([]posting, error) {
+
+	pair := tx.GetAssetPair()
+	exchange := tx.GetExchange()
+	feeAsset := tx.GetFeeAsset()
+	fee := tx.GetFee()
+
+	if feeAsset == "" {
+		feeAsset = pair.CostUnit
+	}
+
+	included := feeAsset == pair.CostUnit
+
+	switch tx.GetSide() {
+	case common.SideTypeReceive, common.SideTypeTransfer:
+
+		if pair.Asset != pair.CostUnit {
+			return e.pricedTransfer(tx, feeAsset)
+		}
+
+		// Same asset on all legs, no need for valuation
+		total := fixed(tx.GetTotalPrice())
+
+		postings := []posting{
+			{kind: postingPlain, account: e.holding(exchange, pair.CostUnit), units: total, asset: pair.CostUnit},
+		}
+
+		if fee != 0 {
+
+			postings = append(
+				postings,
+				posting{kind: postingPlain, account: e.fees(exchange), units: fixed(fee), asset: feeAsset},
+			)
+
+			if !included {
+
+				postings = append(
+					postings,
+					posting{kind: postingPlain, account: e.holding(exchange, feeAsset), units: -fixed(fee), asset: feeAsset},
+				)
+
+			}
+
+		}
+
+		transfer := -total
+		if included {
+			transfer -= fixed(fee)
+		}
+
+		return append(
+			postings,
+			posting{kind: postingPlain, account: e.accounts.Transfers, units: fixed(transfer), asset: pair.CostUnit},
+		), nil
+
+	case common.SideTypeBuy, common.SideTypeSell:
+	default:
+		return nil, fmt.Errorf("transaction: %s has unsupported side: %s", tx.GetID(), tx.GetSide())
+	}
+
+	total, err := e.valued(tx, pair.CostUnit, tx.GetTotalPrice(), tx.GetTranslatedTotalPrice(e.asset))
+	if err != nil {
+		return nil, err
+	}
+
+	postings := []posting{total}
+
+	if fee != 0 {
+
+		feeValue, err := e.valued(tx, feeAsset, fee, tx.GetTranslatedFee(e.asset))
+		if err != nil {
+			return nil, err
+		}
+
+		feeValue.account = e.fees(exchange)
+		postings = append(postings, feeValue)
+
+		if !included {
+
+			paid := feeValue
+			paid.account = e.holding(exchange, feeAsset)
+			paid.units = -paid.units
+			paid.value = -paid.value
+
+			postings = append(postings, paid)
+
+		}
+
+	}
+
+	if tx.GetSide() == common.SideTypeBuy {
+
+		value := 0.0
+		for _, p := range postings {
+			value -= p.value
+		}
+
+		value = fixed(value)
+
+		return append(postings, posting{
+			kind:    postingAugment,
+			account: e.holding(exchange, pair.Asset),
+			units:   fixed(tx.GetAssetSize()),
+			asset:   pair.Asset,
+			value:   value,
+			label:   lotLabel(tx),
+		}), nil
+
+	}
+
+	// Crypto received as payment is a lot that may be sold later
+	if !pair.CostUnit.IsFIAT() && postings[0].kind == postingPrice {
+
+		postings[0].kind = postingAugment
+		postings[0].label = lotLabel(tx)
+
+	}
+
+	lots, ok := e.lots[lotLabel(tx)]
+	if !ok {
+
+		postings = append(postings, posting{
+			kind:    postingReduce,
+			account: e.holding(exchange, pair.Asset),
+			units:   -fixed(tx.GetAssetSize()),
+			asset:   pair.Asset,
+		})
+
+	}
+
+	for _, lot := range lots {
+
+		size := lot.GetAssetSize()
+		if lot.GetSide() == common.SideTypeSell {
+			size = lot.GetTotalPrice() // received as payment
+		}
+
+		held := e.holding(lot.GetExchange(), pair.Asset)
+
+		postings = append(postings, posting{
+			kind:    postingReduce,
+			account: held,
+			units:   -fixed(size),
+			asset:   pair.Asset,
+			label:   lotLabel(lot),
+		})
+
+		// The FIFO queues spans all exchanges, move the units to where they were sold
+		if held != e.holding(exchange, pair.Asset) {
+
+			postings = append(
+				postings,
+				posting{kind: postingPlain, account: held, units: fixed(size), asset: pair.Asset},
+				posting{kind: postingPlain, account: e.holding(exchange, pair.Asset), units: -fixed(size), asset: pair.Asset},
+			)
+
+		}
+
+	}
+
+	return append(postings, posting{kind: postingAuto, account: e.gains(exchange)}), nil
+}
+
+// pricedTransfer creates the postings for a _RECEIVE_ or _TRANSFER_ of an asset that
+// is priced in another cost unit, e.g. _ETH-EUR_. A receive adds a lot at the translated
+// total while a transfer reduces the held lots. The clearing account takes the value.
+func (e *Exporter) pricedTransfer(tx common.TransactionEntry, feeAsset common.AssetType) ([]posting, error) {
+
+	pair := tx.GetAssetPair()
+	exchange := tx.GetExchange()
+
+	total, err := e.valued(tx, pair.CostUnit, tx.GetTotalPrice(), tx.GetTranslatedTotalPrice(e.asset))
+	if err != nil {
+		return nil, err
+	}
+
+	postings := []posting{}
+
+	if tx.GetSide() == common.SideTypeReceive {
+
+		postings = append(postings, posting{
+			kind:    postingAugment,
+			account: e.holding(exchange, pair.Asset),
+			units:   fixed(tx.GetAssetSize()),
+			asset:   pair.Asset,
+			value:   fixed(math.Abs(total.value)),
+			label:   lotLabel(tx),
+		})
+
+	} else {
+
+		postings = append(postings, posting{
+			kind:    postingReduce,
+			account: e.holding(exchange, pair.Asset),
+			units:   -fixed(tx.GetAssetSize()),
+			asset:   pair.Asset,
+			value:   total.value,
+		})
+
+	}
+
+	if fee := tx.GetFee(); fee != 0 {
+
+		feeValue, err := e.valued(tx, feeAsset, fee, tx.GetTranslatedFee(e.asset))
+		if err != nil {
+			return nil, err
+		}
+
+		feeValue.account = e.fees(exchange)
+		postings = append(postings, feeValue)
+
+		// A fee in the cost unit is part of the value and hence taken by the clearing account
+		if feeAsset != pair.CostUnit {
+
+			paid := feeValue
+			paid.account = e.holding(exchange, feeAsset)
+			paid.units = -paid.units
+			paid.value = -paid.value
+
+			postings = append(postings, paid)
+
+		}
+
+	}
+
+	return append(postings, posting{kind: postingAuto, account: e.accounts.Transfers}), nil
+}
+
+// valued creates a posting of _units_ of _asset_ that is valued in the operating
+// currency. If _asset_ is not the operating currency, the _translated_ value is used.
+func (e *Exporter) valued(
+	tx common.TransactionEntry, asset common.AssetType, units, translated float64,
+) (posting, error) {
+
+	units = fixed(units)
+
+	account := e.holding(tx.GetExchange(), asset)
+
+	if asset == e.asset {
+		return posting{kind: postingPlain, account: account, units: units, asset: asset, value: units}, nil
+	}
+
+	if !isTranslated(tx, e.asset) {
+		return posting{}, fmt.Errorf("transaction: %s is not translated to %s", tx.GetID(), e.asset)
+	}
+
+	// The translated fee is positive while the total is signed, hence use the units sign
+	value := math.Copysign(fixed(math.Abs(translated)), units)
+
+	return posting{
+		kind:    postingPrice,
+		account: account,
+		units:   units,
+		asset:   asset,
+		value:   value,
+	}, nil
+
+}
+
+func (e *Exporter) transaction(tx common.TransactionEntry, postings []posting) string {
+
+	var b strings.Builder
+
+	fmt.Fprintf(
+		&b, "%s * %s %s\n",
+		e.date(tx.GetCreatedAt()),
+		quote(e.exchange(tx.GetExchange())),
+		quote(fmt.Sprintf("%s %s", tx.GetSide(), tx.GetAssetPair().String())),
+	)
+
+	fmt.Fprintf(&b, "  id: %s\n", quote(tx.GetID()))
+
+	for _, p := range postings {
+
+		switch p.kind {
+		case postingPlain:
+			fmt.Fprintf(&b, "  %s  %s %s\n", p.account, number(p.units), commodity(p.asset))
+		case postingPrice:
+			fmt.Fprintf(
+				&b, "  %s  %s %s @@ %s %s\n",
+				p.account, number(p.units), commodity(p.asset), number(math.Abs(p.value)), commodity(e.asset),
+			)
+		case postingAugment:
+			// Total cost, since a unit cost may not multiply back to the exact weight
+			fmt.Fprintf(
+				&b, "  %s  %s %s {{%s %s, %s}}\n",
+				p.account, number(p.units), commodity(p.asset), number(p.value), commodity(e.asset), quote(p.label),
+			)
+		case postingReduce:
+
+			label := ""
+			if p.label != "" {
+				label = quote(p.label)
+			}
+
+			// The price is informational, the reduced lots are booked at cost
+			price := ""
+			if p.value != 0 {
+				price = fmt.Sprintf(" @@ %s %s", number(math.Abs(p.value)), commodity(e.asset))
+			}
+
+			fmt.Fprintf(&b, "  %s  %s %s {%s}%s\n", p.account, number(p.units), commodity(p.asset), label, price)
+
+		case postingAuto:
+			fmt.Fprintf(&b, "  %s\n", p.account)
+		}
+
+	}
+
+	return b.String()
+}
+
+// holding returns the account where _asset_ is held on _exchange_.
+func (e *Exporter) holding(exchange string, asset common.AssetType) string {
+
+	root := e.accounts.Crypto
+	if asset.IsFIAT() {
+		root = e.accounts.Fiat
+	}
+
+	return fmt.Sprintf("%s:%s:%s", root, e.exchange(exchange), component(string(asset)))
+}
+
+func (e *Exporter) fees(exchange string) string {
+	return fmt.Sprintf("%s:%s", e.accounts.Fees, e.exchange(exchange))
+}
+
+func (e *Exporter) gains(exchange string) string {
+	return fmt.Sprintf("%s:%s", e.accounts.Gains, e.exchange(exchange))
+}
+
+func (e *Exporter) exchange(exchange string) string {
+
+	if name, ok := e.exchanges[exchange]; ok {
+		return component(name)
+	}
+
+	return component(exchange)
+}
+
+func (e *Exporter) date(t time.Time) string {
+	return t.In(e.location).Format("2006-01-02")
+}
+
+// lotLabel is the unique label of the lot that _tx_ creates.
+func lotLabel(tx common.TransactionEntry) string {
+	return fmt.Sprintf("%s-%s", tx.GetExchange(), tx.GetID())
+}
+
+func isTranslated(tx common.TransactionEntry, asset common.AssetType) bool {
+
+	for _, translated := range tx.GetTranslatedAssets() {
+
+		if translated == asset {
+			return true
+		}
+
+	}
+
+	return false
+}
+
+func fixed(v float64) float64 {
+
+	v = utils.ToFixed(v, 8)
+	if v == 0 {
+		return 0 // no negative zero
+	}
+
+	return v
+}
+
+func number(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
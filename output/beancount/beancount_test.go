@@ -0,0 +1,197 @@
+package beancount
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/processors"
+	"github.com/mariotoffia/gocryptoadmin/txlog/testfiles/fixtures"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ledgerEntries returns the _coinbase pro_ buy and sells, translated into _EUR_, and the
+// _bitstamp_ transfer as exchange _foo bar_.
+func ledgerEntries() (tx []common.TransactionEntry, transfer *common.TransactionLog) {
+
+	bst := fixtures.Bitstamp("foo bar")
+
+	for i := range bst {
+
+		if bst[i].Side == common.SideTypeTransfer {
+			transfer = &bst[i]
+		}
+
+	}
+
+	return fixtures.Entries(fixtures.CoinbasePro(common.AssetTypeEuro)), transfer
+}
+
+func TestLedgerWithLots(t *testing.T) {
+
+	tx, transfer := ledgerEntries()
+
+	bs := processors.NewTxBuySellProcessor()
+	bs.ProcessMany(tx)
+	entries, _ := bs.Flush()
+
+	var buff bytes.Buffer
+
+	err := NewExporter(common.AssetTypeEuro).
+		AddTransactions(transfer, tx[2], tx[1], tx[0]).
+		AddLots(entries...).
+		Write(&buff)
+
+	require.NoError(t, err)
+
+	assert.Equal(t, `option "title" "gocryptoadmin"
+option "operating_currency" "EUR"
+option "booking_method" "FIFO"
+
+2017-12-20 open Assets:Crypto:CoinbasePro:XLM
+2017-12-20 open Assets:Crypto:Foo-bar:LTC
+2017-12-20 open Assets:Fiat:CoinbasePro:EUR
+2017-12-20 open Assets:Transfers
+2017-12-20 open Expenses:Fees:CoinbasePro
+2017-12-20 open Income:CapitalGains:CoinbasePro
+
+2017-12-20 * "Foo-bar" "TRANSFER LTC-LTC"
+  id: "1427334377"
+  Assets:Crypto:Foo-bar:LTC  -50.34321665 LTC
+  Assets:Transfers  50.34321665 LTC
+
+2019-06-26 * "CoinbasePro" "BUY XLM-EUR"
+  id: "381617"
+  Assets:Fiat:CoinbasePro:EUR  -201.3378845 EUR
+  Expenses:Fees:CoinbasePro  0.30155449 EUR
+  Assets:Crypto:CoinbasePro:XLM  1782 XLM {{201.03633001 EUR, "cbx-381617"}}
+
+2019-06-26 * "CoinbasePro" "SELL XLM-EUR"
+  id: "382592"
+  Assets:Fiat:CoinbasePro:EUR  14.87889813 EUR
+  Expenses:Fees:CoinbasePro  0.02235188 EUR
+  Assets:Crypto:CoinbasePro:XLM  -131 XLM {"cbx-381617"}
+  Income:CapitalGains:CoinbasePro
+
+2019-06-26 * "CoinbasePro" "SELL XLM-EUR"
+  id: "382593"
+  Assets:Fiat:CoinbasePro:EUR  49.86134563 EUR
+  Expenses:Fees:CoinbasePro  0.07490437 EUR
+  Assets:Crypto:CoinbasePro:XLM  -439 XLM {"cbx-381617"}
+  Income:CapitalGains:CoinbasePro
+`, buff.String())
+
+}
+
+func TestLedgerCryptoTrades(t *testing.T) {
+
+	created, _ := time.Parse(time.RFC3339, "2021-01-01T10:00:00Z")
+
+	// Sell LTC for BTC on Kraken, fee in EUR
+	sell := &common.TransactionLog{
+		ID:                   "10",
+		Exchange:             "krk",
+		Side:                 common.SideTypeSell,
+		CreatedAt:            created,
+		AssetSize:            2,
+		PricePerUnit:         0.01,
+		Fee:                  2,
+		FeeAsset:             common.AssetTypeEuro,
+		TotalPrice:           0.02,
+		AssetPair:            common.AssetPair{Asset: common.AssetTypeLTC, CostUnit: common.AssetTypeBTC},
+		TranslatedTotalPrice: map[string]float64{"EUR": 300},
+		TranslatedFee:        map[string]float64{"EUR": 2},
+	}
+
+	// Lot bought on Bittrex
+	lot := &common.TransactionLog{
+		ID:        "9",
+		Exchange:  "btx",
+		Side:      common.SideTypeBuy,
+		AssetSize: 2,
+		AssetPair: common.AssetPair{Asset: common.AssetTypeLTC, CostUnit: common.AssetTypeEuro},
+	}
+
+	e := NewExporter(common.AssetTypeEuro).
+		AddLots(common.NewTxBuySellLog(sell, []common.TransactionEntry{lot}))
+
+	postings, err := e.postings(sell)
+	require.NoError(t, err)
+
+	var buff bytes.Buffer
+	buff.WriteString(e.transaction(sell, postings))
+
+	assert.Equal(t, `2021-01-01 * "Kraken" "SELL LTC-BTC"
+  id: "10"
+  Assets:Crypto:Kraken:BTC  0.02 BTC {{300 EUR, "krk-10"}}
+  Expenses:Fees:Kraken  2 EUR
+  Assets:Fiat:Kraken:EUR  -2 EUR
+  Assets:Crypto:Bittrex:LTC  -2 LTC {"btx-9"}
+  Assets:Crypto:Bittrex:LTC  2 LTC
+  Assets:Crypto:Kraken:LTC  -2 LTC
+  Income:CapitalGains:Kraken
+`, buff.String())
+
+	// Not translated into the operating currency
+	delete(sell.TranslatedTotalPrice, "EUR")
+	sell.TranslatedTotalPrice["SEK"] = 3000
+	sell.TranslatedFee = map[string]float64{}
+
+	_, err = e.postings(sell)
+	assert.EqualError(t, err, "transaction: 10 is not translated to EUR")
+
+}
+
+func TestLedgerPricedReceiveAndTransfer(t *testing.T) {
+
+	created, _ := time.Parse(time.RFC3339, "2021-01-01T10:00:00Z")
+	etheur := common.AssetPair{Asset: common.AssetTypeETH, CostUnit: common.AssetTypeEuro}
+
+	receive := &common.TransactionLog{
+		ID: "20", Exchange: "krk", Side: common.SideTypeReceive, CreatedAt: created,
+		AssetSize: 2, PricePerUnit: 250, TotalPrice: 500, AssetPair: etheur,
+	}
+
+	transfer := &common.TransactionLog{
+		ID: "21", Exchange: "krk", Side: common.SideTypeTransfer, CreatedAt: created.Add(time.Hour * 24),
+		AssetSize: 1, PricePerUnit: 260, TotalPrice: -260, Fee: 0.001, FeeAsset: common.AssetTypeETH,
+		AssetPair:            etheur,
+		TranslatedTotalPrice: map[string]float64{"EUR": -260},
+		TranslatedFee:        map[string]float64{"EUR": 0.26},
+	}
+
+	var buff bytes.Buffer
+
+	err := NewExporter(common.AssetTypeEuro).AddTransactions(transfer, receive).Write(&buff)
+	require.NoError(t, err)
+
+	assert.Contains(t, buff.String(), `2021-01-01 * "Kraken" "RECEIVE ETH-EUR"
+  id: "20"
+  Assets:Crypto:Kraken:ETH  2 ETH {{500 EUR, "krk-20"}}
+  Assets:Transfers
+`)
+
+	assert.Contains(t, buff.String(), `2021-01-02 * "Kraken" "TRANSFER ETH-EUR"
+  id: "21"
+  Assets:Crypto:Kraken:ETH  -1 ETH {} @@ 260 EUR
+  Expenses:Fees:Kraken  0.001 ETH @@ 0.26 EUR
+  Assets:Crypto:Kraken:ETH  -0.001 ETH @@ 0.26 EUR
+  Assets:Transfers
+`)
+
+	assert.NotContains(t, buff.String(), "Assets:Fiat:Kraken:EUR")
+
+}
+
+func TestSanitize(t *testing.T) {
+
+	assert.Equal(t, "Foo-bar", component("foo bar"))
+	assert.Equal(t, "1inch", component("1inch"))
+	assert.Equal(t, "Xx", component("-x"))
+	assert.Equal(t, "X1INCH", commodity("1INCH"))
+	assert.Equal(t, "XT", commodity("T"))
+	assert.Equal(t, "USDT", commodity("usdt"))
+
+}
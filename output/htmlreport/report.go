@@ -0,0 +1,450 @@
+// Package htmlreport renders the processor results as a self contained _HTML_ file.
+//
+// All styling is inline and the charts are rendered, in go, as inline _SVG_. Hence no
+// scripts or external resources are needed to view the report.
+package htmlreport
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+)
+
+//go:embed report.gohtml
+var reportTemplate string
+
+// PriceFunc returns the price of one _asset_ in the report asset at time _at_.
+type PriceFunc func(asset common.AssetType, at time.Time) (float64, bool)
+
+// Report renders transactions, the balances per exchange, the valuation over time
+// and the realized gains per asset.
+//
+// The valuation is calculated on each entry of the `common.ExchangeAll` account (or
+// all accounts if not present) using the `PriceFunc`. When no `PriceFunc` is set, the
+// last price of each asset is derived from the translated total of the transactions.
+type Report struct {
+	title        string
+	asset        common.AssetType
+	price        PriceFunc
+	transactions []common.TransactionEntry
+	accounts     map[string][]common.TransactionEntry
+	gains        []common.TxBuySellEntry
+}
+
+// NewReport creates a new report where all amounts are translated into _asset_.
+func NewReport(asset common.AssetType) *Report {
+
+	return &Report{
+		title:        "gocryptoadmin report",
+		asset:        asset,
+		transactions: []common.TransactionEntry{},
+		accounts:     map[string][]common.TransactionEntry{},
+		gains:        []common.TxBuySellEntry{},
+	}
+
+}
+
+// UseTitle sets the report title.
+func (r *Report) UseTitle(title string) *Report {
+
+	r.title = title
+	return r
+
+}
+
+// UsePriceFunc sets the _price_ to value the balances with.
+func (r *Report) UsePriceFunc(price PriceFunc) *Report {
+
+	r.price = price
+	return r
+
+}
+
+// UseTransactions renders the _tx_ in the transactions table.
+func (r *Report) UseTransactions(tx ...common.TransactionEntry) *Report {
+
+	r.transactions = append(r.transactions, tx...)
+	return r
+
+}
+
+// UseAccounts sets the _accounts_, as returned by `MultiExchangeAccountingProcessor.Flush`,
+// to render balances and valuation from.
+func (r *Report) UseAccounts(accounts map[string][]common.TransactionEntry) *Report {
+
+	for exchange, entries := range accounts {
+		r.accounts[exchange] = append(r.accounts[exchange], entries...)
+	}
+
+	return r
+}
+
+// UseGains renders the realized gains, as returned by `TxBuySellProcessor.Flush`.
+func (r *Report) UseGains(entries ...common.TxBuySellEntry) *Report {
+
+	r.gains = append(r.gains, entries...)
+	return r
+
+}
+
+// Write renders the report onto _w_.
+func (r *Report) Write(w io.Writer) error {
+
+	tpl, err := template.New("report").
+		Funcs(template.FuncMap{"amount": formatAmount}).
+		Parse(reportTemplate)
+
+	if err != nil {
+		return err
+	}
+
+	return tpl.Execute(w, r.data())
+}
+
+type transactionRow struct {
+	Date       time.Time
+	Exchange   string
+	ID         string
+	Side       common.SideType
+	Pair       common.AssetPair
+	Size       float64
+	Price      float64
+	Fee        float64
+	FeeAsset   common.AssetType
+	Total      float64
+	Translated float64
+}
+
+type balanceRow struct {
+	Exchange string
+	Amounts  []float64
+}
+
+type gainRow struct {
+	Asset    common.AssetType
+	Count    int
+	Cost     float64
+	Proceeds float64
+	Gain     float64
+}
+
+type reportData struct {
+	Title        string
+	Asset        common.AssetType
+	Transactions []transactionRow
+	Assets       []common.AssetType
+	Balances     []balanceRow
+	Valuation    template.HTML
+	Value        float64
+	Gains        []gainRow
+	GainsChart   template.HTML
+	TotalGain    float64
+}
+
+func (r *Report) data() reportData {
+
+	data := reportData{
+		Title: r.title,
+		Asset: r.asset,
+	}
+
+	for _, tx := range r.transactions {
+
+		data.Transactions = append(data.Transactions, transactionRow{
+			Date:       tx.GetCreatedAt(),
+			Exchange:   tx.GetExchange(),
+			ID:         tx.GetID(),
+			Side:       tx.GetSide(),
+			Pair:       tx.GetAssetPair(),
+			Size:       tx.GetAssetSize(),
+			Price:      tx.GetPricePerUnit(),
+			Fee:        tx.GetFee(),
+			FeeAsset:   tx.GetFeeAsset(),
+			Total:      tx.GetTotalPrice(),
+			Translated: tx.GetTranslatedTotalPrice(r.asset),
+		})
+
+	}
+
+	r.balances(&data)
+
+	points := r.valuation()
+	data.Valuation = LineChart(points, string(r.asset))
+
+	if len(points) > 0 {
+		data.Value = points[len(points)-1].Value
+	}
+
+	r.gainsPerAsset(&data)
+
+	return data
+}
+
+// balances renders the last account status of each exchange.
+func (r *Report) balances(data *reportData) {
+
+	seen := map[common.AssetType]bool{}
+	last := map[string]common.AccountStatus{}
+
+	for _, exchange := range r.exchanges() {
+
+		status := lastStatus(r.accounts[exchange])
+		last[exchange] = status
+
+		for asset := range status {
+
+			if !seen[asset] {
+
+				seen[asset] = true
+				data.Assets = append(data.Assets, asset)
+
+			}
+
+		}
+
+	}
+
+	sort.Slice(data.Assets, func(i, j int) bool {
+		return data.Assets[i] < data.Assets[j]
+	})
+
+	for _, exchange := range r.exchanges() {
+
+		row := balanceRow{Exchange: exchange}
+		for _, asset := range data.Assets {
+			row.Amounts = append(row.Amounts, last[exchange][asset])
+		}
+
+		data.Balances = append(data.Balances, row)
+
+	}
+
+}
+
+// valuation values the account after each entry.
+func (r *Report) valuation() []Point {
+
+	entries := r.accounts[common.ExchangeAll]
+
+	if len(entries) == 0 {
+
+		for _, exchange := range r.exchanges() {
+			entries = append(entries, r.accounts[exchange]...)
+		}
+
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].GetCreatedAt().Before(entries[j].GetCreatedAt())
+		})
+
+	}
+
+	price := r.price
+	if price == nil {
+		price = lastTradePrice(r.asset, r.transactions, entries)
+	}
+
+	points := []Point{}
+	balances := map[string]common.AccountStatus{}
+	hasAll := len(r.accounts[common.ExchangeAll]) > 0
+
+	for _, entry := range entries {
+
+		acc, ok := entry.(common.AccountEntry)
+		if !ok {
+			continue
+		}
+
+		// Without the all account, the total is the sum of the last status of each exchange
+		key := entry.GetExchange()
+		if hasAll {
+			key = common.ExchangeAll
+		}
+
+		balances[key] = acc.GetAccountStatus()
+
+		value := 0.0
+
+		for _, status := range balances {
+
+			for asset, amount := range status {
+
+				if asset == r.asset {
+
+					value += amount
+					continue
+
+				}
+
+				if p, ok := price(asset, entry.GetCreatedAt()); ok {
+					value += amount * p
+				}
+
+			}
+
+		}
+
+		points = append(points, Point{At: entry.GetCreatedAt(), Value: value})
+
+	}
+
+	return points
+}
+
+func (r *Report) gainsPerAsset(data *reportData) {
+
+	rows := map[common.AssetType]*gainRow{}
+
+	for _, entry := range r.gains {
+
+		asset := entry.GetAssetPair().Asset
+
+		row, ok := rows[asset]
+		if !ok {
+
+			row = &gainRow{Asset: asset}
+			rows[asset] = row
+
+		}
+
+		cost := -entry.GetBuy().GetTranslatedTotalPrice(r.asset)
+		proceeds := entry.GetSell().GetTranslatedTotalPrice(r.asset)
+
+		row.Count++
+		row.Cost += cost
+		row.Proceeds += proceeds
+		row.Gain += proceeds - cost
+
+		data.TotalGain += proceeds - cost
+
+	}
+
+	bars := []Bar{}
+
+	for _, row := range rows {
+		data.Gains = append(data.Gains, *row)
+	}
+
+	sort.Slice(data.Gains, func(i, j int) bool {
+		return data.Gains[i].Asset < data.Gains[j].Asset
+	})
+
+	for _, row := range data.Gains {
+		bars = append(bars, Bar{Label: string(row.Asset), Value: row.Gain})
+	}
+
+	data.GainsChart = BarChart(bars, string(r.asset))
+}
+
+func (r *Report) exchanges() []string {
+
+	list := make([]string, 0, len(r.accounts))
+	for exchange := range r.accounts {
+
+		if exchange != common.ExchangeAll {
+			list = append(list, exchange)
+		}
+
+	}
+
+	sort.Strings(list)
+
+	if _, ok := r.accounts[common.ExchangeAll]; ok {
+		list = append(list, common.ExchangeAll)
+	}
+
+	return list
+}
+
+func lastStatus(entries []common.TransactionEntry) common.AccountStatus {
+
+	for i := len(entries) - 1; i >= 0; i-- {
+
+		if acc, ok := entries[i].(common.AccountEntry); ok {
+			return acc.GetAccountStatus()
+		}
+
+	}
+
+	return common.AccountStatus{}
+}
+
+// lastTradePrice derives the price of each asset, in _target_, from the translated total
+// of the latest transaction, at or before the time, where the asset was bought or sold.
+func lastTradePrice(
+	target common.AssetType,
+	tx ...[]common.TransactionEntry,
+) PriceFunc {
+
+	type price struct {
+		at    time.Time
+		price float64
+	}
+
+	prices := map[common.AssetType][]price{}
+
+	add := func(asset common.AssetType, at time.Time, amount, translated float64) {
+
+		if asset == target || amount == 0 || translated == 0 {
+			return
+		}
+
+		prices[asset] = append(prices[asset], price{at: at, price: math.Abs(translated / amount)})
+
+	}
+
+	for _, list := range tx {
+
+		for _, entry := range list {
+
+			translated := entry.GetTranslatedTotalPrice(target)
+			pair := entry.GetAssetPair()
+
+			add(pair.Asset, entry.GetCreatedAt(), entry.GetAssetSize(), translated)
+
+			if pair.CostUnit != pair.Asset {
+				add(pair.CostUnit, entry.GetCreatedAt(), entry.GetTotalPrice(), translated)
+			}
+
+		}
+
+	}
+
+	for asset := range prices {
+
+		list := prices[asset]
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].at.Before(list[j].at)
+		})
+
+	}
+
+	return func(asset common.AssetType, at time.Time) (float64, bool) {
+
+		list := prices[asset]
+		i := sort.Search(len(list), func(i int) bool {
+			return list[i].at.After(at)
+		})
+
+		if i == 0 {
+			return 0, false
+		}
+
+		return list[i-1].price, true
+	}
+
+}
+
+func formatAmount(v float64, asset common.AssetType) string {
+
+	if asset.IsFIAT() {
+		return fmt.Sprintf("%.2f", v)
+	}
+
+	return fmt.Sprintf("%.8f", v)
+}
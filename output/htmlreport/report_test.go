@@ -0,0 +1,100 @@
+package htmlreport
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/processors"
+	"github.com/mariotoffia/gocryptoadmin/txlog/testfiles/fixtures"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportIsSelfContained(t *testing.T) {
+
+	entries := fixtures.Entries(fixtures.CoinbasePro(common.AssetTypeEuro))
+
+	acc := processors.NewMultiExchangeAccountingProcessor()
+	acc.ProcessMany(entries)
+
+	bs := processors.NewTxBuySellProcessor()
+	bs.ProcessMany(entries)
+	pairs, _ := bs.Flush()
+
+	var buff bytes.Buffer
+
+	err := NewReport(common.AssetTypeEuro).
+		UseTitle("Report <2019>").
+		UseTransactions(entries...).
+		UseAccounts(acc.Flush()).
+		UseGains(pairs...).
+		Write(&buff)
+
+	require.NoError(t, err)
+
+	html := buff.String()
+
+	assert.Contains(t, html, "<title>Report &lt;2019&gt;</title>")
+	assert.NotContains(t, html, "<script")
+	assert.NotContains(t, html, "<link")
+	assert.NotContains(t, html, "src=")
+
+	// Balances for cbx and all
+	assert.Contains(t, html, `<tr><td>cbx</td><td class="num neg">-136.60</td><td class="num">1212.00000000</td></tr>`)
+	assert.Contains(t, html, `<tr><td>all</td>`)
+
+	// After the sells, 1212 XLM is valued at the last price 49.861345625 / 439
+	assert.Contains(t, html, "Current value: 1.06 EUR")
+	assert.Equal(t, 2, strings.Count(html, "<svg "))
+	assert.Contains(t, html, "<polyline")
+
+	// Two sells of 570 XLM out of the 1782 bought
+	assert.Contains(t, html, `<tr><td>XLM</td><td class="num">2</td><td class="num">64.40</td><td class="num">64.74</td><td class="num">0.34</td></tr>`)
+	assert.Contains(t, html, `<rect `)
+
+	assert.Contains(t, html, `<td>2019-06-26 13:35:21</td><td>cbx</td><td>382592</td><td>SELL</td><td>XLM-EUR</td>`)
+
+}
+
+func TestReportUsesPriceFunc(t *testing.T) {
+
+	entries := fixtures.Entries(fixtures.CoinbasePro(common.AssetTypeEuro))
+
+	acc := processors.NewMultiExchangeAccountingProcessor()
+	acc.Process(entries[0])
+
+	var buff bytes.Buffer
+
+	err := NewReport(common.AssetTypeEuro).
+		UseAccounts(acc.Flush()).
+		UsePriceFunc(func(asset common.AssetType, at time.Time) (float64, bool) {
+			return 0.2, asset == common.AssetTypeXLM
+		}).
+		Write(&buff)
+
+	require.NoError(t, err)
+	assert.Contains(t, buff.String(), "Current value: 155.06 EUR")
+
+}
+
+func TestCharts(t *testing.T) {
+
+	assert.Equal(t, "", string(LineChart(nil, "EUR")))
+	assert.Equal(t, "", string(BarChart(nil, "EUR")))
+
+	chart := string(BarChart([]Bar{{Label: "BTC", Value: 10}, {Label: "<ETH>", Value: -5}}, "EUR"))
+
+	assert.Contains(t, chart, `fill="#2ca02c"><title>BTC: 10.00 EUR</title>`)
+	assert.Contains(t, chart, `fill="#d62728"><title>&lt;ETH&gt;: -5.00 EUR</title>`)
+	assert.Contains(t, chart, `>-5.00 EUR</text>`)
+
+	at, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+	chart = string(LineChart([]Point{{At: at, Value: 0}, {At: at.AddDate(0, 0, 1), Value: 10}}, "EUR"))
+
+	assert.Contains(t, chart, `points="48.0,192.0 672.0,48.0"`)
+	assert.Contains(t, chart, `>2021-01-02</text>`)
+
+}
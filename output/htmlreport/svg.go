@@ -0,0 +1,207 @@
+package htmlreport
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"math"
+	"time"
+)
+
+// Point is a value at a point in time in a `LineChart`.
+type Point struct {
+	At    time.Time
+	Value float64
+}
+
+// Bar is a labeled value in a `BarChart`.
+type Bar struct {
+	Label string
+	Value float64
+}
+
+const (
+	chartWidth  = 720
+	chartHeight = 240
+	chartMargin = 48
+)
+
+// LineChart renders the _points_, that must be in chronological order, as a inline
+// _SVG_ line chart. The y-axis is labeled with the min and max value and the x-axis
+// with the first and last date.
+func LineChart(points []Point, unit string) template.HTML {
+
+	if len(points) == 0 {
+		return ""
+	}
+
+	min, max := points[0].Value, points[0].Value
+	for _, p := range points {
+
+		min = math.Min(min, p.Value)
+		max = math.Max(max, p.Value)
+
+	}
+
+	if min > 0 {
+		min = 0
+	}
+
+	if max == min {
+		max = min + 1
+	}
+
+	first := points[0].At
+	span := points[len(points)-1].At.Sub(first).Seconds()
+
+	plotWidth := float64(chartWidth - 2*chartMargin)
+	plotHeight := float64(chartHeight - 2*chartMargin)
+
+	x := func(at time.Time) float64 {
+
+		if span == 0 {
+			return chartMargin + plotWidth/2
+		}
+
+		return chartMargin + at.Sub(first).Seconds()/span*plotWidth
+	}
+
+	y := func(v float64) float64 {
+		return chartMargin + (max-v)/(max-min)*plotHeight
+	}
+
+	var b bytes.Buffer
+
+	openSVG(&b)
+	axes(&b, y(0))
+
+	b.WriteString(`<polyline fill="none" stroke="#1f77b4" stroke-width="2" points="`)
+
+	for i, p := range points {
+
+		if i > 0 {
+			b.WriteString(" ")
+		}
+
+		fmt.Fprintf(&b, "%.1f,%.1f", x(p.At), y(p.Value))
+
+	}
+
+	b.WriteString(`"/>`)
+
+	label(&b, chartMargin-4, y(max)+4, "end", formatAxis(max, unit))
+	label(&b, chartMargin-4, y(min)+4, "end", formatAxis(min, unit))
+	label(&b, chartMargin, chartHeight-chartMargin+16, "start", first.Format("2006-01-02"))
+	label(
+		&b, chartWidth-chartMargin, chartHeight-chartMargin+16, "end",
+		points[len(points)-1].At.Format("2006-01-02"),
+	)
+
+	b.WriteString("</svg>")
+
+	return template.HTML(b.String())
+}
+
+// BarChart renders the _bars_ as a inline _SVG_ bar chart. Positive bars are green and
+// negative are red.
+func BarChart(bars []Bar, unit string) template.HTML {
+
+	if len(bars) == 0 {
+		return ""
+	}
+
+	min, max := 0.0, 0.0
+	for _, bar := range bars {
+
+		min = math.Min(min, bar.Value)
+		max = math.Max(max, bar.Value)
+
+	}
+
+	if max == min {
+		max = min + 1
+	}
+
+	plotWidth := float64(chartWidth - 2*chartMargin)
+	plotHeight := float64(chartHeight - 2*chartMargin)
+	slot := plotWidth / float64(len(bars))
+
+	y := func(v float64) float64 {
+		return chartMargin + (max-v)/(max-min)*plotHeight
+	}
+
+	var b bytes.Buffer
+
+	openSVG(&b)
+	axes(&b, y(0))
+
+	for i, bar := range bars {
+
+		color := "#2ca02c"
+		if bar.Value < 0 {
+			color = "#d62728"
+		}
+
+		top := math.Min(y(bar.Value), y(0))
+		height := math.Abs(y(bar.Value) - y(0))
+		left := chartMargin + float64(i)*slot + slot*0.15
+
+		fmt.Fprintf(
+			&b,
+			`<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>`,
+			left, top, slot*0.7, height, color,
+		)
+
+		template.HTMLEscape(&b, []byte(fmt.Sprintf("%s: %s", bar.Label, formatAxis(bar.Value, unit))))
+		b.WriteString("</title></rect>")
+
+		label(&b, left+slot*0.35, chartHeight-chartMargin+16, "middle", bar.Label)
+
+	}
+
+	label(&b, chartMargin-4, y(max)+4, "end", formatAxis(max, unit))
+
+	if min < 0 {
+		label(&b, chartMargin-4, y(min)+4, "end", formatAxis(min, unit))
+	}
+
+	b.WriteString("</svg>")
+
+	return template.HTML(b.String())
+}
+
+func openSVG(b *bytes.Buffer) {
+
+	fmt.Fprintf(
+		b,
+		`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d" `+
+			`font-family="sans-serif" font-size="11">`,
+		chartWidth, chartHeight, chartWidth, chartHeight,
+	)
+
+}
+
+// axes draws the y-axis and the x-axis at _zero_.
+func axes(b *bytes.Buffer, zero float64) {
+
+	fmt.Fprintf(
+		b,
+		`<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#999"/>`+
+			`<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#999"/>`,
+		chartMargin, chartMargin, chartMargin, chartHeight-chartMargin,
+		chartMargin, zero, chartWidth-chartMargin, zero,
+	)
+
+}
+
+func label(b *bytes.Buffer, x, y float64, anchor, text string) {
+
+	fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="%s">`, x, y, anchor)
+	template.HTMLEscape(b, []byte(text))
+	b.WriteString("</text>")
+
+}
+
+func formatAxis(v float64, unit string) string {
+	return fmt.Sprintf("%.2f %s", v, unit)
+}
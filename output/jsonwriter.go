@@ -0,0 +1,151 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+)
+
+// Format is the serialization format of the `JSONWriter`.
+type Format string
+
+const (
+	// FormatJSON writes a single `Envelope` with all records in _Data_.
+	FormatJSON Format = "json"
+	// FormatNDJSON writes a `Envelope` header line followed by one record per line.
+	FormatNDJSON Format = "ndjson"
+)
+
+// Envelope kinds.
+const (
+	EnvelopeKindEntries = "entries"
+	EnvelopeKindCandles = "candles"
+)
+
+// JSONWriter implements `common.TxFlushableEntryProcessor` and writes the entries
+// as `EntryRecord` using the _JSON_ or _JSON lines_ `Format`.
+//
+// The entries are written when `Flush` is invoked. Candles are written directly
+// using `WriteCandles`.
+type JSONWriter struct {
+	w       io.Writer
+	format  Format
+	indent  bool
+	assets  []common.AssetType
+	entries []common.TransactionEntry
+}
+
+// NewJSONWriter creates a new writer with specified _w_ as `io.Writer`, if
+// `nil` it will set `os.Stdout` as _w_.
+//
+// If no _assets_ are submitted, all translated assets on each entry is written.
+func NewJSONWriter(w io.Writer, format Format, assets ...common.AssetType) *JSONWriter {
+
+	if w == nil {
+		w = os.Stdout
+	}
+
+	if format != FormatJSON && format != FormatNDJSON {
+		panic("unknown json format: " + string(format))
+	}
+
+	return &JSONWriter{
+		w:       w,
+		format:  format,
+		assets:  assets,
+		entries: []common.TransactionEntry{},
+	}
+}
+
+// UseIndent indents the output when `FormatJSON`.
+func (jw *JSONWriter) UseIndent() *JSONWriter {
+
+	jw.indent = true
+	return jw
+
+}
+
+func (jw *JSONWriter) ProcessMany(tx []common.TransactionEntry) {
+
+	for i := range tx {
+		jw.Process(tx[i])
+	}
+
+}
+
+func (jw *JSONWriter) Process(tx common.TransactionEntry) {
+	jw.entries = append(jw.entries, tx.Clone())
+}
+
+func (jw *JSONWriter) Reset() {
+	jw.entries = []common.TransactionEntry{}
+}
+
+func (jw *JSONWriter) Flush() []common.TransactionEntry {
+
+	entries := jw.entries
+	jw.Reset()
+
+	if err := jw.WriteEntries(entries); err != nil {
+		panic(err)
+	}
+
+	return entries
+}
+
+// WriteEntries writes the _entries_ directly, i.e. without processing them.
+func (jw *JSONWriter) WriteEntries(entries []common.TransactionEntry) error {
+
+	records := make([]interface{}, 0, len(entries))
+
+	for i := range entries {
+		records = append(records, NewEntryRecord(entries[i], jw.assets...))
+	}
+
+	return jw.write(EnvelopeKindEntries, records)
+}
+
+// WriteCandles writes the _candles_ as `CandleRecord`.
+func (jw *JSONWriter) WriteCandles(candles []common.TxOHCHistory) error {
+
+	records := make([]interface{}, 0, len(candles))
+
+	for i := range candles {
+		records = append(records, NewCandleRecord(candles[i]))
+	}
+
+	return jw.write(EnvelopeKindCandles, records)
+}
+
+func (jw *JSONWriter) write(kind string, records []interface{}) error {
+
+	enc := json.NewEncoder(jw.w)
+	env := Envelope{Schema: Schema, Version: SchemaVersion, Kind: kind}
+
+	if jw.format == FormatJSON {
+
+		if jw.indent {
+			enc.SetIndent("", "  ")
+		}
+
+		env.Data = records
+		return enc.Encode(env)
+
+	}
+
+	if err := enc.Encode(env); err != nil {
+		return err
+	}
+
+	for _, rec := range records {
+
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/processors"
+	"github.com/mariotoffia/gocryptoadmin/txlog/testfiles/fixtures"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONWriterWritesVersionedEnvelope(t *testing.T) {
+
+	cbx := fixtures.CoinbasePro(common.AssetTypeEuro)
+	buy, sell := &cbx[0], &cbx[1]
+
+	group := &common.TxGroupEntry{TransactionLog: common.TransactionLog{ID: "grp"}}
+	group.AddTransactionEntry(buy).AddTransactionEntry(sell)
+
+	acc := processors.NewAccountingProcessor("cbx")
+	acc.Process(buy)
+
+	bs := processors.NewTxBuySellProcessor()
+	bs.ProcessMany([]common.TransactionEntry{buy, sell})
+	pairs, _ := bs.Flush()
+	require.Equal(t, 1, len(pairs))
+
+	var buff bytes.Buffer
+
+	jw := NewJSONWriter(&buff, FormatJSON).UseIndent()
+	jw.ProcessMany([]common.TransactionEntry{
+		buy,
+		group,
+		acc.Flush()[0],
+		pairs[0],
+	})
+
+	require.Equal(t, 4, len(jw.Flush()))
+
+	var env struct {
+		Envelope
+		Data []EntryRecord `json:"data"`
+	}
+
+	require.NoError(t, json.Unmarshal(buff.Bytes(), &env))
+
+	assert.Equal(t, Schema, env.Schema)
+	assert.Equal(t, SchemaVersion, env.Version)
+	assert.Equal(t, EnvelopeKindEntries, env.Kind)
+	require.Equal(t, 4, len(env.Data))
+
+	tx := env.Data[0]
+	assert.Equal(t, RecordKindTransaction, tx.Kind)
+	assert.Equal(t, common.AssetTypeXLM, tx.Asset)
+	assert.Equal(t, common.AssetTypeEuro, tx.FeeAsset)
+	assert.Equal(t, -201.337884495, tx.Total)
+	assert.Equal(t, -201.337884495, tx.Translated[common.AssetTypeEuro].Total)
+	assert.Equal(t, "381617", tx.Translated[common.AssetTypeEuro].Provenance[0].TxID)
+
+	grp := env.Data[1]
+	assert.Equal(t, RecordKindGroup, grp.Kind)
+	require.Equal(t, 2, len(grp.Entries))
+	assert.Equal(t, "382592", grp.Entries[1].ID)
+
+	account := env.Data[2]
+	assert.Equal(t, RecordKindAccount, account.Kind)
+	assert.Equal(t, 1782.0, account.Account[common.AssetTypeXLM])
+	assert.Equal(t, -201.337884495, account.Account[common.AssetTypeEuro])
+
+	pair := env.Data[3]
+	assert.Equal(t, RecordKindBuySell, pair.Kind)
+	require.NotNil(t, pair.Sell)
+	assert.Equal(t, "382592", pair.Sell.ID)
+	require.Equal(t, 1, len(pair.Buys))
+	assert.Equal(t, "381617", pair.Buys[0].ID)
+	assert.Equal(t, 131.0, pair.Buys[0].Size)
+
+}
+
+func TestJSONWriterWritesLines(t *testing.T) {
+
+	cbx := fixtures.CoinbasePro(common.AssetTypeEuro)
+	buy, sell := &cbx[0], &cbx[1]
+
+	var buff bytes.Buffer
+
+	jw := NewJSONWriter(&buff, FormatNDJSON, common.AssetTypeEuro)
+	require.NoError(t, jw.WriteEntries([]common.TransactionEntry{buy, sell}))
+
+	scanner := bufio.NewScanner(&buff)
+	lines := []string{}
+
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	require.Equal(t, 3, len(lines))
+	assert.Equal(t, `{"schema":"gocryptoadmin","version":1,"kind":"entries"}`, lines[0])
+
+	var rec EntryRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &rec))
+
+	assert.Equal(t, "382592", rec.ID)
+	assert.Equal(t, common.SideTypeSell, rec.Side)
+	assert.Equal(t, 14.878898125, rec.Translated[common.AssetTypeEuro].Total)
+
+}
+
+func TestJSONWriterWritesCandles(t *testing.T) {
+
+	at, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+
+	var buff bytes.Buffer
+
+	err := NewJSONWriter(&buff, FormatNDJSON).WriteCandles([]common.TxOHCHistory{{
+		Exchange:   "cbx",
+		DateTime:   at,
+		Resolution: 1440,
+		Open:       1,
+		High:       3,
+		Low:        0.5,
+		Close:      2,
+		AssetPair:  common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeEuro},
+	}})
+
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		`{"schema":"gocryptoadmin","version":1,"kind":"candles"}`+"\n"+
+			`{"exchange":"cbx","asset":"BTC","costunit":"EUR","time":"2021-01-01T00:00:00Z",`+
+			`"resolution":1440,"open":1,"high":3,"low":0.5,"close":2,"assetvolume":0,"cuvolume":0}`+"\n",
+		buff.String(),
+	)
+
+}
+
+func TestAccountLogJSONRoundTrip(t *testing.T) {
+
+	cbx := fixtures.CoinbasePro(common.AssetTypeEuro)
+	buy := &cbx[0]
+	acc := common.NextAccountLog(nil, buy)
+
+	data, err := json.Marshal(acc)
+	require.NoError(t, err)
+
+	var back common.AccountLog
+	require.NoError(t, json.Unmarshal(data, &back))
+
+	assert.Equal(t, acc.GetAccountStatus(), back.GetAccountStatus())
+	assert.Equal(t, "381617", back.GetID())
+	assert.Equal(t, -201.337884495, back.GetTotalPrice())
+
+}
@@ -0,0 +1,190 @@
+package output
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+)
+
+// SchemaVersion is the version of the _JSON_ schema, i.e. the `EntryRecord` and
+// `CandleRecord`. It is bumped whenever a field is renamed, removed or changes meaning.
+// Added fields do not bump the version.
+const SchemaVersion = 1
+
+// Schema is the name of the schema written in the `Envelope`.
+const Schema = "gocryptoadmin"
+
+// RecordKind is the kind of `EntryRecord`.
+type RecordKind string
+
+const (
+	// RecordKindTransaction is a plain `common.TransactionLog`.
+	RecordKindTransaction RecordKind = "transaction"
+	// RecordKindGroup is a `common.TxLogGroup` where the grouped are in _Entries_.
+	RecordKindGroup RecordKind = "group"
+	// RecordKindAccount is a `common.AccountEntry` with the status in _Account_.
+	RecordKindAccount RecordKind = "account"
+	// RecordKindBuySell is a `common.TxBuySellEntry` with _Sell_ and _Buys_.
+	RecordKindBuySell RecordKind = "buysell"
+	// RecordKindCandle is a `CandleRecord`.
+	RecordKindCandle RecordKind = "candle"
+)
+
+// Envelope wraps the records written as _JSON_. When writing _JSON_ lines, the
+// envelope, without _Data_, is the first line.
+type Envelope struct {
+	Schema  string      `json:"schema"`
+	Version int         `json:"version"`
+	Kind    string      `json:"kind"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// TranslatedRecord is a translated amount and how it was derived.
+type TranslatedRecord struct {
+	Total      float64                        `json:"total"`
+	Fee        float64                        `json:"fee"`
+	Provenance []common.TranslationProvenance `json:"provenance,omitempty"`
+}
+
+// EntryRecord is the stable representation of a `common.TransactionEntry`.
+type EntryRecord struct {
+	Kind       RecordKind                            `json:"kind"`
+	ID         string                                `json:"id"`
+	Exchange   string                                `json:"exchange"`
+	Side       common.SideType                       `json:"side"`
+	SideID     string                                `json:"sideid,omitempty"`
+	CreatedAt  time.Time                             `json:"created"`
+	Asset      common.AssetType                      `json:"asset"`
+	CostUnit   common.AssetType                      `json:"costunit"`
+	Size       float64                               `json:"size"`
+	Price      float64                               `json:"price"`
+	Fee        float64                               `json:"fee"`
+	FeeAsset   common.AssetType                      `json:"feeasset,omitempty"`
+	Total      float64                               `json:"total"`
+	Translated map[common.AssetType]TranslatedRecord `json:"translated,omitempty"`
+	// Account is set when _Kind_ is `RecordKindAccount`.
+	Account common.AccountStatus `json:"account,omitempty"`
+	// Entries are set when _Kind_ is `RecordKindGroup`.
+	Entries []EntryRecord `json:"entries,omitempty"`
+	// Sell and Buys are set when _Kind_ is `RecordKindBuySell`.
+	Sell *EntryRecord  `json:"sell,omitempty"`
+	Buys []EntryRecord `json:"buys,omitempty"`
+}
+
+// CandleRecord is the stable representation of a `common.TxOHCHistory`.
+type CandleRecord struct {
+	ID             string           `json:"id,omitempty"`
+	Exchange       string           `json:"exchange"`
+	Asset          common.AssetType `json:"asset"`
+	CostUnit       common.AssetType `json:"costunit"`
+	Time           time.Time        `json:"time"`
+	Resolution     int              `json:"resolution"`
+	Open           float64          `json:"open"`
+	High           float64          `json:"high"`
+	Low            float64          `json:"low"`
+	Close          float64          `json:"close"`
+	AssetVolume    float64          `json:"assetvolume"`
+	CostUnitVolume float64          `json:"cuvolume"`
+}
+
+// NewEntryRecord converts _tx_ into a record. If _assets_ are submitted, only those
+// translations are included, otherwise all.
+func NewEntryRecord(tx common.TransactionEntry, assets ...common.AssetType) EntryRecord {
+
+	pair := tx.GetAssetPair()
+
+	rec := EntryRecord{
+		Kind:      RecordKindTransaction,
+		ID:        tx.GetID(),
+		Exchange:  tx.GetExchange(),
+		Side:      tx.GetSide(),
+		SideID:    tx.GetSideIdentifier(),
+		CreatedAt: tx.GetCreatedAt(),
+		Asset:     pair.Asset,
+		CostUnit:  pair.CostUnit,
+		Size:      tx.GetAssetSize(),
+		Price:     tx.GetPricePerUnit(),
+		Fee:       tx.GetFee(),
+		FeeAsset:  tx.GetFeeAsset(),
+		Total:     tx.GetTotalPrice(),
+	}
+
+	if len(assets) == 0 {
+
+		assets = tx.GetTranslatedAssets()
+
+		sort.Slice(assets, func(i, j int) bool {
+			return assets[i] < assets[j]
+		})
+
+	}
+
+	for _, asset := range assets {
+
+		if rec.Translated == nil {
+			rec.Translated = map[common.AssetType]TranslatedRecord{}
+		}
+
+		rec.Translated[asset] = TranslatedRecord{
+			Total:      tx.GetTranslatedTotalPrice(asset),
+			Fee:        tx.GetTranslatedFee(asset),
+			Provenance: tx.GetProvenance(asset),
+		}
+
+	}
+
+	switch entry := tx.(type) {
+	case common.TxBuySellEntry:
+
+		sell := NewEntryRecord(entry.GetSell(), assets...)
+
+		rec.Kind = RecordKindBuySell
+		rec.Sell = &sell
+		rec.Buys = newEntryRecords(entry.GetBuy().GetTransactionEntries(), assets...)
+
+	case common.AccountEntry:
+
+		rec.Kind = RecordKindAccount
+		rec.Account = entry.GetAccountStatus()
+
+	case common.TxLogGroup:
+
+		rec.Kind = RecordKindGroup
+		rec.Entries = newEntryRecords(entry.GetTransactionEntries(), assets...)
+
+	}
+
+	return rec
+}
+
+// NewCandleRecord converts _candle_ into a record.
+func NewCandleRecord(candle common.TxOHCHistory) CandleRecord {
+
+	return CandleRecord{
+		ID:             candle.ID,
+		Exchange:       candle.Exchange,
+		Asset:          candle.Asset,
+		CostUnit:       candle.CostUnit,
+		Time:           candle.DateTime,
+		Resolution:     candle.Resolution,
+		Open:           candle.Open,
+		High:           candle.High,
+		Low:            candle.Low,
+		Close:          candle.Close,
+		AssetVolume:    candle.AssetVolume,
+		CostUnitVolume: candle.CostUnitVolume,
+	}
+
+}
+
+func newEntryRecords(tx []common.TransactionEntry, assets ...common.AssetType) []EntryRecord {
+
+	list := make([]EntryRecord, 0, len(tx))
+
+	for i := range tx {
+		list = append(list, NewEntryRecord(tx[i], assets...))
+	}
+
+	return list
+}
@@ -0,0 +1,108 @@
+package sie
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+)
+
+// Account is a account in the chart of accounts.
+type Account struct {
+	Number int    `json:"number"`
+	Name   string `json:"name"`
+}
+
+// Accounts maps the transactions onto the chart of accounts.
+type Accounts struct {
+	// Crypto is the default account for all crypto assets.
+	Crypto Account `json:"crypto"`
+	// Fiat is the default account for all _FIAT_ held on exchanges.
+	Fiat Account `json:"fiat"`
+	// Assets overrides the _Crypto_ and _Fiat_ account for a specific asset.
+	Assets map[common.AssetType]Account `json:"assets,omitempty"`
+	// Fee is the expense account for all fees.
+	Fee Account `json:"fee"`
+	// Gain is where the realized gains (and losses) are booked.
+	Gain Account `json:"gain"`
+	// Receive is the counter account when receiving assets, e.g. from the owner.
+	Receive Account `json:"receive"`
+	// Transfer is the counter account when transferring assets out.
+	Transfer Account `json:"transfer"`
+	// Rounding is where the rounding difference, to whole öre, is booked.
+	Rounding Account `json:"rounding"`
+}
+
+// DefaultAccounts returns a mapping onto the _BAS_ chart of accounts.
+func DefaultAccounts() Accounts {
+
+	return Accounts{
+		Crypto:   Account{Number: 1880, Name: "Andra kortfristiga placeringar"},
+		Fiat:     Account{Number: 1680, Name: "Andra kortfristiga fordringar"},
+		Assets:   map[common.AssetType]Account{},
+		Fee:      Account{Number: 6570, Name: "Bankkostnader"},
+		Gain:     Account{Number: 8220, Name: "Resultat vid försäljning av värdepapper"},
+		Receive:  Account{Number: 2893, Name: "Skulder till närstående personer, kortfristig del"},
+		Transfer: Account{Number: 2893, Name: "Skulder till närstående personer, kortfristig del"},
+		Rounding: Account{Number: 3740, Name: "Öres- och kronutjämning"},
+	}
+
+}
+
+// ParseAccounts parses a _JSON_ mapping on top of the `DefaultAccounts`. Hence only
+// the accounts that differs needs to be specified.
+func ParseAccounts(data []byte) (Accounts, error) {
+
+	accounts := DefaultAccounts()
+
+	if err := json.Unmarshal(data, &accounts); err != nil {
+		return Accounts{}, err
+	}
+
+	if err := accounts.Validate(); err != nil {
+		return Accounts{}, err
+	}
+
+	return accounts, nil
+}
+
+// Validate checks that all accounts have a number.
+func (a *Accounts) Validate() error {
+
+	named := map[string]Account{
+		"crypto": a.Crypto, "fiat": a.Fiat, "fee": a.Fee, "gain": a.Gain,
+		"receive": a.Receive, "transfer": a.Transfer, "rounding": a.Rounding,
+	}
+
+	for _, name := range []string{"crypto", "fiat", "fee", "gain", "receive", "transfer", "rounding"} {
+
+		if named[name].Number <= 0 {
+			return fmt.Errorf("account: %s is missing number", name)
+		}
+
+	}
+
+	for asset, account := range a.Assets {
+
+		if account.Number <= 0 {
+			return fmt.Errorf("account for asset: %s is missing number", asset)
+		}
+
+	}
+
+	return nil
+}
+
+// ForAsset returns the account where _asset_ is held.
+func (a *Accounts) ForAsset(asset common.AssetType) Account {
+
+	if account, ok := a.Assets[asset]; ok {
+		return account
+	}
+
+	if asset.IsFIAT() {
+		return a.Fiat
+	}
+
+	return a.Crypto
+}
@@ -0,0 +1,86 @@
+package sie
+
+import (
+	"bytes"
+	"io"
+)
+
+// cp437 is the upper half (0x80 - 0xFF) of code page 437, i.e. the _PC8_ character
+// set that _SIE_ files are encoded in.
+var cp437 = []rune(
+	"ÇüéâäàåçêëèïîìÄÅ" +
+		"ÉæÆôöòûùÿÖÜ¢£¥₧ƒ" +
+		"áíóúñÑªº¿⌐¬½¼¡«»" +
+		"░▒▓│┤╡╢╖╕╣║╗╝╜╛┐" +
+		"└┴┬├─┼╞╟╚╔╩╦╠═╬╧" +
+		"╨╤╥╙╘╒╓╫╪┘┌█▄▌▐▀" +
+		"αßΓπΣσµτΦΘΩδ∞φε∩" +
+		"≡±≥≤⌠⌡÷≈°∙·√ⁿ²■\u00a0",
+)
+
+var cp437Encoding = func() map[rune]byte {
+
+	m := map[rune]byte{}
+	for i, r := range cp437 {
+		m[r] = byte(0x80 + i)
+	}
+
+	return m
+
+}()
+
+// EncodePC8 encodes _s_ into code page 437. Characters that do not exist in the code
+// page are replaced with _?_.
+func EncodePC8(s string) []byte {
+
+	var b bytes.Buffer
+
+	for _, r := range s {
+
+		if r < 0x80 {
+
+			b.WriteByte(byte(r))
+			continue
+
+		}
+
+		if c, ok := cp437Encoding[r]; ok {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('?')
+		}
+
+	}
+
+	return b.Bytes()
+}
+
+// DecodePC8 decodes code page 437 _data_.
+func DecodePC8(data []byte) string {
+
+	runes := make([]rune, len(data))
+
+	for i, c := range data {
+
+		if c < 0x80 {
+			runes[i] = rune(c)
+		} else {
+			runes[i] = cp437[c-0x80]
+		}
+
+	}
+
+	return string(runes)
+}
+
+// pc8Writer encodes all written (_UTF-8_) strings into code page 437.
+type pc8Writer struct {
+	w io.Writer
+}
+
+func (pw *pc8Writer) WriteString(s string) error {
+
+	_, err := pw.w.Write(EncodePC8(s))
+	return err
+
+}
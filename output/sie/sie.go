@@ -0,0 +1,454 @@
+// Package sie exports the processed transactions as _SIE4_ verifications, in _SEK_,
+// to be imported into a Swedish bookkeeping system.
+//
+// Each transaction renders one verification where the cash leg (the cost unit for a
+// _BUY_ and _SELL_, the asset for a _RECEIVE_ and _TRANSFER_) is booked with the
+// translated total price. The counter leg (the asset for a _BUY_ and _SELL_, the
+// `Accounts.Receive` or `Accounts.Transfer` account otherwise) balances it and the fee is
+// booked as a expense. Hence sells are credited the asset account at the sale value.
+//
+// The realized gain, from a `common.TxBuySellEntry`, renders a separate verification
+// that adjusts the asset account from sale value to acquisition value against the
+// `Accounts.Gain` account.
+//
+// The translated total price is expected to include the fee, as the total price does,
+// when the fee is charged in the cost unit.
+package sie
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+)
+
+// Exporter collects the verifications and writes them as a _SIE4_ file.
+type Exporter struct {
+	accounts  Accounts
+	company   string
+	orgNumber string
+	series    string
+	generated time.Time
+	location  *time.Location
+	yearStart time.Time
+	yearEnd   time.Time
+	tx        []common.TransactionEntry
+	gains     []common.TxBuySellEntry
+}
+
+// Verification is a balanced set of transactions on a date.
+type Verification struct {
+	Number int
+	Date   time.Time
+	Text   string
+	Rows   []Row
+}
+
+// Row is a single transaction row in a `Verification`. A positive _Amount_ is debit.
+type Row struct {
+	Account Account
+	Amount  float64
+}
+
+// NewExporter creates a exporter that maps onto _accounts_.
+func NewExporter(accounts Accounts) *Exporter {
+
+	if err := accounts.Validate(); err != nil {
+		panic(err)
+	}
+
+	return &Exporter{
+		accounts:  accounts,
+		series:    "A",
+		generated: time.Now(),
+		location:  time.UTC,
+		tx:        []common.TransactionEntry{},
+		gains:     []common.TxBuySellEntry{},
+	}
+
+}
+
+// UseCompany sets the company name and organisation number.
+func (e *Exporter) UseCompany(name, orgNumber string) *Exporter {
+
+	e.company = name
+	e.orgNumber = orgNumber
+	return e
+
+}
+
+// UseSeries sets the verification series (default _A_).
+func (e *Exporter) UseSeries(series string) *Exporter {
+
+	e.series = series
+	return e
+
+}
+
+// UseGenerated sets the generation date (default now).
+func (e *Exporter) UseGenerated(at time.Time) *Exporter {
+
+	e.generated = at
+	return e
+
+}
+
+// UseLocation sets the location to render the verification dates in (default _UTC_).
+func (e *Exporter) UseLocation(location *time.Location) *Exporter {
+
+	e.location = location
+	return e
+
+}
+
+// UseFiscalYear sets the fiscal year. If not set, it is the calendar year(s) of the
+// verifications.
+func (e *Exporter) UseFiscalYear(start, end time.Time) *Exporter {
+
+	e.yearStart = start
+	e.yearEnd = end
+	return e
+
+}
+
+// AddTransactions adds _tx_ that are booked as buys, sells, receives or transfers.
+func (e *Exporter) AddTransactions(tx ...common.TransactionEntry) *Exporter {
+
+	e.tx = append(e.tx, tx...)
+	return e
+
+}
+
+// AddGains adds realized gains, as returned by `TxBuySellProcessor.Flush`.
+func (e *Exporter) AddGains(entries ...common.TxBuySellEntry) *Exporter {
+
+	e.gains = append(e.gains, entries...)
+	return e
+
+}
+
+// Verifications creates the verifications, numbered and sorted by date.
+func (e *Exporter) Verifications() ([]Verification, error) {
+
+	list := []Verification{}
+
+	for _, tx := range e.tx {
+
+		ver, err := e.transaction(tx)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ver.Rows) > 0 {
+			list = append(list, ver)
+		}
+
+	}
+
+	for _, entry := range e.gains {
+
+		ver, err := e.gain(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ver.Rows) > 0 {
+			list = append(list, ver)
+		}
+
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Date.Before(list[j].Date)
+	})
+
+	for i := range list {
+		list[i].Number = i + 1
+	}
+
+	return list, nil
+}
+
+// Write writes the _SIE4_ file, encoded as _PC8_, onto _w_.
+func (e *Exporter) Write(w io.Writer) error {
+
+	verifications, err := e.Verifications()
+	if err != nil {
+		return err
+	}
+
+	start, end := e.fiscalYear(verifications)
+
+	lines := []string{
+		"#FLAGGA 0",
+		"#FORMAT PC8",
+		"#SIETYP 4",
+		`#PROGRAM "gocryptoadmin" 1.0`,
+		"#GEN " + e.generated.Format("20060102"),
+	}
+
+	if e.company != "" {
+		lines = append(lines, "#FNAMN "+quote(e.company))
+	}
+
+	if e.orgNumber != "" {
+		lines = append(lines, "#ORGNR "+e.orgNumber)
+	}
+
+	lines = append(
+		lines,
+		fmt.Sprintf("#RAR 0 %s %s", start.Format("20060102"), end.Format("20060102")),
+		"#KPTYP BAS2014",
+		"#VALUTA SEK",
+	)
+
+	for _, account := range usedAccounts(verifications) {
+		lines = append(lines, fmt.Sprintf("#KONTO %d %s", account.Number, quote(account.Name)))
+	}
+
+	for _, ver := range verifications {
+
+		lines = append(
+			lines,
+			fmt.Sprintf(
+				"#VER %s %d %s %s", e.series, ver.Number, ver.Date.Format("20060102"), quote(ver.Text),
+			),
+			"{",
+		)
+
+		for _, row := range ver.Rows {
+			lines = append(lines, fmt.Sprintf("   #TRANS %d {} %.2f", row.Account.Number, row.Amount))
+		}
+
+		lines = append(lines, "}")
+
+	}
+
+	pw := &pc8Writer{w: w}
+
+	for _, line := range lines {
+
+		if err := pw.WriteString(line + "\r\n"); err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+}
+
+func (e *Exporter) transaction(tx common.TransactionEntry) (Verification, error) {
+
+	total, fee, err := translated(tx)
+	if err != nil {
+		return Verification{}, err
+	}
+
+	pair := tx.GetAssetPair()
+	feeAsset := tx.GetFeeAsset()
+	included := feeAsset == "" || feeAsset == pair.CostUnit
+
+	var cash, counter Account
+
+	switch tx.GetSide() {
+	case common.SideTypeBuy, common.SideTypeSell:
+
+		cash = e.accounts.ForAsset(pair.CostUnit)
+		counter = e.accounts.ForAsset(pair.Asset)
+
+	case common.SideTypeReceive:
+
+		cash = e.accounts.ForAsset(pair.Asset)
+		counter = e.accounts.Receive
+
+	case common.SideTypeTransfer:
+
+		cash = e.accounts.ForAsset(pair.Asset)
+		counter = e.accounts.Transfer
+
+	default:
+		return Verification{}, fmt.Errorf("transaction: %s has unsupported side: %s", tx.GetID(), tx.GetSide())
+	}
+
+	rows := []Row{{Account: cash, Amount: total}}
+
+	if included {
+		rows = append(rows, Row{Account: counter, Amount: -total - fee})
+	} else {
+
+		rows = append(
+			rows,
+			Row{Account: counter, Amount: -total},
+			Row{Account: e.accounts.ForAsset(feeAsset), Amount: -fee},
+		)
+
+	}
+
+	rows = append(rows, Row{Account: e.accounts.Fee, Amount: fee})
+
+	text := fmt.Sprintf("%s %s %s %s", tx.GetSide(), pair.String(), tx.GetExchange(), tx.GetID())
+
+	return e.verification(tx.GetCreatedAt(), text, rows), nil
+}
+
+// gain adjusts the asset account from the sale value, that the sell was credited with,
+// to the acquisition value that the buys was debited with.
+func (e *Exporter) gain(entry common.TxBuySellEntry) (Verification, error) {
+
+	sellTotal, sellFee, err := translated(entry.GetSell())
+	if err != nil {
+		return Verification{}, err
+	}
+
+	buyTotal, buyFee, err := translated(entry.GetBuy())
+	if err != nil {
+		return Verification{}, err
+	}
+
+	proceeds := sellTotal + includedFee(entry.GetSell(), sellFee)
+	cost := -buyTotal - includedFee(entry.GetBuy(), buyFee)
+	gain := proceeds - cost
+
+	pair := entry.GetAssetPair()
+
+	rows := []Row{
+		{Account: e.accounts.ForAsset(pair.Asset), Amount: gain},
+		{Account: e.accounts.Gain, Amount: -gain},
+	}
+
+	text := fmt.Sprintf("Realized %s %s %s", pair.String(), entry.GetExchange(), entry.GetSell().GetID())
+
+	return e.verification(entry.GetSell().GetCreatedAt(), text, rows), nil
+}
+
+// verification rounds, merges and balances the _rows_.
+func (e *Exporter) verification(at time.Time, text string, rows []Row) Verification {
+
+	merged := []Row{}
+	index := map[int]int{}
+
+	for _, row := range rows {
+
+		if i, ok := index[row.Account.Number]; ok {
+
+			merged[i].Amount += row.Amount
+			continue
+
+		}
+
+		index[row.Account.Number] = len(merged)
+		merged = append(merged, row)
+
+	}
+
+	ver := Verification{Date: at.In(e.location), Text: text, Rows: []Row{}}
+	sum := 0.0
+
+	for _, row := range merged {
+
+		row.Amount = roundOre(row.Amount)
+
+		if row.Amount != 0 {
+
+			ver.Rows = append(ver.Rows, row)
+			sum += row.Amount
+
+		}
+
+	}
+
+	if diff := roundOre(-sum); diff != 0 && len(ver.Rows) > 0 {
+		ver.Rows = append(ver.Rows, Row{Account: e.accounts.Rounding, Amount: diff})
+	}
+
+	return ver
+}
+
+func (e *Exporter) fiscalYear(verifications []Verification) (time.Time, time.Time) {
+
+	if !e.yearStart.IsZero() && !e.yearEnd.IsZero() {
+		return e.yearStart, e.yearEnd
+	}
+
+	first, last := e.generated.In(e.location), e.generated.In(e.location)
+
+	if len(verifications) > 0 {
+
+		first = verifications[0].Date
+		last = verifications[len(verifications)-1].Date
+
+	}
+
+	return time.Date(first.Year(), time.January, 1, 0, 0, 0, 0, e.location),
+		time.Date(last.Year(), time.December, 31, 0, 0, 0, 0, e.location)
+}
+
+func translated(tx common.TransactionEntry) (total float64, fee float64, err error) {
+
+	for _, asset := range tx.GetTranslatedAssets() {
+
+		if asset == common.AssetTypeSvenskKrona {
+
+			return tx.GetTranslatedTotalPrice(common.AssetTypeSvenskKrona),
+				tx.GetTranslatedFee(common.AssetTypeSvenskKrona), nil
+
+		}
+
+	}
+
+	return 0, 0, fmt.Errorf("transaction: %s is not translated to SEK", tx.GetID())
+}
+
+func includedFee(tx common.TransactionEntry, fee float64) float64 {
+
+	if feeAsset := tx.GetFeeAsset(); feeAsset == "" || feeAsset == tx.GetAssetPair().CostUnit {
+		return fee
+	}
+
+	return 0
+}
+
+func usedAccounts(verifications []Verification) []Account {
+
+	seen := map[int]bool{}
+	list := []Account{}
+
+	for _, ver := range verifications {
+
+		for _, row := range ver.Rows {
+
+			if !seen[row.Account.Number] {
+
+				seen[row.Account.Number] = true
+				list = append(list, row.Account)
+
+			}
+
+		}
+
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Number < list[j].Number
+	})
+
+	return list
+}
+
+func roundOre(v float64) float64 {
+
+	r := math.Round(v*100) / 100
+	if r == 0 {
+		return 0 // no negative zero
+	}
+
+	return r
+}
+
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
@@ -0,0 +1,125 @@
+package sie
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/processors"
+	"github.com/mariotoffia/gocryptoadmin/txlog/testfiles/fixtures"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPC8RoundTrip(t *testing.T) {
+
+	assert.Equal(t, 128, len(cp437))
+
+	encoded := EncodePC8("Öres- och kronutjämning på €")
+
+	assert.Equal(t, byte(0x99), encoded[0])
+	assert.Equal(t, "Öres- och kronutjämning på ?", DecodePC8(encoded))
+
+}
+
+func TestParseAccountsOverridesDefaults(t *testing.T) {
+
+	accounts, err := ParseAccounts([]byte(`{"crypto":{"number":1350,"name":"Kryptotillgångar"},"assets":{"BTC":{"number":1351,"name":"Bitcoin"}}}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1351, accounts.ForAsset(common.AssetTypeBTC).Number)
+	assert.Equal(t, 1350, accounts.ForAsset(common.AssetTypeETH).Number)
+	assert.Equal(t, 1680, accounts.ForAsset(common.AssetTypeSvenskKrona).Number)
+	assert.Equal(t, 6570, accounts.Fee.Number)
+
+	_, err = ParseAccounts([]byte(`{"fee":{"number":0}}`))
+	assert.EqualError(t, err, "account: fee is missing number")
+
+}
+
+func TestExportVerifications(t *testing.T) {
+
+	entries := fixtures.Entries(fixtures.CoinbasePro(common.AssetTypeSvenskKrona))
+
+	bs := processors.NewTxBuySellProcessor()
+	bs.ProcessMany(entries)
+	pairs, _ := bs.Flush()
+
+	generated, _ := time.Parse(time.RFC3339, "2019-12-31T00:00:00Z")
+
+	var buff bytes.Buffer
+
+	err := NewExporter(DefaultAccounts()).
+		UseCompany(`Kryptö "AB"`, "556000-0000").
+		UseGenerated(generated).
+		AddTransactions(entries...).
+		AddGains(pairs...).
+		Write(&buff)
+
+	require.NoError(t, err)
+
+	sie := DecodePC8(buff.Bytes())
+	lines := strings.Split(sie, "\r\n")
+
+	assert.Equal(t, "#FLAGGA 0", lines[0])
+	assert.Contains(t, lines, "#GEN 20191231")
+	assert.Contains(t, lines, `#FNAMN "Kryptö \"AB\""`)
+	assert.Contains(t, lines, "#ORGNR 556000-0000")
+	assert.Contains(t, lines, "#RAR 0 20190101 20191231")
+	assert.Contains(t, lines, `#KONTO 8220 "Resultat vid försäljning av värdepapper"`)
+
+	// The buy debits the crypto account with the acquisition value
+	assert.Contains(t, sie, "#VER A 1 20190626 \"BUY XLM-EUR cbx 381617\"\r\n{\r\n"+
+		"   #TRANS 1680 {} -2013.38\r\n"+
+		"   #TRANS 1880 {} 2010.36\r\n"+
+		"   #TRANS 6570 {} 3.02\r\n}")
+
+	// The sell credits the crypto account with the sale value
+	assert.Contains(t, sie, "#VER A 2 20190626 \"SELL XLM-EUR cbx 382592\"\r\n{\r\n"+
+		"   #TRANS 1680 {} 148.79\r\n"+
+		"   #TRANS 1880 {} -149.01\r\n"+
+		"   #TRANS 6570 {} 0.22\r\n}")
+
+	// Sold 131 XLM for 149.01 with a acquisition value of 2010.36 * 131 / 1782
+	assert.Contains(t, sie, "#VER A 3 20190626 \"Realized XLM-EUR cbx 382592\"\r\n{\r\n"+
+		"   #TRANS 1880 {} 1.22\r\n"+
+		"   #TRANS 8220 {} -1.22\r\n}")
+
+	assert.Contains(t, sie, "#VER A 5 20190626 \"Realized XLM-EUR cbx 382593\"\r\n{\r\n"+
+		"   #TRANS 1880 {} 4.10\r\n"+
+		"   #TRANS 8220 {} -4.10\r\n}")
+
+}
+
+func TestRoundingIsBooked(t *testing.T) {
+
+	// The translated total and fee has more than two decimals
+	cbx := fixtures.CoinbasePro(common.AssetTypeSvenskKrona)
+	buy := &cbx[0]
+
+	ver, err := NewExporter(DefaultAccounts()).AddTransactions(buy).Verifications()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(ver))
+
+	sum := 0.0
+	for _, row := range ver[0].Rows {
+		sum += row.Amount
+	}
+
+	assert.InDelta(t, 0, sum, 0.001)
+
+}
+
+func TestMissingSEKTranslationFails(t *testing.T) {
+
+	cbx := fixtures.CoinbasePro(common.AssetTypeSvenskKrona)
+	buy := &cbx[0]
+	buy.TranslatedTotalPrice = map[string]float64{"EUR": -201.337884495}
+	buy.TranslatedFee = map[string]float64{"EUR": 0.301554495}
+
+	err := NewExporter(DefaultAccounts()).AddTransactions(buy).Write(&bytes.Buffer{})
+	assert.EqualError(t, err, "transaction: 381617 is not translated to SEK")
+
+}
@@ -0,0 +1,37 @@
+package taxtool
+
+// coinTracking renders the _CoinTracking_ _CSV_ import format.
+type coinTracking struct{}
+
+func (c *coinTracking) header() []string {
+
+	return []string{
+		"Type", "Buy Amount", "Buy Currency", "Sell Amount", "Sell Currency",
+		"Fee", "Fee Currency", "Exchange", "Trade-Group", "Comment", "Date",
+	}
+
+}
+
+func (c *coinTracking) row(ev *event, w *Writer) []string {
+
+	kind := "Trade"
+
+	switch ev.kind {
+	case eventDeposit:
+		kind = "Deposit"
+	case eventWithdrawal:
+		kind = "Withdrawal"
+	}
+
+	return []string{
+		kind,
+		number(ev.received), string(ev.receivedAsset),
+		number(ev.sent), string(ev.sentAsset),
+		number(ev.fee), string(ev.feeAsset),
+		ev.tx.Exchange,
+		"",
+		ev.tx.ID,
+		ev.tx.CreatedAt.In(w.location).Format("2006-01-02 15:04:05"),
+	}
+
+}
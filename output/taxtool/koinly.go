@@ -0,0 +1,39 @@
+package taxtool
+
+import (
+	"fmt"
+)
+
+// koinly renders the _Koinly_ universal format. Deposits and withdrawals has no label
+// and are hence treated as transfers between own wallets, until labelled in _Koinly_.
+//
+// The dates are always in _UTC_, regardless of `Writer.UseLocation`, since _Koinly_ does
+// not accept all time zone abbreviations, e.g. _CEST_.
+type koinly struct{}
+
+func (k *koinly) header() []string {
+
+	return []string{
+		"Date", "Sent Amount", "Sent Currency", "Received Amount", "Received Currency",
+		"Fee Amount", "Fee Currency", "Net Worth Amount", "Net Worth Currency",
+		"Label", "Description", "TxHash",
+	}
+
+}
+
+func (k *koinly) row(ev *event, w *Writer) []string {
+
+	worth, worthAsset := w.netWorthOf(ev.tx)
+
+	return []string{
+		ev.tx.CreatedAt.UTC().Format("2006-01-02 15:04:05") + " UTC",
+		number(ev.sent), string(ev.sentAsset),
+		number(ev.received), string(ev.receivedAsset),
+		number(ev.fee), string(ev.feeAsset),
+		worth, worthAsset,
+		"",
+		fmt.Sprintf("%s %s %s", ev.tx.Exchange, ev.tx.Side, ev.tx.ID),
+		"",
+	}
+
+}
@@ -0,0 +1,303 @@
+// Package taxtool exports `common.TransactionLog` to the universal _CSV_ import
+// formats of third party crypto tax services. This is to be able to compare their
+// numbers with ours, given the same data.
+//
+// Each transaction is mapped onto a trade, deposit or withdrawal where the sent and
+// received amounts are before fees and the fee is a separate column. The services
+// deducts the fee from the balance of the fee currency. Hence the balances will be
+// the same as in the `processors.AccountingProcessor`.
+//
+// Transactions that cannot be represented are not written, instead these are returned
+// as `Unsupported` and may be written using `WriteUnsupported`.
+package taxtool
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/utils"
+)
+
+// Format is a third party import format.
+type Format string
+
+const (
+	// FormatKoinly is the _Koinly_ universal _CSV_ format.
+	FormatKoinly Format = "koinly"
+	// FormatCoinTracking is the _CoinTracking_ _CSV_ import format.
+	FormatCoinTracking Format = "cointracking"
+)
+
+// Formats returns all supported formats.
+func Formats() []Format {
+	return []Format{FormatKoinly, FormatCoinTracking}
+}
+
+// Unsupported is a transaction that could not be exported.
+type Unsupported struct {
+	ID        string          `json:"id"`
+	Exchange  string          `json:"exchange"`
+	Side      common.SideType `json:"side"`
+	CreatedAt time.Time       `json:"created"`
+	Reason    string          `json:"reason"`
+}
+
+func (u Unsupported) String() string {
+
+	return fmt.Sprintf(
+		"%s %s (%s) %s: %s", u.CreatedAt.Format(time.RFC3339), u.Exchange, u.ID, u.Side, u.Reason,
+	)
+
+}
+
+type eventKind int
+
+const (
+	eventTrade eventKind = iota
+	eventDeposit
+	eventWithdrawal
+)
+
+// event is the format neutral representation of a transaction.
+type event struct {
+	kind          eventKind
+	tx            *common.TransactionLog
+	sent          float64
+	sentAsset     common.AssetType
+	received      float64
+	receivedAsset common.AssetType
+	fee           float64
+	feeAsset      common.AssetType
+}
+
+// formatter renders events in a specific format.
+type formatter interface {
+	header() []string
+	row(ev *event, w *Writer) []string
+}
+
+// Writer writes transactions in a third party format.
+type Writer struct {
+	formatter formatter
+	location  *time.Location
+	netWorth  common.AssetType
+}
+
+// NewWriter creates a writer of _format_.
+func NewWriter(format Format) (*Writer, error) {
+
+	var f formatter
+
+	switch format {
+	case FormatKoinly:
+		f = &koinly{}
+	case FormatCoinTracking:
+		f = &coinTracking{}
+	default:
+		return nil, fmt.Errorf("unknown format: %s", format)
+	}
+
+	return &Writer{formatter: f, location: time.UTC}, nil
+}
+
+// UseLocation sets the location to render the dates in (default _UTC_). Formats that
+// include the time zone, e.g. _Koinly_, always uses _UTC_.
+func (w *Writer) UseLocation(location *time.Location) *Writer {
+
+	w.location = location
+	return w
+
+}
+
+// UseNetWorth sets the asset, from the translated total price, to report the value
+// of each transaction in. Only formats with such column uses it.
+func (w *Writer) UseNetWorth(asset common.AssetType) *Writer {
+
+	w.netWorth = asset
+	return w
+
+}
+
+// Write writes _tx_, including the header, onto _out_. The transactions that could not
+// be represented are returned.
+func (w *Writer) Write(out io.Writer, tx []common.TransactionLog) ([]Unsupported, error) {
+
+	unsupported := []Unsupported{}
+
+	cw := csv.NewWriter(out)
+
+	if err := cw.Write(w.formatter.header()); err != nil {
+		return nil, err
+	}
+
+	for i := range tx {
+
+		ev, reason := toEvent(&tx[i])
+
+		if reason != "" {
+
+			unsupported = append(unsupported, Unsupported{
+				ID:        tx[i].ID,
+				Exchange:  tx[i].Exchange,
+				Side:      tx[i].Side,
+				CreatedAt: tx[i].CreatedAt,
+				Reason:    reason,
+			})
+
+			continue
+
+		}
+
+		if err := cw.Write(w.formatter.row(ev, w)); err != nil {
+			return nil, err
+		}
+
+	}
+
+	cw.Flush()
+	return unsupported, cw.Error()
+}
+
+// WriteUnsupported writes the _list_ as _CSV_ onto _out_.
+func WriteUnsupported(out io.Writer, list []Unsupported) error {
+
+	cw := csv.NewWriter(out)
+
+	if err := cw.Write([]string{"id", "exchange", "side", "created", "reason"}); err != nil {
+		return err
+	}
+
+	for _, u := range list {
+
+		row := []string{u.ID, u.Exchange, string(u.Side), u.CreatedAt.Format(time.RFC3339), u.Reason}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// toEvent maps _tx_ onto a event. If not possible, the reason is returned.
+func toEvent(tx *common.TransactionLog) (*event, string) {
+
+	asset := tx.AssetPair.Asset
+	costUnit := tx.AssetPair.CostUnit
+
+	if asset == "" || costUnit == "" {
+		return nil, "missing asset or cost unit"
+	}
+
+	ev := &event{tx: tx, fee: tx.Fee, feeAsset: tx.FeeAsset}
+
+	if ev.feeAsset == "" {
+		ev.feeAsset = costUnit
+	}
+
+	if ev.fee == 0 {
+		ev.feeAsset = ""
+	}
+
+	// Fee in the cost unit is part of the total
+	included := 0.0
+	if ev.feeAsset == costUnit {
+		included = ev.fee
+	}
+
+	total := math.Abs(tx.TotalPrice)
+
+	switch tx.Side {
+	case common.SideTypeBuy, common.SideTypeSell:
+
+		if asset == costUnit {
+			return nil, fmt.Sprintf("trade of %s to itself", asset)
+		}
+
+		ev.kind = eventTrade
+
+		if tx.Side == common.SideTypeBuy {
+
+			ev.received, ev.receivedAsset = tx.AssetSize, asset
+			ev.sent, ev.sentAsset = total-included, costUnit
+
+		} else {
+
+			ev.sent, ev.sentAsset = tx.AssetSize, asset
+			ev.received, ev.receivedAsset = total+included, costUnit
+
+		}
+
+	case common.SideTypeReceive, common.SideTypeTransfer:
+
+		// Received or transferred the cost unit itself, otherwise the asset that may be
+		// priced in the cost unit, e.g. a staking reward
+		size, sizeAsset := total+included, costUnit
+		if tx.Side == common.SideTypeTransfer {
+			size = total - included
+		}
+
+		if asset != costUnit {
+
+			size, sizeAsset = tx.AssetSize, asset
+
+			if size == 0 && tx.PricePerUnit != 0 {
+				size = math.Abs(tx.TotalPrice+included) / tx.PricePerUnit
+			}
+
+			if size == 0 {
+				return nil, fmt.Sprintf("size of %s %s is unknown", tx.Side, asset)
+			}
+
+		}
+
+		if tx.Side == common.SideTypeReceive {
+
+			ev.kind = eventDeposit
+			ev.received, ev.receivedAsset = size, sizeAsset
+
+		} else {
+
+			ev.kind = eventWithdrawal
+			ev.sent, ev.sentAsset = size, sizeAsset
+
+		}
+
+	default:
+		return nil, fmt.Sprintf("side %s is not supported", tx.Side)
+	}
+
+	return ev, ""
+}
+
+// netWorthOf returns the value of the transaction or empty strings if not translated.
+func (w *Writer) netWorthOf(tx *common.TransactionLog) (string, string) {
+
+	if w.netWorth == "" {
+		return "", ""
+	}
+
+	if _, ok := tx.TranslatedTotalPrice[string(w.netWorth)]; !ok {
+		return "", ""
+	}
+
+	return number(math.Abs(tx.GetTranslatedTotalPrice(w.netWorth))), string(w.netWorth)
+}
+
+// number renders _v_ with at most 8 decimals, empty if zero.
+func number(v float64) string {
+
+	v = utils.ToFixed(v, 8)
+	if v == 0 {
+		return ""
+	}
+
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
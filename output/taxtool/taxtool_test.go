@@ -0,0 +1,170 @@
+package taxtool
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func toolEntries() []common.TransactionLog {
+
+	created, _ := time.Parse(time.RFC3339, "2021-01-01T10:00:00Z")
+
+	return []common.TransactionLog{
+		{
+			ID: "1", Exchange: "krk", Side: common.SideTypeReceive, CreatedAt: created,
+			AssetSize: 500, PricePerUnit: 1, TotalPrice: 500,
+			AssetPair: common.AssetPair{Asset: common.AssetTypeEuro, CostUnit: common.AssetTypeEuro},
+		},
+		{
+			ID: "2", Exchange: "krk", Side: common.SideTypeBuy, CreatedAt: created.Add(time.Hour),
+			AssetSize: 2, PricePerUnit: 100, Fee: 1, FeeAsset: common.AssetTypeEuro, TotalPrice: -201,
+			AssetPair:            common.AssetPair{Asset: common.AssetTypeLTC, CostUnit: common.AssetTypeEuro},
+			TranslatedTotalPrice: map[string]float64{"SEK": -2010},
+		},
+		{
+			ID: "3", Exchange: "krk", Side: common.SideTypeSell, CreatedAt: created.Add(2 * time.Hour),
+			AssetSize: 1, PricePerUnit: 0.01, Fee: 0.1, FeeAsset: common.AssetTypeBNB, TotalPrice: 0.01,
+			AssetPair: common.AssetPair{Asset: common.AssetTypeLTC, CostUnit: common.AssetTypeBTC},
+		},
+		{
+			ID: "4", Exchange: "krk", Side: common.SideTypeTransfer, CreatedAt: created.Add(3 * time.Hour),
+			AssetSize: 0.5, PricePerUnit: 1, Fee: 0.01, FeeAsset: common.AssetTypeLTC, TotalPrice: -0.51,
+			AssetPair: common.AssetPair{Asset: common.AssetTypeLTC, CostUnit: common.AssetTypeLTC},
+		},
+		{
+			ID: "5", Exchange: "krk", Side: common.SideTypeBuySell, CreatedAt: created.Add(4 * time.Hour),
+			AssetPair: common.AssetPair{Asset: common.AssetTypeLTC, CostUnit: common.AssetTypeEuro},
+		},
+	}
+
+}
+
+func TestKoinly(t *testing.T) {
+
+	w, err := NewWriter(FormatKoinly)
+	require.NoError(t, err)
+
+	var buff bytes.Buffer
+
+	unsupported, err := w.UseNetWorth(common.AssetTypeSvenskKrona).Write(&buff, toolEntries())
+	require.NoError(t, err)
+
+	assert.Equal(t, `Date,Sent Amount,Sent Currency,Received Amount,Received Currency,Fee Amount,Fee Currency,Net Worth Amount,Net Worth Currency,Label,Description,TxHash
+2021-01-01 10:00:00 UTC,,,500,EUR,,,,,,krk RECEIVE 1,
+2021-01-01 11:00:00 UTC,200,EUR,2,LTC,1,EUR,2010,SEK,,krk BUY 2,
+2021-01-01 12:00:00 UTC,1,LTC,0.01,BTC,0.1,BNB,,,,krk SELL 3,
+2021-01-01 13:00:00 UTC,0.5,LTC,,,0.01,LTC,,,,krk TRANSFER 4,
+`, buff.String())
+
+	require.Equal(t, 1, len(unsupported))
+	assert.Equal(t, "5", unsupported[0].ID)
+	assert.Equal(t, "side BUYSELL is not supported", unsupported[0].Reason)
+
+	buff.Reset()
+	require.NoError(t, WriteUnsupported(&buff, unsupported))
+
+	assert.Equal(t, "id,exchange,side,created,reason\n5,krk,BUYSELL,2021-01-01T14:00:00Z,side BUYSELL is not supported\n", buff.String())
+
+}
+
+func TestKoinlyDatesAreUTC(t *testing.T) {
+
+	stockholm, err := time.LoadLocation("Europe/Stockholm")
+	require.NoError(t, err)
+
+	w, err := NewWriter(FormatKoinly)
+	require.NoError(t, err)
+
+	var buff bytes.Buffer
+
+	_, err = w.UseLocation(stockholm).Write(&buff, toolEntries()[:1])
+	require.NoError(t, err)
+
+	assert.Contains(t, buff.String(), "\n2021-01-01 10:00:00 UTC,")
+
+}
+
+func TestReceiveAndTransferOfPricedAsset(t *testing.T) {
+
+	ethEur := common.AssetPair{Asset: common.AssetTypeETH, CostUnit: common.AssetTypeEuro}
+
+	// A reward priced in the cost unit with price 1 is still the asset
+	ev, reason := toEvent(&common.TransactionLog{
+		Side: common.SideTypeReceive, AssetSize: 2, PricePerUnit: 1, TotalPrice: 2, AssetPair: ethEur,
+	})
+
+	require.Equal(t, "", reason)
+	assert.Equal(t, eventDeposit, ev.kind)
+	assert.Equal(t, 2.0, ev.received)
+	assert.Equal(t, common.AssetTypeETH, ev.receivedAsset)
+
+	ev, reason = toEvent(&common.TransactionLog{
+		Side: common.SideTypeTransfer, AssetSize: 0.5, PricePerUnit: 1500, Fee: 2, TotalPrice: -752,
+		AssetPair: ethEur,
+	})
+
+	require.Equal(t, "", reason)
+	assert.Equal(t, eventWithdrawal, ev.kind)
+	assert.Equal(t, 0.5, ev.sent)
+	assert.Equal(t, common.AssetTypeETH, ev.sentAsset)
+	assert.Equal(t, 2.0, ev.fee)
+	assert.Equal(t, common.AssetTypeEuro, ev.feeAsset)
+
+	// The size is derived from the total when missing
+	ev, reason = toEvent(&common.TransactionLog{
+		Side: common.SideTypeReceive, PricePerUnit: 1500, Fee: 3, TotalPrice: 1497, AssetPair: ethEur,
+	})
+
+	require.Equal(t, "", reason)
+	assert.Equal(t, 1.0, ev.received)
+
+}
+
+func TestCoinTracking(t *testing.T) {
+
+	w, err := NewWriter(FormatCoinTracking)
+	require.NoError(t, err)
+
+	var buff bytes.Buffer
+
+	unsupported, err := w.Write(&buff, toolEntries())
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(unsupported))
+
+	assert.Equal(t, `Type,Buy Amount,Buy Currency,Sell Amount,Sell Currency,Fee,Fee Currency,Exchange,Trade-Group,Comment,Date
+Deposit,500,EUR,,,,,krk,,1,2021-01-01 10:00:00
+Trade,2,LTC,200,EUR,1,EUR,krk,,2,2021-01-01 11:00:00
+Trade,0.01,BTC,1,LTC,0.1,BNB,krk,,3,2021-01-01 12:00:00
+Withdrawal,,,0.5,LTC,0.01,LTC,krk,,4,2021-01-01 13:00:00
+`, buff.String())
+
+}
+
+func TestUnsupportedEvents(t *testing.T) {
+
+	_, reason := toEvent(&common.TransactionLog{
+		Side:      common.SideTypeBuy,
+		AssetPair: common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeBTC},
+	})
+
+	assert.Equal(t, "trade of BTC to itself", reason)
+
+	_, reason = toEvent(&common.TransactionLog{Side: common.SideTypeReceive})
+	assert.Equal(t, "missing asset or cost unit", reason)
+
+	_, reason = toEvent(&common.TransactionLog{
+		Side:      common.SideTypeReceive,
+		AssetPair: common.AssetPair{Asset: common.AssetTypeETH, CostUnit: common.AssetTypeEuro},
+	})
+
+	assert.Equal(t, "size of RECEIVE ETH is unknown", reason)
+
+	_, err := NewWriter("unknown")
+	assert.EqualError(t, err, "unknown format: unknown")
+
+}
@@ -0,0 +1,384 @@
+package xlsx
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+)
+
+// Report renders the processor results as a workbook with the sheets:
+//
+// _Transactions_ from `UseTransactions`, one _Account <exchange>_ sheet per exchange
+// from `UseAccounts`, one _Gains <year>_ sheet per tax year (the year of the sell) from
+// `UseGains` and _Open Lots_ from `UseOpenLots`. Sheets without data are omitted.
+//
+// The translated columns are rendered for each asset passed to `NewReport`. The gains
+// and the translated totals have a bold summary row with `SUM` formulas.
+type Report struct {
+	assets       []common.AssetType
+	transactions []common.TransactionEntry
+	accounts     map[string][]common.TransactionEntry
+	gains        []common.TxBuySellEntry
+	lots         []common.InventoryLot
+}
+
+// NewReport creates a new report with translated columns for the _assets_.
+func NewReport(assets ...common.AssetType) *Report {
+
+	return &Report{
+		assets:       assets,
+		transactions: []common.TransactionEntry{},
+		accounts:     map[string][]common.TransactionEntry{},
+		gains:        []common.TxBuySellEntry{},
+		lots:         []common.InventoryLot{},
+	}
+
+}
+
+// UseTransactions renders the _tx_ in the transactions sheet.
+func (r *Report) UseTransactions(tx ...common.TransactionEntry) *Report {
+
+	r.transactions = append(r.transactions, tx...)
+	return r
+
+}
+
+// UseAccounts renders the _accounts_, as returned by `MultiExchangeAccountingProcessor.Flush`,
+// where each entry is expected to be a `common.AccountEntry`.
+func (r *Report) UseAccounts(accounts map[string][]common.TransactionEntry) *Report {
+
+	for exchange, entries := range accounts {
+		r.accounts[exchange] = append(r.accounts[exchange], entries...)
+	}
+
+	return r
+}
+
+// UseGains renders the realized gains, as returned by `TxBuySellProcessor.Flush`.
+func (r *Report) UseGains(entries ...common.TxBuySellEntry) *Report {
+
+	r.gains = append(r.gains, entries...)
+	return r
+
+}
+
+// UseOpenLots renders the open lots, e.g. from `TxBuySellProcessor.ExportLots`.
+func (r *Report) UseOpenLots(lots ...common.InventoryLot) *Report {
+
+	r.lots = append(r.lots, lots...)
+	return r
+
+}
+
+// Write renders the workbook onto _w_.
+func (r *Report) Write(w io.Writer) error {
+	return r.Workbook().Write(w)
+}
+
+// Workbook creates the workbook.
+func (r *Report) Workbook() *Workbook {
+
+	wb := NewWorkbook()
+
+	if len(r.transactions) > 0 {
+		r.transactionSheet(wb.AddSheet("Transactions"))
+	}
+
+	for _, exchange := range r.exchanges() {
+		r.accountSheet(wb.AddSheet("Account "+exchange), r.accounts[exchange])
+	}
+
+	years := map[int][]common.TxBuySellEntry{}
+	for _, entry := range r.gains {
+
+		year := entry.GetSell().GetCreatedAt().UTC().Year()
+		years[year] = append(years[year], entry)
+
+	}
+
+	for _, year := range sortedYears(years) {
+		r.gainSheet(wb.AddSheet(fmt.Sprintf("Gains %d", year)), years[year])
+	}
+
+	if len(r.lots) > 0 {
+		r.lotSheet(wb.AddSheet("Open Lots"))
+	}
+
+	return wb
+}
+
+func (r *Report) transactionSheet(sheet *Sheet) {
+
+	header := []string{
+		"Date", "Exchange", "ID", "Side", "Side ID", "Pair", "Size", "Price", "Fee", "Fee Asset", "Total",
+	}
+
+	for _, asset := range r.assets {
+		header = append(header, "Total "+string(asset), "Fee "+string(asset))
+	}
+
+	sheet.AddHeader(header...)
+
+	for _, tx := range r.transactions {
+
+		pair := tx.GetAssetPair()
+		feeAsset := tx.GetFeeAsset()
+
+		row := []Cell{
+			Date(tx.GetCreatedAt()),
+			String(tx.GetExchange()),
+			String(tx.GetID()),
+			String(string(tx.GetSide())),
+			String(tx.GetSideIdentifier()),
+			String(pair.String()),
+			Number(tx.GetAssetSize(), CurrencyFormat(pair.Asset)),
+			Number(tx.GetPricePerUnit(), CurrencyFormat(pair.CostUnit)),
+			Number(tx.GetFee(), CurrencyFormat(feeAsset)),
+			String(string(feeAsset)),
+			Number(tx.GetTotalPrice(), CurrencyFormat(pair.CostUnit)),
+		}
+
+		for _, asset := range r.assets {
+
+			row = append(
+				row,
+				Number(tx.GetTranslatedTotalPrice(asset), CurrencyFormat(asset)),
+				Number(tx.GetTranslatedFee(asset), CurrencyFormat(asset)),
+			)
+
+		}
+
+		sheet.AddRow(row...)
+
+	}
+
+	r.addSummary(sheet, 11, 2)
+}
+
+func (r *Report) accountSheet(sheet *Sheet, entries []common.TransactionEntry) {
+
+	seen := map[common.AssetType]bool{}
+	assets := []common.AssetType{}
+
+	for _, entry := range entries {
+
+		if acc, ok := entry.(common.AccountEntry); ok {
+
+			for asset := range acc.GetAccountStatus() {
+
+				if !seen[asset] {
+
+					seen[asset] = true
+					assets = append(assets, asset)
+
+				}
+
+			}
+
+		}
+
+	}
+
+	sort.Slice(assets, func(i, j int) bool {
+		return assets[i] < assets[j]
+	})
+
+	header := []string{"Date", "ID", "Side", "Pair", "Size", "Price", "Fee", "Total"}
+	for _, asset := range assets {
+		header = append(header, "Account "+string(asset))
+	}
+
+	sheet.AddHeader(header...)
+
+	for _, entry := range entries {
+
+		pair := entry.GetAssetPair()
+
+		row := []Cell{
+			Date(entry.GetCreatedAt()),
+			String(entry.GetID()),
+			String(string(entry.GetSide())),
+			String(pair.String()),
+			Number(entry.GetAssetSize(), CurrencyFormat(pair.Asset)),
+			Number(entry.GetPricePerUnit(), CurrencyFormat(pair.CostUnit)),
+			Number(entry.GetFee(), CurrencyFormat(entry.GetFeeAsset())),
+			Number(entry.GetTotalPrice(), CurrencyFormat(pair.CostUnit)),
+		}
+
+		var status common.AccountStatus
+		if acc, ok := entry.(common.AccountEntry); ok {
+			status = acc.GetAccountStatus()
+		}
+
+		for _, asset := range assets {
+			row = append(row, Number(status[asset], CurrencyFormat(asset)))
+		}
+
+		sheet.AddRow(row...)
+
+	}
+
+}
+
+func (r *Report) gainSheet(sheet *Sheet, entries []common.TxBuySellEntry) {
+
+	header := []string{"Bought", "Sold", "Exchange", "Pair", "Size"}
+	for _, asset := range r.assets {
+
+		header = append(
+			header, "Cost "+string(asset), "Proceeds "+string(asset), "Gain "+string(asset),
+		)
+
+	}
+
+	sheet.AddHeader(header...)
+
+	for _, entry := range entries {
+
+		buy := entry.GetBuy()
+		sell := entry.GetSell()
+		pair := entry.GetAssetPair()
+		row := sheet.RowCount() + 1
+
+		cells := []Cell{
+			Date(buy.GetCreatedAt()),
+			Date(sell.GetCreatedAt()),
+			String(entry.GetExchange()),
+			String(pair.String()),
+			Number(entry.GetAssetSize(), CurrencyFormat(pair.Asset)),
+		}
+
+		for _, asset := range r.assets {
+
+			format := CurrencyFormat(asset)
+			cost := -buy.GetTranslatedTotalPrice(asset)
+			proceeds := sell.GetTranslatedTotalPrice(asset)
+
+			col := len(cells)
+
+			cells = append(
+				cells,
+				Number(cost, format),
+				Number(proceeds, format),
+				Formula(
+					fmt.Sprintf("%s-%s", CellRef(col+1, row), CellRef(col, row)),
+					proceeds-cost,
+					format,
+				),
+			)
+
+		}
+
+		sheet.AddRow(cells...)
+
+	}
+
+	r.addSummary(sheet, 5, 3)
+}
+
+func (r *Report) lotSheet(sheet *Sheet) {
+
+	header := []string{"Queue", "Date", "Exchange", "ID", "Side", "Pair", "Size", "Total"}
+	for _, asset := range r.assets {
+		header = append(header, "Total "+string(asset))
+	}
+
+	sheet.AddHeader(header...)
+
+	for _, lot := range r.lots {
+
+		tx := lot.Tx
+
+		row := []Cell{
+			String(string(lot.Queue)),
+			Date(tx.CreatedAt),
+			String(tx.Exchange),
+			String(tx.ID),
+			String(string(tx.Side)),
+			String(tx.AssetPair.String()),
+			Number(tx.AssetSize, CurrencyFormat(tx.Asset)),
+			Number(tx.TotalPrice, CurrencyFormat(tx.CostUnit)),
+		}
+
+		for _, asset := range r.assets {
+			row = append(row, Number(tx.GetTranslatedTotalPrice(asset), CurrencyFormat(asset)))
+		}
+
+		sheet.AddRow(row...)
+
+	}
+
+	r.addSummary(sheet, 8, 1)
+}
+
+// addSummary adds a bold row with `SUM` formulas for the translated columns, starting
+// at the zero based _first_ column and having _perAsset_ columns for each asset.
+func (r *Report) addSummary(sheet *Sheet, first, perAsset int) {
+
+	if len(r.assets) == 0 || sheet.RowCount() < 2 {
+		return
+	}
+
+	last := sheet.RowCount()
+	row := make([]Cell, first, first+len(r.assets)*perAsset)
+
+	for i := range row {
+		row[i] = String("")
+	}
+
+	row[0] = String("Total").Bold()
+
+	for i, asset := range r.assets {
+
+		for j := 0; j < perAsset; j++ {
+
+			col := first + i*perAsset + j
+			sum := 0.0
+
+			for _, cells := range sheet.rows[1:] {
+				sum += cells[col].value
+			}
+
+			expr := fmt.Sprintf("SUM(%s:%s)", CellRef(col, 2), CellRef(col, last))
+			row = append(row, Formula(expr, sum, CurrencyFormat(asset)).Bold())
+
+		}
+
+	}
+
+	sheet.AddRow(row...)
+}
+
+func (r *Report) exchanges() []string {
+
+	list := make([]string, 0, len(r.accounts))
+	for exchange := range r.accounts {
+		list = append(list, exchange)
+	}
+
+	// The all (summary) account is rendered last
+	sort.Slice(list, func(i, j int) bool {
+
+		if list[i] == common.ExchangeAll || list[j] == common.ExchangeAll {
+			return list[j] == common.ExchangeAll && list[i] != common.ExchangeAll
+		}
+
+		return strings.ToLower(list[i]) < strings.ToLower(list[j])
+	})
+
+	return list
+}
+
+func sortedYears(years map[int][]common.TxBuySellEntry) []int {
+
+	list := make([]int, 0, len(years))
+	for year := range years {
+		list = append(list, year)
+	}
+
+	sort.Ints(list)
+	return list
+}
@@ -0,0 +1,132 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/processors"
+	"github.com/mariotoffia/gocryptoadmin/txlog/testfiles/fixtures"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readZip(t *testing.T, data []byte) map[string]string {
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	files := map[string]string{}
+
+	for _, f := range zr.File {
+
+		rc, err := f.Open()
+		require.NoError(t, err)
+
+		content, err := ioutil.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+
+		files[f.Name] = string(content)
+
+	}
+
+	return files
+}
+
+func TestReportRendersAllSheets(t *testing.T) {
+
+	entries := fixtures.Entries(fixtures.CoinbasePro(common.AssetTypeSvenskKrona))
+
+	acc := processors.NewMultiExchangeAccountingProcessor()
+	acc.ProcessMany(entries)
+
+	bs := processors.NewTxBuySellProcessor()
+	bs.ProcessMany(entries)
+
+	open := bs.ExportLots()
+	pairs, _ := bs.Flush()
+
+	var buff bytes.Buffer
+
+	err := NewReport(common.AssetTypeSvenskKrona).
+		UseTransactions(entries...).
+		UseAccounts(acc.Flush()).
+		UseGains(pairs...).
+		UseOpenLots(open...).
+		Write(&buff)
+
+	require.NoError(t, err)
+
+	files := readZip(t, buff.Bytes())
+
+	for _, name := range []string{
+		"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels",
+		"xl/styles.xml", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet5.xml",
+	} {
+		assert.Contains(t, files, name)
+	}
+
+	wb := files["xl/workbook.xml"]
+	assert.Contains(t, wb, `<sheet name="Transactions" sheetId="1" r:id="rId1"/>`)
+	assert.Contains(t, wb, `<sheet name="Account cbx" sheetId="2" r:id="rId2"/>`)
+	assert.Contains(t, wb, `<sheet name="Account all" sheetId="3" r:id="rId3"/>`)
+	assert.Contains(t, wb, `<sheet name="Gains 2019" sheetId="4" r:id="rId4"/>`)
+	assert.Contains(t, wb, `<sheet name="Open Lots" sheetId="5" r:id="rId5"/>`)
+
+	styles := files["xl/styles.xml"]
+	assert.Contains(t, styles, `formatCode="#,##0.00 &#34;SEK&#34;"`)
+	assert.Contains(t, styles, `formatCode="#,##0.00000000 &#34;XLM&#34;"`)
+
+	tx := files["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, tx, `<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`)
+	assert.Contains(t, tx, `<v>-201.337884495</v>`)
+	assert.Contains(t, tx, `<f>SUM(L2:L4)</f><v>-1365.97640745</v>`)
+	assert.Contains(t, tx, `<f>SUM(M2:M4)</f><v>3.98810745</v>`)
+
+	// Account balance after the sells
+	account := files["xl/worksheets/sheet2.xml"]
+	assert.Contains(t, account, `<t xml:space="preserve">Account XLM</t>`)
+	assert.Contains(t, account, `<v>1212</v>`)
+
+	// The 131 XLM sold cost 148.00933148 SEK of the buy and yielded 148.78898125 SEK
+	gains := files["xl/worksheets/sheet4.xml"]
+	assert.Contains(t, gains, `<c r="F2" s="5"><v>148.00933148</v></c><c r="G2" s="5"><v>148.78898125</v></c>`)
+	assert.Contains(t, gains, `<f>SUM(G2:G3)</f><v>647.4024375</v>`)
+
+	// The remaining 1212 XLM of the buy
+	lots := files["xl/worksheets/sheet5.xml"]
+	assert.Contains(t, lots, `<v>1212</v>`)
+	assert.Contains(t, lots, `<f>SUM(I2:I2)</f><v>-1369.3687767</v>`)
+
+}
+
+func TestSheetNamesAreSanitizedAndUnique(t *testing.T) {
+
+	wb := NewWorkbook()
+
+	assert.Equal(t, "Account a-b", wb.AddSheet("Account a/b").Name())
+	assert.Equal(t, "Account a-b (2)", wb.AddSheet("Account a:b").Name())
+	assert.Equal(t, 31, len(wb.AddSheet("Account with a very long exchange name").Name()))
+
+}
+
+func TestCellRef(t *testing.T) {
+
+	assert.Equal(t, "A1", CellRef(0, 1))
+	assert.Equal(t, "Z10", CellRef(25, 10))
+	assert.Equal(t, "AA2", CellRef(26, 2))
+	assert.Equal(t, "AZ3", CellRef(51, 3))
+	assert.Equal(t, "BA4", CellRef(52, 4))
+
+}
+
+func TestDateIsSerial(t *testing.T) {
+
+	at, _ := time.Parse(time.RFC3339, "2021-01-01T12:00:00Z")
+	assert.Equal(t, 44197.5, Date(at).value)
+
+}
@@ -0,0 +1,616 @@
+// Package xlsx writes _Office Open XML_ workbooks (_.xlsx_) with numeric cells,
+// number formats, frozen headers and formulas. It only depends on the standard
+// library, i.e. the workbook is rendered directly as _XML_ in a _zip_ archive.
+//
+// The `Report` renders the processor results as a workbook.
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+)
+
+// Built-in number formats.
+const (
+	FormatNone     = ""
+	FormatDateTime = "yyyy-mm-dd hh:mm:ss"
+	FormatDate     = "yyyy-mm-dd"
+)
+
+// firstCustomFormatID is the first id that is not reserved for built-in formats.
+const firstCustomFormatID = 164
+
+// maxSheetName is the maximum length of a sheet name.
+const maxSheetName = 31
+
+// CurrencyFormat returns the number format for amounts in _asset_. _FIAT_ is rendered
+// with two and crypto with eight decimals, e.g. _1,234.50 "EUR"_.
+func CurrencyFormat(asset common.AssetType) string {
+
+	if asset == "" {
+		return "#,##0.00########"
+	}
+
+	if asset.IsFIAT() {
+		return fmt.Sprintf(`#,##0.00 "%s"`, asset)
+	}
+
+	return fmt.Sprintf(`#,##0.00000000 "%s"`, asset)
+}
+
+type cellKind int
+
+const (
+	cellString cellKind = iota
+	cellNumber
+	cellFormula
+)
+
+// Cell is a single cell in a `Sheet`.
+type Cell struct {
+	kind   cellKind
+	text   string
+	value  float64
+	format string
+	bold   bool
+}
+
+// String creates a text cell.
+func String(s string) Cell {
+	return Cell{kind: cellString, text: s}
+}
+
+// Number creates a numeric cell rendered with the number _format_.
+func Number(v float64, format string) Cell {
+	return Cell{kind: cellNumber, value: v, format: format}
+}
+
+// Date creates a numeric date cell, the time is rendered in _UTC_.
+func Date(t time.Time) Cell {
+
+	if t.IsZero() {
+		return String("")
+	}
+
+	return Cell{kind: cellNumber, value: excelTime(t), format: FormatDateTime}
+}
+
+// Formula creates a cell with the formula _expr_ (without the leading equal sign).
+// The _value_ is the cached result that is shown until the workbook is recalculated.
+func Formula(expr string, value float64, format string) Cell {
+	return Cell{kind: cellFormula, text: expr, value: value, format: format}
+}
+
+// Bold renders the cell using a bold font.
+func (c Cell) Bold() Cell {
+
+	c.bold = true
+	return c
+
+}
+
+// Sheet is a single worksheet.
+type Sheet struct {
+	name   string
+	frozen int
+	rows   [][]Cell
+}
+
+// Name returns the sanitized sheet name.
+func (s *Sheet) Name() string {
+	return s.name
+}
+
+// AddHeader adds a bold row and freezes all rows up to, and including, it.
+func (s *Sheet) AddHeader(columns ...string) *Sheet {
+
+	row := make([]Cell, len(columns))
+	for i := range columns {
+		row[i] = String(columns[i]).Bold()
+	}
+
+	s.rows = append(s.rows, row)
+	s.frozen = len(s.rows)
+
+	return s
+}
+
+// AddRow adds a row of cells.
+func (s *Sheet) AddRow(cells ...Cell) *Sheet {
+
+	s.rows = append(s.rows, cells)
+	return s
+
+}
+
+// RowCount returns the number of rows, hence the next row is `RowCount` + 1.
+func (s *Sheet) RowCount() int {
+	return len(s.rows)
+}
+
+// Workbook is a set of sheets that is written using `Write`.
+type Workbook struct {
+	sheets []*Sheet
+}
+
+// NewWorkbook creates a empty workbook.
+func NewWorkbook() *Workbook {
+	return &Workbook{sheets: []*Sheet{}}
+}
+
+// AddSheet adds a new sheet. The _name_ is sanitized and made unique.
+func (wb *Workbook) AddSheet(name string) *Sheet {
+
+	sheet := &Sheet{name: wb.uniqueName(name), rows: [][]Cell{}}
+	wb.sheets = append(wb.sheets, sheet)
+
+	return sheet
+}
+
+// GetSheets returns all sheets in order.
+func (wb *Workbook) GetSheets() []*Sheet {
+	return wb.sheets
+}
+
+// Write renders the workbook onto _w_.
+func (wb *Workbook) Write(w io.Writer) error {
+
+	if len(wb.sheets) == 0 {
+		wb.AddSheet("Sheet1")
+	}
+
+	st := newStyles()
+
+	sheets := make([][]byte, len(wb.sheets))
+	for i, sheet := range wb.sheets {
+		sheets[i] = sheet.render(st)
+	}
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"[Content_Types].xml", wb.contentTypes()},
+		{"_rels/.rels", []byte(rootRels)},
+		{"xl/workbook.xml", wb.workbook()},
+		{"xl/_rels/workbook.xml.rels", wb.workbookRels()},
+		{"xl/styles.xml", st.render()},
+	}
+
+	for i := range sheets {
+
+		files = append(files, struct {
+			name string
+			data []byte
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sheets[i]})
+
+	}
+
+	zw := zip.NewWriter(w)
+
+	for _, file := range files {
+
+		fw, err := zw.Create(file.name)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fw.Write(file.data); err != nil {
+			return err
+		}
+
+	}
+
+	return zw.Close()
+}
+
+// CellRef returns the _A1_ reference of the zero based _col_ and one based _row_.
+func CellRef(col, row int) string {
+	return ColumnName(col) + strconv.Itoa(row)
+}
+
+// ColumnName returns the column name of the zero based _col_, e.g. _AA_ for 26.
+func ColumnName(col int) string {
+
+	name := ""
+
+	for col >= 0 {
+
+		name = string(rune('A'+col%26)) + name
+		col = col/26 - 1
+
+	}
+
+	return name
+}
+
+func (wb *Workbook) uniqueName(name string) string {
+
+	name = strings.Map(func(r rune) rune {
+
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '-'
+		}
+
+		return r
+
+	}, name)
+
+	if name == "" {
+		name = "Sheet"
+	}
+
+	if len(name) > maxSheetName {
+		name = name[:maxSheetName]
+	}
+
+	candidate := name
+
+	for i := 2; wb.hasSheet(candidate); i++ {
+
+		suffix := fmt.Sprintf(" (%d)", i)
+
+		if len(name)+len(suffix) > maxSheetName {
+			candidate = name[:maxSheetName-len(suffix)] + suffix
+		} else {
+			candidate = name + suffix
+		}
+
+	}
+
+	return candidate
+}
+
+func (wb *Workbook) hasSheet(name string) bool {
+
+	for _, sheet := range wb.sheets {
+
+		if strings.EqualFold(sheet.name, name) {
+			return true
+		}
+
+	}
+
+	return false
+}
+
+func (s *Sheet) render(st *styles) []byte {
+
+	var b bytes.Buffer
+
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="` + nsMain + `" xmlns:r="` + nsRelationships + `">`)
+
+	if s.frozen > 0 {
+
+		fmt.Fprintf(
+			&b,
+			`<sheetViews><sheetView workbookViewId="0"><pane ySplit="%d" topLeftCell="%s" `+
+				`activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`,
+			s.frozen, CellRef(0, s.frozen+1),
+		)
+
+	}
+
+	if widths := s.widths(); len(widths) > 0 {
+
+		b.WriteString("<cols>")
+
+		for i, width := range widths {
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, width)
+		}
+
+		b.WriteString("</cols>")
+
+	}
+
+	b.WriteString("<sheetData>")
+
+	for r, row := range s.rows {
+
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+
+		for c, cell := range row {
+
+			ref := CellRef(c, r+1)
+			style := st.index(cell.format, cell.bold)
+
+			switch cell.kind {
+			case cellString:
+
+				if cell.text == "" {
+					continue
+				}
+
+				fmt.Fprintf(&b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">`, ref, style)
+				xml.EscapeText(&b, []byte(cell.text))
+				b.WriteString("</t></is></c>")
+
+			case cellNumber:
+
+				fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, formatFloat(cell.value))
+
+			case cellFormula:
+
+				fmt.Fprintf(&b, `<c r="%s" s="%d"><f>`, ref, style)
+				xml.EscapeText(&b, []byte(cell.text))
+				fmt.Fprintf(&b, `</f><v>%s</v></c>`, formatFloat(cell.value))
+
+			}
+
+		}
+
+		b.WriteString("</row>")
+
+	}
+
+	b.WriteString("</sheetData></worksheet>")
+
+	return b.Bytes()
+}
+
+// widths approximates the column widths from the text and formats.
+func (s *Sheet) widths() []int {
+
+	widths := []int{}
+
+	for _, row := range s.rows {
+
+		for c, cell := range row {
+
+			if c >= len(widths) {
+				widths = append(widths, 8)
+			}
+
+			n := len(cell.text)
+			if cell.kind != cellString {
+				n = len(formatFloat(cell.value)) + len(cell.format)/2
+			}
+
+			if cell.format == FormatDateTime {
+				n = len(FormatDateTime)
+			}
+
+			if n+2 > widths[c] {
+				widths[c] = n + 2
+			}
+
+		}
+
+	}
+
+	for i := range widths {
+
+		if widths[i] > 50 {
+			widths[i] = 50
+		}
+
+	}
+
+	return widths
+}
+
+func (wb *Workbook) contentTypes() []byte {
+
+	var b bytes.Buffer
+
+	b.WriteString(xml.Header)
+	b.WriteString(
+		`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`,
+	)
+
+	for i := range wb.sheets {
+
+		fmt.Fprintf(
+			&b,
+			`<Override PartName="/xl/worksheets/sheet%d.xml" `+
+				`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`,
+			i+1,
+		)
+
+	}
+
+	b.WriteString("</Types>")
+	return b.Bytes()
+}
+
+func (wb *Workbook) workbook() []byte {
+
+	var b bytes.Buffer
+
+	b.WriteString(xml.Header)
+	b.WriteString(`<workbook xmlns="` + nsMain + `" xmlns:r="` + nsRelationships + `"><sheets>`)
+
+	for i, sheet := range wb.sheets {
+
+		b.WriteString(`<sheet name="`)
+		xml.EscapeText(&b, []byte(sheet.name))
+		fmt.Fprintf(&b, `" sheetId="%d" r:id="rId%d"/>`, i+1, i+1)
+
+	}
+
+	b.WriteString("</sheets></workbook>")
+	return b.Bytes()
+}
+
+func (wb *Workbook) workbookRels() []byte {
+
+	var b bytes.Buffer
+
+	b.WriteString(xml.Header)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+
+	for i := range wb.sheets {
+
+		fmt.Fprintf(
+			&b,
+			`<Relationship Id="rId%d" Type="%s/worksheet" Target="worksheets/sheet%d.xml"/>`,
+			i+1, nsRelationships, i+1,
+		)
+
+	}
+
+	fmt.Fprintf(
+		&b,
+		`<Relationship Id="rId%d" Type="%s/styles" Target="styles.xml"/>`,
+		len(wb.sheets)+1, nsRelationships,
+	)
+
+	b.WriteString("</Relationships>")
+	return b.Bytes()
+}
+
+const (
+	nsMain          = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	nsRelationships = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+)
+
+const rootRels = xml.Header +
+	`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="` + nsRelationships + `/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// styles keeps track of the cell formats (number format and font) in use.
+type styles struct {
+	formats []string
+	xfs     []styleKey
+}
+
+type styleKey struct {
+	format string
+	bold   bool
+}
+
+func newStyles() *styles {
+
+	return &styles{
+		formats: []string{},
+		xfs:     []styleKey{{}},
+	}
+
+}
+
+// index returns the cell format index for the number _format_ and font.
+func (st *styles) index(format string, bold bool) int {
+
+	key := styleKey{format: format, bold: bold}
+
+	for i := range st.xfs {
+
+		if st.xfs[i] == key {
+			return i
+		}
+
+	}
+
+	if format != FormatNone && st.formatID(format) == 0 {
+		st.formats = append(st.formats, format)
+	}
+
+	st.xfs = append(st.xfs, key)
+	return len(st.xfs) - 1
+}
+
+func (st *styles) formatID(format string) int {
+
+	for i := range st.formats {
+
+		if st.formats[i] == format {
+			return firstCustomFormatID + i
+		}
+
+	}
+
+	return 0
+}
+
+func (st *styles) render() []byte {
+
+	var b bytes.Buffer
+
+	b.WriteString(xml.Header)
+	b.WriteString(`<styleSheet xmlns="` + nsMain + `">`)
+
+	if len(st.formats) > 0 {
+
+		fmt.Fprintf(&b, `<numFmts count="%d">`, len(st.formats))
+
+		for i, format := range st.formats {
+
+			fmt.Fprintf(&b, `<numFmt numFmtId="%d" formatCode="`, firstCustomFormatID+i)
+			xml.EscapeText(&b, []byte(format))
+			b.WriteString(`"/>`)
+
+		}
+
+		b.WriteString("</numFmts>")
+
+	}
+
+	b.WriteString(
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font>` +
+			`<font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+			`<fills count="2"><fill><patternFill patternType="none"/></fill>` +
+			`<fill><patternFill patternType="gray125"/></fill></fills>` +
+			`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+			`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>`,
+	)
+
+	fmt.Fprintf(&b, `<cellXfs count="%d">`, len(st.xfs))
+
+	for _, key := range st.xfs {
+
+		fontID := 0
+		if key.bold {
+			fontID = 1
+		}
+
+		fmt.Fprintf(
+			&b,
+			`<xf numFmtId="%d" fontId="%d" fillId="0" borderId="0" xfId="0"`,
+			st.formatID(key.format), fontID,
+		)
+
+		if key.format != FormatNone {
+			b.WriteString(` applyNumberFormat="1"`)
+		}
+
+		if key.bold {
+			b.WriteString(` applyFont="1"`)
+		}
+
+		b.WriteString("/>")
+
+	}
+
+	b.WriteString(
+		`</cellXfs><cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+			`</styleSheet>`,
+	)
+
+	return b.Bytes()
+}
+
+// excelTime converts _t_ to the serial date, i.e. days since 1899-12-30.
+func excelTime(t time.Time) float64 {
+
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	return t.UTC().Sub(epoch).Hours() / 24
+
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
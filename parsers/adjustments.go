@@ -0,0 +1,62 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+)
+
+// ParseAdjustments parses a _JSON_ adjustment file, i.e. a array of `common.Adjustment`.
+//
+// .Example
+// ====
+// [{"action": "delete", "exchange": "cbx", "id": "123", "reason": "duplicate of 122"},
+// {"action": "patch", "exchange": "kr", "id": "T-1", "reason": "wrong fee", "patch": {"fee": 0.5}},
+// {"action": "add", "exchange": "ledger", "id": "L-1", "reason": "not exported",
+// "tx": {"side": "RECEIVE", "created": "2021-01-02T10:00:00Z", "asset": "BTC", "costunit": "BTC", "size": 0.1, "price": 1}}]
+// ====
+//
+// A added transaction gets the exchange and id from the adjustment when not set in _tx_.
+// All adjustments are validated and the first error is returned.
+func ParseAdjustments(data []byte) ([]common.Adjustment, error) {
+
+	adjustments := []common.Adjustment{}
+
+	if err := json.Unmarshal(data, &adjustments); err != nil {
+		return nil, err
+	}
+
+	for i := range adjustments {
+
+		adj := &adjustments[i]
+
+		if err := adj.Validate(); err != nil {
+			return nil, fmt.Errorf("[index: %d] %s", i, err.Error())
+		}
+
+		if adj.Tx == nil {
+			continue
+		}
+
+		if adj.Tx.Exchange == "" {
+			adj.Tx.Exchange = adj.Exchange
+		}
+
+		if adj.Tx.ID == "" {
+			adj.Tx.ID = adj.ID
+		}
+
+		if adj.Tx.Exchange != adj.Exchange || adj.Tx.ID != adj.ID {
+
+			return nil, fmt.Errorf(
+				"[index: %d] tx %s/%s do not match adjustment %s/%s",
+				i, adj.Tx.Exchange, adj.Tx.ID, adj.Exchange, adj.ID,
+			)
+
+		}
+
+	}
+
+	return adjustments, nil
+}
@@ -0,0 +1,49 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAdjustments(t *testing.T) {
+
+	adjustments, err := ParseAdjustments([]byte(`[
+		{"action": "delete", "exchange": "cbx", "id": "123", "reason": "duplicate of 122"},
+		{"action": "patch", "exchange": "kr", "id": "T-1", "reason": "wrong fee", "patch": {"fee": 0.5}},
+		{
+			"action": "add", "exchange": "ledger", "id": "L-1", "reason": "not exported",
+			"tx": {"side": "RECEIVE", "created": "2021-01-02T10:00:00Z", "asset": "BTC", "costunit": "BTC", "size": 0.1, "price": 1}
+		}
+	]`))
+
+	require.NoError(t, err)
+	require.Equal(t, 3, len(adjustments))
+
+	assert.Equal(t, common.AdjustmentActionDelete, adjustments[0].Action)
+	assert.Equal(t, "fee=0.500000", adjustments[1].Patch.Fields())
+
+	tx := adjustments[2].Tx
+	require.NotNil(t, tx)
+	assert.Equal(t, "ledger", tx.Exchange)
+	assert.Equal(t, "L-1", tx.ID)
+	assert.Equal(t, common.SideTypeReceive, tx.Side)
+	assert.Equal(t, common.AssetTypeBTC, tx.Asset)
+	assert.Equal(t, 0.1, tx.AssetSize)
+}
+
+func TestParseAdjustmentsRequiresReason(t *testing.T) {
+
+	_, err := ParseAdjustments([]byte(`[{"action": "delete", "exchange": "cbx", "id": "123"}]`))
+	require.Error(t, err)
+	assert.Equal(t, "[index: 0] adjustment delete cbx/123 is missing reason", err.Error())
+
+	_, err = ParseAdjustments([]byte(`[{"action": "patch", "exchange": "cbx", "id": "1", "reason": "x"}]`))
+	require.Error(t, err)
+
+	_, err = ParseAdjustments([]byte(`[{"action": "move", "exchange": "cbx", "id": "1", "reason": "x"}]`))
+	require.Error(t, err)
+	assert.Equal(t, "[index: 0] unknown adjustment action: move", err.Error())
+}
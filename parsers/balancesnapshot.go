@@ -0,0 +1,200 @@
+package parsers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jszwec/csvutil"
+	"github.com/mariotoffia/gocryptoadmin/common"
+)
+
+// BalanceSnapshotRow is a single row in a balance snapshot _CSV_ file.
+//
+// .Example
+// ====
+// exchange,time,asset,balance
+// kr,2021-12-31,BTC,1.2
+// kr,2021-12-31,EUR,100.50
+// ====
+type BalanceSnapshotRow struct {
+	Exchange string  `csv:"exchange" json:"exchange"`
+	Time     string  `csv:"time"     json:"time"`
+	Asset    string  `csv:"asset"    json:"asset"`
+	Balance  float64 `csv:"balance"  json:"balance"`
+}
+
+type balanceSnapshotJSON struct {
+	Exchange string             `json:"exchange"`
+	Time     string             `json:"time"`
+	Balances map[string]float64 `json:"balances"`
+}
+
+// ParseBalanceSnapshots detects the format (_JSON_ or _CSV_) and parses the snapshots.
+//
+// The _JSON_ is a array of objects with _exchange_, _time_ and _balances_, where
+// _balances_ is keyed by asset, or the same rows as in the _CSV_ format (see
+// `BalanceSnapshotRow`).
+//
+// The _time_ is either _RFC3339_ or a date. A date is interpreted as the end of the
+// day _UTC_, i.e. the balance after all transactions that day.
+//
+// Rows with same exchange and time are merged into a single snapshot and the
+// snapshots are sorted by time.
+func ParseBalanceSnapshots(data []byte) ([]common.BalanceSnapshot, error) {
+
+	trimmed := bytes.TrimSpace(data)
+
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		return parseBalanceSnapshotsJSON(trimmed)
+	}
+
+	return parseBalanceSnapshotsCSV(trimmed)
+}
+
+func parseBalanceSnapshotsCSV(data []byte) ([]common.BalanceSnapshot, error) {
+
+	dec, err := csvutil.NewDecoder(csv.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []BalanceSnapshotRow{}
+
+	for {
+
+		row := BalanceSnapshotRow{}
+
+		if err := dec.Decode(&row); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		rows = append(rows, row)
+
+	}
+
+	return mergeBalanceRows(rows)
+}
+
+func parseBalanceSnapshotsJSON(data []byte) ([]common.BalanceSnapshot, error) {
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	rows := []BalanceSnapshotRow{}
+
+	for _, r := range raw {
+
+		var snapshot balanceSnapshotJSON
+		if err := json.Unmarshal(r, &snapshot); err != nil {
+			return nil, err
+		}
+
+		if snapshot.Balances == nil {
+
+			var row BalanceSnapshotRow
+			if err := json.Unmarshal(r, &row); err != nil {
+				return nil, err
+			}
+
+			rows = append(rows, row)
+			continue
+
+		}
+
+		if len(snapshot.Balances) == 0 {
+
+			// Keep the (empty) snapshot, i.e. all balances are zero
+			rows = append(rows, BalanceSnapshotRow{Exchange: snapshot.Exchange, Time: snapshot.Time})
+			continue
+
+		}
+
+		for asset, balance := range snapshot.Balances {
+
+			rows = append(rows, BalanceSnapshotRow{
+				Exchange: snapshot.Exchange,
+				Time:     snapshot.Time,
+				Asset:    asset,
+				Balance:  balance,
+			})
+
+		}
+
+	}
+
+	return mergeBalanceRows(rows)
+}
+
+func mergeBalanceRows(rows []BalanceSnapshotRow) ([]common.BalanceSnapshot, error) {
+
+	snapshots := []common.BalanceSnapshot{}
+	index := map[string]int{}
+
+	for _, row := range rows {
+
+		at, err := parseSnapshotTime(row.Time)
+		if err != nil {
+			return nil, err
+		}
+
+		if row.Exchange == "" {
+			row.Exchange = common.ExchangeAll
+		}
+
+		key := row.Exchange + "/" + at.Format(time.RFC3339Nano)
+
+		i, ok := index[key]
+		if !ok {
+
+			i = len(snapshots)
+			index[key] = i
+
+			snapshots = append(snapshots, common.BalanceSnapshot{
+				Exchange: row.Exchange,
+				At:       at,
+				Balances: map[common.AssetType]float64{},
+			})
+
+		}
+
+		if row.Asset == "" {
+			continue
+		}
+
+		asset := common.AssetType(strings.TrimSpace(row.Asset)).Normalize()
+		snapshots[i].Balances[asset] += row.Balance
+
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].At.Before(snapshots[j].At)
+	})
+
+	return snapshots, nil
+}
+
+func parseSnapshotTime(s string) (time.Time, error) {
+
+	s = strings.TrimSpace(s)
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	day, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid snapshot time: %s", s)
+	}
+
+	return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
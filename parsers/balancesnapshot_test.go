@@ -0,0 +1,47 @@
+package parsers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBalanceSnapshotsCSV(t *testing.T) {
+
+	snapshots, err := ParseBalanceSnapshots([]byte(
+		"exchange,time,asset,balance\n" +
+			"kr,2021-12-31,XXBT,1.2\n" +
+			"kr,2021-12-31,EUR,100.50\n" +
+			"cb,2021-06-30T12:00:00Z,LTC,3\n",
+	))
+
+	require.NoError(t, err)
+	require.Equal(t, 2, len(snapshots))
+
+	assert.Equal(t, "cb", snapshots[0].Exchange)
+	assert.Equal(t, float64(3), snapshots[0].Balances[common.AssetTypeLTC])
+
+	assert.Equal(t, "kr", snapshots[1].Exchange)
+	assert.Equal(t, "2021-12-31T23:59:59.999999999Z", snapshots[1].At.Format(time.RFC3339Nano))
+	assert.Equal(t, float64(1.2), snapshots[1].Balances[common.AssetTypeBTC])
+	assert.Equal(t, float64(100.5), snapshots[1].Balances[common.AssetTypeEuro])
+}
+
+func TestParseBalanceSnapshotsJSON(t *testing.T) {
+
+	snapshots, err := ParseBalanceSnapshots([]byte(`[
+		{"exchange": "kr", "time": "2021-12-31", "balances": {"BTC": 1.2, "EUR": 100.5}},
+		{"exchange": "kr", "time": "2021-12-31", "asset": "LTC", "balance": 2}
+	]`))
+
+	require.NoError(t, err)
+	require.Equal(t, 1, len(snapshots))
+	assert.Equal(t, 3, len(snapshots[0].Balances))
+	assert.Equal(t, float64(2), snapshots[0].Balances[common.AssetTypeLTC])
+
+	_, err = ParseBalanceSnapshots([]byte(`[{"exchange": "kr", "time": "31/12/2021", "balances": {}}]`))
+	assert.EqualError(t, err, "invalid snapshot time: 31/12/2021")
+}
@@ -0,0 +1,130 @@
+package parsers
+
+import (
+	"fmt"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNewLine
+	tokenIdent
+	tokenWildcard
+	tokenColon
+	tokenComma
+	tokenEquals
+	tokenArrow
+)
+
+var tokenNames = map[tokenKind]string{
+	tokenEOF:      "end of input",
+	tokenNewLine:  "new-line",
+	tokenIdent:    "identifier",
+	tokenWildcard: "'*'",
+	tokenColon:    "':'",
+	tokenComma:    "','",
+	tokenEquals:   "'='",
+	tokenArrow:    "'->'",
+}
+
+func (kind tokenKind) String() string {
+	return tokenNames[kind]
+}
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   Position
+}
+
+func (t token) String() string {
+
+	if t.kind == tokenIdent {
+		return fmt.Sprintf("'%s'", t.value)
+	}
+
+	return t.kind.String()
+}
+
+// lex splits the _expr_ into tokens. Comments, starting with _#_, are skipped
+// until end of line. Whitespace (except new-line) is ignored.
+func lex(expr string) ([]token, error) {
+
+	tokens := []token{}
+	runes := []rune(expr)
+	pos := Position{Line: 1, Column: 1}
+
+	advance := func(n int) {
+		pos.Column += n
+	}
+
+	for i := 0; i < len(runes); {
+
+		r := runes[i]
+
+		switch {
+		case r == '\n':
+
+			tokens = append(tokens, token{kind: tokenNewLine, pos: pos})
+			pos = Position{Line: pos.Line + 1, Column: 1}
+			i++
+
+		case r == '#':
+
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+				advance(1)
+			}
+
+		case unicode.IsSpace(r):
+
+			i++
+			advance(1)
+
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '>':
+
+			tokens = append(tokens, token{kind: tokenArrow, value: "->", pos: pos})
+			i += 2
+			advance(2)
+
+		case r == '*' || r == ':' || r == ',' || r == '=':
+
+			kind := map[rune]tokenKind{
+				'*': tokenWildcard, ':': tokenColon, ',': tokenComma, '=': tokenEquals,
+			}[r]
+
+			tokens = append(tokens, token{kind: kind, value: string(r), pos: pos})
+			i++
+			advance(1)
+
+		case isIdentRune(r):
+
+			start, startPos := i, pos
+			for i < len(runes) && isIdentRune(runes[i]) {
+				i++
+				advance(1)
+			}
+
+			tokens = append(tokens, token{
+				kind: tokenIdent, value: string(runes[start:i]), pos: startPos,
+			})
+
+		default:
+
+			return nil, &ParseError{
+				Position: pos,
+				Message:  fmt.Sprintf("unexpected character '%c'", r),
+			}
+
+		}
+
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: pos}), nil
+}
+
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.'
+}
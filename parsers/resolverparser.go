@@ -2,30 +2,65 @@ package parsers
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/mariotoffia/gocryptoadmin/common"
 )
 
 // ResolverParser parses a resolver expression on form
-// _btx:USDT = btx,all:USDT-USD -> ofx,all:USD-EUR_.
+// _btx:USDT = btx,all:USD -> ofx,all:EUR_.
 //
-// All patterns are terminated with a new-line.
+// Each reference in the path is a asset that is expanded to a asset pair with the
+// previous asset, e.g. the above becomes the pairs _USDT-USD_ and _USD-EUR_.
 //
-// When prefix is omitted, the _all_ prefix is appended.
+// All definitions are terminated with a new-line. A definition may span several
+// lines as long as the line is broken before or after a _->_ (or after the _=_).
+//
+// When prefix is omitted, the _all_ prefix is appended. The wildcard prefix _*_
+// (`common.ExchangeAny`) matches any exchange, e.g. _*:USDT = USD_.
+//
+// Blank lines are ignored and _#_ starts a comment that lasts to the end of the line.
+//
+// .Grammar
+// ====
+// definitions := { definition | new-line }
+// definition  := reference '=' path ( new-line | end )
+// path        := reference { '->' reference }
+// reference   := [ prefix { ',' prefix } ':' ] asset
+// prefix      := identifier | '*'
+// ====
 type ResolverParser struct {
 	expressions []ResolverExpression
 }
 
+// Position is a one based line and column in the parsed expression.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// ParseError is returned when a expression is not valid.
+type ParseError struct {
+	Position
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
 type ResolverExpressionPathItem struct {
 	AssetPrefixes []string
 	AssetPair     common.AssetPair
+	// Position is where the path item is defined.
+	Position Position
 }
 
 type ResolverExpression struct {
 	Asset         common.AssetType
 	AssetPrefixes []string
 	Path          []ResolverExpressionPathItem
+	// Position is where the expression is defined.
+	Position Position
 }
 
 func NewResolverParser() *ResolverParser {
@@ -34,83 +69,219 @@ func NewResolverParser() *ResolverParser {
 	}
 }
 
+// Parse parses the _expr_ and panics with a `*ParseError` if it is not valid.
 func (parser *ResolverParser) Parse(expr string) *ResolverParser {
 
-	lines := strings.Split(strings.ReplaceAll(expr, "\r\n", "\n"), "\n")
+	if err := parser.TryParse(expr); err != nil {
+		panic(err)
+	}
+
+	return parser
+}
 
-	for _, line := range lines {
+// TryParse is same as `Parse` but returns a `*ParseError` instead of panic. When
+// it fails, none of the expressions in _expr_ are added.
+func (parser *ResolverParser) TryParse(expr string) error {
 
-		expr := ResolverExpression{
-			Path: []ResolverExpressionPathItem{},
-		}
+	tokens, err := lex(expr)
+	if err != nil {
+		return err
+	}
 
-		eq := strings.Split(line, "=")
+	p := &exprParser{tokens: tokens}
+	expressions := []ResolverExpression{}
 
-		if len(eq) != 2 {
+	for {
 
-			panic(
-				fmt.Sprintf("expr: %s is not valid", line),
-			)
+		p.skipNewLines()
 
+		if p.peek().kind == tokenEOF {
+			break
 		}
 
-		assetPrefixes, assetExpr := parser.getPrefixes(parser.cleanString(eq[0]))
+		e, err := p.parseDefinition()
+		if err != nil {
+			return err
+		}
+
+		expressions = append(expressions, e)
+	}
+
+	parser.expressions = append(parser.expressions, expressions...)
+	return nil
+}
+
+func (parser *ResolverParser) GetExpressions() []ResolverExpression {
+	return parser.expressions
+}
+
+type exprParser struct {
+	tokens []token
+	idx    int
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.idx]
+}
+
+func (p *exprParser) next() token {
+
+	t := p.tokens[p.idx]
+	if t.kind != tokenEOF {
+		p.idx++
+	}
 
-		asset := common.AssetType(assetExpr)
-		expr.Asset = asset
-		expr.AssetPrefixes = assetPrefixes
+	return t
+}
 
-		paths := strings.Split(parser.cleanString(eq[1]), "->")
-		for _, path := range paths {
+func (p *exprParser) skipNewLines() {
 
-			assetPairPrefixes, costUnitExpr := parser.getPrefixes(path)
+	for p.peek().kind == tokenNewLine {
+		p.next()
+	}
+
+}
 
-			costUnit := common.AssetType(costUnitExpr)
-			expr.Path = append(expr.Path, ResolverExpressionPathItem{
-				AssetPrefixes: assetPairPrefixes,
-				AssetPair: common.AssetPair{
-					Asset:    asset,
-					CostUnit: costUnit,
-				},
-			})
+// peekPastNewLines returns the first token that is not a new-line without
+// consuming anything.
+func (p *exprParser) peekPastNewLines() token {
 
-			asset = costUnit
+	for i := p.idx; i < len(p.tokens); i++ {
 
+		if p.tokens[i].kind != tokenNewLine {
+			return p.tokens[i]
 		}
 
-		parser.expressions = append(parser.expressions, expr)
 	}
 
-	return parser
+	return p.tokens[len(p.tokens)-1]
 }
 
-func (parser *ResolverParser) GetExpressions() []ResolverExpression {
-	return parser.expressions
+func (p *exprParser) expect(kind tokenKind, what string) (token, error) {
+
+	t := p.next()
+	if t.kind != kind {
+		return t, unexpected(t, what)
+	}
+
+	return t, nil
 }
 
-func (parser *ResolverParser) cleanString(expr string) string {
+func (p *exprParser) parseDefinition() (ResolverExpression, error) {
+
+	prefixes, asset, pos, err := p.parseReference()
+	if err != nil {
+		return ResolverExpression{}, err
+	}
+
+	if _, err := p.expect(tokenEquals, "'='"); err != nil {
+		return ResolverExpression{}, err
+	}
+
+	expr := ResolverExpression{
+		Asset:         asset,
+		AssetPrefixes: prefixes,
+		Path:          []ResolverExpressionPathItem{},
+		Position:      pos,
+	}
+
+	p.skipNewLines()
+
+	for {
+
+		prefixes, costUnit, pos, err := p.parseReference()
+		if err != nil {
+			return ResolverExpression{}, err
+		}
+
+		expr.Path = append(expr.Path, ResolverExpressionPathItem{
+			AssetPrefixes: prefixes,
+			AssetPair: common.AssetPair{
+				Asset:    asset,
+				CostUnit: costUnit,
+			},
+			Position: pos,
+		})
+
+		asset = costUnit
+
+		if p.peekPastNewLines().kind != tokenArrow {
+			break
+		}
 
-	expr = strings.ReplaceAll(expr, " ", "")
-	return strings.ReplaceAll(expr, "\t", "")
+		p.skipNewLines()
+		p.next() // ->
+		p.skipNewLines()
 
+	}
+
+	if t := p.next(); t.kind != tokenNewLine && t.kind != tokenEOF {
+		return ResolverExpression{}, unexpected(t, "'->' or new-line")
+	}
+
+	return expr, nil
 }
 
-func (parser *ResolverParser) getPrefixes(expr string) ([]string, string) {
+// parseReference parses _[prefix {, prefix} :] asset_.
+func (p *exprParser) parseReference() ([]string, common.AssetType, Position, error) {
+
+	pos := p.peek().pos
+	items := []token{}
 
-	c := strings.Split(expr, ":")
+	for {
 
-	if len(c) <= 1 {
-		return []string{"all"}, expr
+		t := p.next()
+		if t.kind != tokenIdent && t.kind != tokenWildcard {
+			return nil, "", pos, unexpected(t, "exchange or asset")
+		}
+
+		items = append(items, t)
+
+		if p.peek().kind != tokenComma {
+			break
+		}
+
+		p.next()
 	}
 
-	if len(c) != 2 {
+	if p.peek().kind != tokenColon {
 
-		panic(
-			fmt.Sprintf("expr: %s is not valid", expr),
-		)
+		if len(items) > 1 {
+			return nil, "", pos, unexpected(p.peek(), "':'")
+		}
+
+		if items[0].kind == tokenWildcard {
+
+			return nil, "", pos, &ParseError{
+				Position: items[0].pos,
+				Message:  "wildcard is only allowed as exchange prefix",
+			}
+
+		}
 
+		return []string{common.ExchangeAll}, common.AssetType(items[0].value), pos, nil
 	}
 
-	return strings.Split(c[0], ","), c[1]
+	p.next() // :
+
+	asset, err := p.expect(tokenIdent, "asset")
+	if err != nil {
+		return nil, "", pos, err
+	}
+
+	prefixes := make([]string, len(items))
+	for i := range items {
+		prefixes[i] = items[i].value
+	}
+
+	return prefixes, common.AssetType(asset.value), pos, nil
+}
+
+func unexpected(t token, what string) *ParseError {
+
+	return &ParseError{
+		Position: t.pos,
+		Message:  fmt.Sprintf("unexpected %s, expected %s", t, what),
+	}
 
 }
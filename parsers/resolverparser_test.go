@@ -51,3 +51,56 @@ func TestNoPrefixMeansAll(t *testing.T) {
 	assert.Equal(t, "all", expr[0].Path[1].AssetPrefixes[0])
 	assert.Equal(t, "USD-EUR", expr[0].Path[1].AssetPair.String())
 }
+
+func TestCommentsBlankLinesAndMultiLine(t *testing.T) {
+
+	expr := NewResolverParser().
+		Parse(`
+# Bittrex tether goes via USD
+btx:USDT = btx,all:USD   # first hop
+	-> ofx,all:EUR
+
+BTC = cbx,all:EUR
+`).
+		GetExpressions()
+
+	require.Equal(t, 2, len(expr))
+	require.Equal(t, 2, len(expr[0].Path))
+	assert.Equal(t, "USD-EUR", expr[0].Path[1].AssetPair.String())
+	assert.Equal(t, Position{Line: 3, Column: 1}, expr[0].Position)
+	assert.Equal(t, Position{Line: 4, Column: 5}, expr[0].Path[1].Position)
+	assert.Equal(t, Position{Line: 6, Column: 1}, expr[1].Position)
+}
+
+func TestWildcardPrefix(t *testing.T) {
+
+	expr := NewResolverParser().
+		Parse("*:USDT = *:USD").
+		GetExpressions()
+
+	require.Equal(t, 1, len(expr))
+	assert.Equal(t, []string{common.ExchangeAny}, expr[0].AssetPrefixes)
+	assert.Equal(t, []string{common.ExchangeAny}, expr[0].Path[0].AssetPrefixes)
+	assert.Equal(t, "USDT-USD", expr[0].Path[0].AssetPair.String())
+}
+
+func TestParseErrorHasPosition(t *testing.T) {
+
+	parser := NewResolverParser()
+
+	err := parser.TryParse("BTC = EUR\n\nETH = cbx:BTC EUR")
+	require.NotNil(t, err)
+
+	perr, ok := err.(*ParseError)
+	require.True(t, ok)
+	assert.Equal(t, 3, perr.Line)
+	assert.Equal(t, 15, perr.Column)
+	assert.Equal(t, "line 3, column 15: unexpected 'EUR', expected '->' or new-line", err.Error())
+	assert.Equal(t, 0, len(parser.GetExpressions()), "nothing added on error")
+
+	err = parser.TryParse("BTC = * ")
+	require.NotNil(t, err)
+	assert.Equal(t, "line 1, column 7: wildcard is only allowed as exchange prefix", err.Error())
+
+	assert.Panics(t, func() { parser.Parse("BTC == EUR") })
+}
@@ -0,0 +1,339 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/processors"
+	"github.com/mariotoffia/gocryptoadmin/txhistory"
+)
+
+// Config is the _JSON_ configuration of a pipeline.
+//
+// .Example
+// ====
+// {"stages": [
+// {"type": "chronological", "options": {"rules": ["tradeid"]}},
+// {"name": "sek", "type": "costunit", "options": {"calculator": "vwap", "assets": ["SEK"]}},
+// {"type": "group", "options": {"window": "5m"}},
+// {"type": "multiaccounting"}
+// ]}
+// ====
+type Config struct {
+	Stages []StageConfig `json:"stages"`
+}
+
+// StageConfig configures a single stage. If _Name_ is omitted, the _Type_ is used.
+type StageConfig struct {
+	Name    string          `json:"name,omitempty"`
+	Type    string          `json:"type"`
+	Options json.RawMessage `json:"options,omitempty"`
+}
+
+// Context holds the dependencies that the stage factories may need.
+type Context struct {
+	// Resolver is needed by the _costunit_ stage.
+	Resolver *txhistory.TxOHCResolver
+}
+
+// StageFactory creates a `Stage` from the, possibly empty, _options_.
+type StageFactory func(ctx *Context, options json.RawMessage) (Stage, error)
+
+var stageFactories = map[string]StageFactory{
+	"chronological":        chronologicalFactory,
+	"chronological-groups": chronologicalGroupsFactory,
+	"adjustments":          adjustmentsFactory,
+	"costunit":             costUnitFactory,
+	"group":                groupFactory,
+	"accounting":           accountingFactory,
+	"multiaccounting":      multiAccountingFactory,
+	"buysell":              buySellFactory,
+}
+
+// RegisterStageFactory registers (or replaces) a stage factory under _name_ so it can
+// be used as _type_ in the `Config`.
+func RegisterStageFactory(name string, factory StageFactory) {
+	stageFactories[name] = factory
+}
+
+// GetStageFactoryNames returns all registered stage types, sorted.
+func GetStageFactoryNames() []string {
+
+	names := make([]string, 0, len(stageFactories))
+	for name := range stageFactories {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// ParseConfig parses a _JSON_ pipeline configuration.
+func ParseConfig(data []byte) (Config, error) {
+
+	var cfg Config
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// Build creates a pipeline from _cfg_. If _ctx_ is `nil`, a empty `Context` is used.
+func Build(cfg Config, ctx *Context) (*Pipeline, error) {
+
+	if ctx == nil {
+		ctx = &Context{}
+	}
+
+	p := New()
+	names := map[string]bool{}
+
+	for i, sc := range cfg.Stages {
+
+		factory, ok := stageFactories[sc.Type]
+		if !ok {
+
+			return nil, fmt.Errorf(
+				"[index: %d] unknown stage type: %s, available: %v", i, sc.Type, GetStageFactoryNames(),
+			)
+
+		}
+
+		name := sc.Name
+		if name == "" {
+			name = sc.Type
+		}
+
+		if names[name] {
+			return nil, fmt.Errorf("[index: %d] duplicate stage name: %s", i, name)
+		}
+
+		names[name] = true
+
+		stage, err := factory(ctx, sc.Options)
+		if err != nil {
+			return nil, fmt.Errorf("[index: %d] stage: %s - %s", i, name, err.Error())
+		}
+
+		p.Add(name, stage)
+	}
+
+	return p, nil
+}
+
+// Load parses the _JSON_ configuration and builds the pipeline.
+func Load(data []byte, ctx *Context) (*Pipeline, error) {
+
+	cfg, err := ParseConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return Build(cfg, ctx)
+}
+
+func decodeOptions(options json.RawMessage, v interface{}) error {
+
+	if len(options) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(options, v)
+}
+
+func chronologicalFactory(ctx *Context, options json.RawMessage) (Stage, error) {
+
+	var opts struct {
+		Rules []string `json:"rules"`
+	}
+
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+
+	proc := processors.NewChronologicalTxEntryProcessor()
+
+	if len(opts.Rules) > 0 {
+
+		rules, err := orderingRules(opts.Rules)
+		if err != nil {
+			return nil, err
+		}
+
+		proc.UseOrderingRules(rules...)
+	}
+
+	return LogStage(proc), nil
+}
+
+func chronologicalGroupsFactory(ctx *Context, options json.RawMessage) (Stage, error) {
+
+	var opts struct {
+		Rules []string `json:"rules"`
+	}
+
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+
+	proc := processors.NewChronologicalGroupTxEntryProcessor()
+
+	if len(opts.Rules) > 0 {
+
+		rules, err := orderingRules(opts.Rules)
+		if err != nil {
+			return nil, err
+		}
+
+		proc.UseOrderingRules(rules...)
+	}
+
+	return GroupStage(proc), nil
+}
+
+func orderingRules(names []string) ([]processors.OrderingRule, error) {
+
+	rules := make([]processors.OrderingRule, 0, len(names))
+
+	for _, name := range names {
+
+		rule, ok := processors.GetOrderingRule(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown ordering rule: %s", name)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func adjustmentsFactory(ctx *Context, options json.RawMessage) (Stage, error) {
+
+	var opts struct {
+		Adjustments []common.Adjustment `json:"adjustments"`
+	}
+
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+
+	for i := range opts.Adjustments {
+
+		if err := opts.Adjustments[i].Validate(); err != nil {
+			return nil, err
+		}
+
+	}
+
+	return LogStage(processors.NewAdjustmentProcessor(nil, opts.Adjustments...)), nil
+}
+
+func costUnitFactory(ctx *Context, options json.RawMessage) (Stage, error) {
+
+	var opts struct {
+		Calculator  string             `json:"calculator"`
+		Assets      []common.AssetType `json:"assets"`
+		TradeWindow string             `json:"tradeWindow"`
+	}
+
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+
+	if ctx.Resolver == nil {
+		return nil, fmt.Errorf("costunit stage requires a resolver in the context")
+	}
+
+	if opts.Calculator != "" {
+
+		if _, ok := processors.GetPriceCalculator(opts.Calculator); !ok {
+			return nil, fmt.Errorf("unknown price calculator: %s", opts.Calculator)
+		}
+
+	}
+
+	proc := processors.NewCostUnitProcessorByName(ctx.Resolver, opts.Calculator)
+	proc.RegisterAsset(opts.Assets...)
+
+	if opts.TradeWindow != "" {
+
+		window, err := time.ParseDuration(opts.TradeWindow)
+		if err != nil {
+			return nil, err
+		}
+
+		proc.UseTradePrices(window)
+	}
+
+	return LogStage(proc), nil
+}
+
+func groupFactory(ctx *Context, options json.RawMessage) (Stage, error) {
+
+	var opts struct {
+		Window string `json:"window"`
+	}
+
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+
+	window := time.Duration(0)
+
+	if opts.Window != "" {
+
+		var err error
+		if window, err = time.ParseDuration(opts.Window); err != nil {
+			return nil, err
+		}
+
+	}
+
+	return GroupingStage(processors.NewTxGroupProcessor(window)), nil
+}
+
+func accountingFactory(ctx *Context, options json.RawMessage) (Stage, error) {
+
+	var opts struct {
+		Exchange string `json:"exchange"`
+	}
+
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+
+	if opts.Exchange == "" {
+		return nil, fmt.Errorf("accounting stage requires a exchange")
+	}
+
+	return EntryStage(processors.NewAccountingProcessor(opts.Exchange)), nil
+}
+
+func multiAccountingFactory(ctx *Context, options json.RawMessage) (Stage, error) {
+	return AccountsStage(processors.NewMultiExchangeAccountingProcessor()), nil
+}
+
+func buySellFactory(ctx *Context, options json.RawMessage) (Stage, error) {
+
+	var opts struct {
+		Taxation bool `json:"taxation"`
+	}
+
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+
+	proc := processors.NewTxBuySellProcessor()
+
+	if opts.Taxation {
+		proc.UseTaxationMarking()
+	}
+
+	return BuySellStage(proc), nil
+}
@@ -0,0 +1,192 @@
+package pipeline
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+)
+
+// Kind is the type of data a `Stage` consumes or produces.
+type Kind string
+
+const (
+	// KindLogs is raw `common.TransactionLog` as returned by the readers.
+	KindLogs Kind = "logs"
+	// KindEntries is `common.TransactionEntry` (any implementation).
+	KindEntries Kind = "entries"
+	// KindGroups is `common.TxGroupEntry`.
+	KindGroups Kind = "groups"
+	// KindAccounts is account entries keyed by exchange, as returned by a
+	// `common.MultiAccountTxProcessor`.
+	KindAccounts Kind = "accounts"
+	// KindBuySell is paired sells with its buys and the entries that could not be paired.
+	KindBuySell Kind = "buysell"
+)
+
+// Data is what flows between the stages. Only the field(s) that corresponds to
+// the _Kind_ is set.
+type Data struct {
+	Kind     Kind
+	Logs     []common.TransactionLog
+	Entries  []common.TransactionEntry
+	Groups   []common.TxGroupEntry
+	Accounts map[string][]common.TransactionEntry
+	BuySell  []common.TxBuySellEntry
+	// Unpaired is the entries from the buy-sell pairing that did not pair.
+	Unpaired []common.TransactionEntry
+}
+
+// NewLogs creates a `KindLogs` data.
+func NewLogs(tx []common.TransactionLog) Data {
+	return Data{Kind: KindLogs, Logs: tx}
+}
+
+// NewEntries creates a `KindEntries` data.
+func NewEntries(tx []common.TransactionEntry) Data {
+	return Data{Kind: KindEntries, Entries: tx}
+}
+
+// NewGroups creates a `KindGroups` data.
+func NewGroups(tx []common.TxGroupEntry) Data {
+	return Data{Kind: KindGroups, Groups: tx}
+}
+
+// NewAccounts creates a `KindAccounts` data.
+func NewAccounts(accounts map[string][]common.TransactionEntry) Data {
+	return Data{Kind: KindAccounts, Accounts: accounts}
+}
+
+// NewBuySell creates a `KindBuySell` data.
+func NewBuySell(pairs []common.TxBuySellEntry, unpaired []common.TransactionEntry) Data {
+	return Data{Kind: KindBuySell, BuySell: pairs, Unpaired: unpaired}
+}
+
+// Len returns the number of entries (all exchanges when `KindAccounts`).
+func (d Data) Len() int {
+
+	switch d.Kind {
+	case KindLogs:
+		return len(d.Logs)
+	case KindEntries:
+		return len(d.Entries)
+	case KindGroups:
+		return len(d.Groups)
+	case KindBuySell:
+		return len(d.BuySell)
+	case KindAccounts:
+
+		n := 0
+		for _, entries := range d.Accounts {
+			n += len(entries)
+		}
+
+		return n
+	}
+
+	return 0
+}
+
+// Convert adapts the data into _kind_. The following conversions are supported:
+//
+// * any kind into `KindEntries`
+// * `KindEntries` into `KindLogs` if all entries are `*common.TransactionLog`
+// * `KindEntries` into `KindGroups` if all entries are `*common.TxGroupEntry`
+//
+// A `KindAccounts` is converted into the `common.ExchangeAll` entries if present,
+// otherwise all exchanges are concatenated in exchange name order. A `KindBuySell`
+// is converted into the pairs only.
+func (d Data) Convert(kind Kind) (Data, error) {
+
+	if d.Kind == kind {
+		return d, nil
+	}
+
+	entries := d.toEntries()
+
+	switch kind {
+	case KindEntries:
+		return NewEntries(entries), nil
+	case KindLogs:
+
+		logs := make([]common.TransactionLog, len(entries))
+
+		for i, entry := range entries {
+
+			tx, ok := entry.(*common.TransactionLog)
+			if !ok {
+				return Data{}, fmt.Errorf("cannot convert %s into %s: %T", d.Kind, kind, entry)
+			}
+
+			logs[i] = *tx
+		}
+
+		return NewLogs(logs), nil
+
+	case KindGroups:
+
+		groups := make([]common.TxGroupEntry, len(entries))
+
+		for i, entry := range entries {
+
+			tx, ok := entry.(*common.TxGroupEntry)
+			if !ok {
+				return Data{}, fmt.Errorf("cannot convert %s into %s: %T", d.Kind, kind, entry)
+			}
+
+			groups[i] = *tx
+		}
+
+		return NewGroups(groups), nil
+
+	}
+
+	return Data{}, fmt.Errorf("cannot convert %s into %s", d.Kind, kind)
+}
+
+func (d Data) toEntries() []common.TransactionEntry {
+
+	entries := []common.TransactionEntry{}
+
+	switch d.Kind {
+	case KindEntries:
+		entries = append(entries, d.Entries...)
+	case KindLogs:
+
+		for i := range d.Logs {
+			entries = append(entries, &d.Logs[i])
+		}
+
+	case KindGroups:
+
+		for i := range d.Groups {
+			entries = append(entries, &d.Groups[i])
+		}
+
+	case KindBuySell:
+
+		for i := range d.BuySell {
+			entries = append(entries, d.BuySell[i])
+		}
+
+	case KindAccounts:
+
+		if all, ok := d.Accounts[common.ExchangeAll]; ok {
+			return append(entries, all...)
+		}
+
+		exchanges := make([]string, 0, len(d.Accounts))
+		for exchange := range d.Accounts {
+			exchanges = append(exchanges, exchange)
+		}
+
+		sort.Strings(exchanges)
+
+		for _, exchange := range exchanges {
+			entries = append(entries, d.Accounts[exchange]...)
+		}
+
+	}
+
+	return entries
+}
@@ -0,0 +1,114 @@
+// Package pipeline composes the processors into named stages, either from code or
+// from a configuration file.
+//
+// Each stage consumes and produces a `Kind` of `Data` and the pipeline adapts the data
+// between the stages, e.g. `KindLogs` into `KindEntries`.
+package pipeline
+
+import (
+	"fmt"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+)
+
+type namedStage struct {
+	name  string
+	stage Stage
+}
+
+// Pipeline runs the stages in the order they where added.
+type Pipeline struct {
+	stages  []namedStage
+	inspect bool
+	results map[string]Data
+}
+
+// New creates a empty pipeline.
+func New() *Pipeline {
+
+	return &Pipeline{
+		stages:  []namedStage{},
+		results: map[string]Data{},
+	}
+
+}
+
+// Add appends a named stage. It panics if the _name_ is empty or already used.
+func (p *Pipeline) Add(name string, stage Stage) *Pipeline {
+
+	if name == "" {
+		panic("pipeline stage must have a name")
+	}
+
+	for _, s := range p.stages {
+
+		if s.name == name {
+			panic(fmt.Sprintf("pipeline stage: %s already exists", name))
+		}
+
+	}
+
+	p.stages = append(p.stages, namedStage{name: name, stage: stage})
+	return p
+
+}
+
+// UseInspection makes the pipeline keep the output of each stage so it may be
+// retrieved by `Result`.
+func (p *Pipeline) UseInspection() *Pipeline {
+
+	p.inspect = true
+	return p
+
+}
+
+// Stages returns the stage names in the order they are run.
+func (p *Pipeline) Stages() []string {
+
+	names := make([]string, len(p.stages))
+	for i := range p.stages {
+		names[i] = p.stages[i].name
+	}
+
+	return names
+}
+
+// Result returns the output of stage _name_ from the last `Run`. This requires
+// `UseInspection`.
+func (p *Pipeline) Result(name string) (Data, bool) {
+
+	data, ok := p.results[name]
+	return data, ok
+
+}
+
+// Run runs all stages, converting the data between them, and returns the output of
+// the last stage. It panics if the data cannot be converted.
+func (p *Pipeline) Run(in Data) Data {
+
+	p.results = map[string]Data{}
+	data := in
+
+	for _, s := range p.stages {
+
+		converted, err := data.Convert(s.stage.Input())
+		if err != nil {
+			panic(fmt.Sprintf("pipeline stage: %s - %s", s.name, err.Error()))
+		}
+
+		data = s.stage.Run(converted)
+
+		if p.inspect {
+			p.results[s.name] = data
+		}
+
+	}
+
+	return data
+}
+
+// RunLogs is a convenience function to run the pipeline using the raw _tx_, e.g.
+// from `txlog.TxLogReaderImpl`.
+func (p *Pipeline) RunLogs(tx []common.TransactionLog) Data {
+	return p.Run(NewLogs(tx))
+}
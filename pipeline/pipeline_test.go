@@ -0,0 +1,181 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/processors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pipelineTestTx() []common.TransactionLog {
+
+	day1, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+	btceur := common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeEuro}
+
+	return []common.TransactionLog{
+		{
+			ID: "buy", Exchange: "kr", Side: common.SideTypeBuy, CreatedAt: day1.Add(time.Hour),
+			AssetSize: 1, PricePerUnit: 50, TotalPrice: -50, AssetPair: btceur,
+		},
+		{
+			ID: "deposit", Exchange: "kr", Side: common.SideTypeReceive, CreatedAt: day1,
+			AssetSize: 100, PricePerUnit: 1, TotalPrice: 100,
+			AssetPair: common.AssetPair{Asset: common.AssetTypeEuro, CostUnit: common.AssetTypeEuro},
+		},
+	}
+
+}
+
+func TestPipelineFromCodeWithInspection(t *testing.T) {
+
+	p := New().
+		UseInspection().
+		Add("sort", LogStage(processors.NewChronologicalTxEntryProcessor())).
+		Add("group", GroupingStage(processors.NewTxGroupProcessor(time.Minute))).
+		Add("accounts", AccountsStage(processors.NewMultiExchangeAccountingProcessor()))
+
+	assert.Equal(t, []string{"sort", "group", "accounts"}, p.Stages())
+
+	out := p.RunLogs(pipelineTestTx())
+
+	require.Equal(t, KindAccounts, out.Kind)
+	require.Equal(t, 2, len(out.Accounts["kr"]))
+
+	status := out.Accounts["kr"][1].(common.AccountEntry).GetAccountStatus()
+	assert.Equal(t, float64(50), status[common.AssetTypeEuro])
+	assert.Equal(t, float64(1), status[common.AssetTypeBTC])
+
+	sorted, ok := p.Result("sort")
+	require.True(t, ok)
+	require.Equal(t, KindLogs, sorted.Kind)
+	assert.Equal(t, "deposit", sorted.Logs[0].ID)
+
+	groups, ok := p.Result("group")
+	require.True(t, ok)
+	assert.Equal(t, KindGroups, groups.Kind)
+	assert.Equal(t, 2, groups.Len())
+}
+
+func TestPipelineAppliesAdjustmentsOnEachRun(t *testing.T) {
+
+	day1, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+
+	adjust := processors.NewAdjustmentProcessor(nil, common.Adjustment{
+		Action: common.AdjustmentActionAdd, Exchange: "kr", ID: "bonus", Reason: "missing bonus",
+		Tx: &common.TransactionLog{
+			Side: common.SideTypeReceive, CreatedAt: day1.Add(time.Minute), AssetSize: 10, PricePerUnit: 1, TotalPrice: 10,
+			AssetPair: common.AssetPair{Asset: common.AssetTypeEuro, CostUnit: common.AssetTypeEuro},
+		},
+	}).UseLog(nil)
+
+	p := New().
+		Add("adjust", LogStage(adjust)).
+		Add("sort", LogStage(processors.NewChronologicalTxEntryProcessor()))
+
+	for run := 0; run < 2; run++ {
+
+		out := p.RunLogs(pipelineTestTx())
+
+		require.Equal(t, 3, len(out.Logs), "run %d", run)
+		assert.Equal(t, "bonus", out.Logs[1].ID, "run %d", run)
+		assert.Equal(t, 0, len(adjust.GetUnmatched()), "run %d", run)
+
+	}
+
+}
+
+func TestPipelineKeepsSeededState(t *testing.T) {
+
+	day1, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+	btceur := common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeEuro}
+
+	sell := []common.TransactionLog{
+		{
+			ID: "sell", Exchange: "kr", Side: common.SideTypeSell, CreatedAt: day1,
+			AssetSize: 1, PricePerUnit: 150, TotalPrice: 150, AssetPair: btceur,
+		},
+	}
+
+	accounts := processors.NewMultiExchangeAccountingProcessor().SeedBalances(
+		map[string]common.AccountStatus{"kr": {common.AssetTypeBTC: 2}}, day1.Add(-time.Hour),
+	)
+
+	buysell := processors.NewTxBuySellProcessor().SeedLots([]common.InventoryLot{
+		{
+			Queue: common.AssetTypeBTC,
+			Tx: common.TransactionLog{
+				ID: "buy", Exchange: "kr", Side: common.SideTypeBuy, CreatedAt: day1.Add(-time.Hour),
+				AssetSize: 2, PricePerUnit: 100, TotalPrice: -200, AssetPair: btceur,
+			},
+		},
+	})
+
+	for run := 0; run < 2; run++ {
+
+		out := New().Add("accounts", AccountsStage(accounts)).RunLogs(sell)
+
+		entries := out.Accounts["kr"]
+		status := entries[len(entries)-1].(common.AccountEntry).GetAccountStatus()
+		assert.Equal(t, float64(1), status[common.AssetTypeBTC], "run %d", run)
+		assert.Equal(t, float64(150), status[common.AssetTypeEuro], "run %d", run)
+
+		out = New().Add("buysell", BuySellStage(buysell)).RunLogs(sell)
+
+		require.Equal(t, 1, len(out.BuySell), "run %d", run)
+		assert.Equal(t, float64(1), out.BuySell[0].GetBuy().GetAssetSize(), "run %d", run)
+		require.Equal(t, 1, len(out.Unpaired), "run %d", run)
+		assert.Equal(t, float64(1), out.Unpaired[0].GetAssetSize(), "run %d", run)
+
+	}
+
+}
+
+func TestPipelineFromConfig(t *testing.T) {
+
+	p, err := Load([]byte(`{"stages": [
+		{"name": "fix", "type": "adjustments", "options": {"adjustments": [
+			{"action": "delete", "exchange": "kr", "id": "buy", "reason": "test"}
+		]}},
+		{"type": "chronological", "options": {"rules": ["side", "row"]}},
+		{"type": "multiaccounting"}
+	]}`), nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fix", "chronological", "multiaccounting"}, p.Stages())
+
+	out := p.RunLogs(pipelineTestTx())
+	require.Equal(t, 1, len(out.Accounts["kr"]))
+
+	entries, err := out.Convert(KindEntries)
+	require.NoError(t, err)
+	assert.Equal(t, "deposit", entries.Entries[0].GetID())
+}
+
+func TestPipelineConfigErrors(t *testing.T) {
+
+	_, err := Load([]byte(`{"stages": [{"type": "nope"}]}`), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown stage type: nope")
+
+	_, err = Load([]byte(`{"stages": [{"type": "costunit"}]}`), nil)
+	require.Error(t, err)
+	assert.Equal(t, "[index: 0] stage: costunit - costunit stage requires a resolver in the context", err.Error())
+
+	_, err = Load([]byte(`{"stages": [{"type": "group"}, {"type": "group"}]}`), nil)
+	require.Error(t, err)
+	assert.Equal(t, "[index: 1] duplicate stage name: group", err.Error())
+}
+
+func TestPipelinePanicsOnUnconvertibleData(t *testing.T) {
+
+	p := New().Add("sort", LogStage(processors.NewChronologicalTxEntryProcessor()))
+
+	assert.Panics(t, func() {
+		p.Run(NewAccounts(map[string][]common.TransactionEntry{
+			"kr": {&common.TxGroupEntry{}},
+		}))
+	})
+}
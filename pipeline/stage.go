@@ -0,0 +1,138 @@
+package pipeline
+
+import (
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/processors"
+)
+
+// Stage is a single step in a `Pipeline`. The pipeline converts the data into the
+// `Input` kind before invoking `Run`.
+type Stage interface {
+	Input() Kind
+	Output() Kind
+	Run(in Data) Data
+}
+
+// EntryGrouper is a processor that groups entries, e.g. `processors.TxGroupProcessor`.
+type EntryGrouper interface {
+	common.Processor
+	ProcessMany(tx []common.TransactionEntry)
+	Flush() []common.TxGroupEntry
+}
+
+type funcStage struct {
+	input  Kind
+	output Kind
+	fn     func(in Data) Data
+}
+
+func (s *funcStage) Input() Kind      { return s.input }
+func (s *funcStage) Output() Kind     { return s.output }
+func (s *funcStage) Run(in Data) Data { return s.fn(in) }
+
+// FuncStage creates a stage from a function.
+func FuncStage(input, output Kind, fn func(in Data) Data) Stage {
+	return &funcStage{input: input, output: output, fn: fn}
+}
+
+// restarter is a processor whose `Reset` keeps state between flushes and hence needs to
+// be restarted before each run, e.g. `processors.AdjustmentProcessor`.
+type restarter interface {
+	Restart()
+}
+
+// LogStage adapts a `common.TxLogProcessor`, e.g. `processors.CostUnitProcessor`.
+func LogStage(proc common.TxLogProcessor) Stage {
+
+	return FuncStage(KindLogs, KindLogs, func(in Data) Data {
+
+		if r, ok := proc.(restarter); ok {
+			r.Restart()
+		} else {
+			proc.Reset()
+		}
+
+		proc.ProcessMany(in.Logs)
+
+		return NewLogs(proc.Flush())
+
+	})
+
+}
+
+// EntryStage adapts a `common.TxFlushableEntryProcessor`, e.g. `processors.AccountingProcessor`
+// or a printer in the output package.
+func EntryStage(proc common.TxFlushableEntryProcessor) Stage {
+
+	return FuncStage(KindEntries, KindEntries, func(in Data) Data {
+
+		proc.Reset()
+		proc.ProcessMany(in.Entries)
+
+		return NewEntries(proc.Flush())
+
+	})
+
+}
+
+// GroupingStage adapts a `EntryGrouper` that groups entries, e.g. `processors.TxGroupProcessor`.
+func GroupingStage(proc EntryGrouper) Stage {
+
+	return FuncStage(KindEntries, KindGroups, func(in Data) Data {
+
+		proc.Reset()
+		proc.ProcessMany(in.Entries)
+
+		return NewGroups(proc.Flush())
+
+	})
+
+}
+
+// GroupStage adapts a `common.TxGroupProcessor`, e.g. `processors.ChronologicalGroupTxEntryProcessor`.
+func GroupStage(proc common.TxGroupProcessor) Stage {
+
+	return FuncStage(KindGroups, KindGroups, func(in Data) Data {
+
+		proc.Reset()
+		proc.ProcessMany(in.Groups)
+
+		return NewGroups(proc.Flush())
+
+	})
+
+}
+
+// AccountsStage adapts a `common.MultiAccountTxProcessor`, e.g. `processors.MultiExchangeAccountingProcessor`.
+//
+// The processor is reset before each run, seeded balances are kept (see
+// `processors.MultiExchangeAccountingProcessor.SeedBalances`).
+func AccountsStage(proc common.MultiAccountTxProcessor) Stage {
+
+	return FuncStage(KindEntries, KindAccounts, func(in Data) Data {
+
+		proc.Reset()
+		proc.ProcessMany(in.Entries)
+
+		return NewAccounts(proc.Flush())
+
+	})
+
+}
+
+// BuySellStage adapts the `processors.TxBuySellProcessor`.
+//
+// The processor is reset before each run, seeded lots are kept (see
+// `processors.TxBuySellProcessor.SeedLots`).
+func BuySellStage(proc *processors.TxBuySellProcessor) Stage {
+
+	return FuncStage(KindEntries, KindBuySell, func(in Data) Data {
+
+		proc.Reset()
+		proc.ProcessMany(in.Entries)
+
+		return NewBuySell(proc.Flush())
+
+	})
+
+}
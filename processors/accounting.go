@@ -1,12 +1,19 @@
 package processors
 
-import "github.com/mariotoffia/gocryptoadmin/common"
+import (
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+)
 
 // AccountingProcessor implements (ish) the `TxGroupProcessor` interface.
 type AccountingProcessor struct {
-	entries  []common.AccountEntry
-	previous common.AccountEntry
-	exchange string
+	entries     []common.AccountEntry
+	previous    common.AccountEntry
+	opening     common.AccountEntry
+	exchange    string
+	diagnostics []BalanceDiagnostic
+	diagnose    bool
 }
 
 // NewAccountingProcessor creates a new accounting processor that will
@@ -28,8 +35,55 @@ func NewAccountingProcessor(exchange string) *AccountingProcessor {
 
 }
 
+// UseDiagnostics enables the diagnostics mode where each time a asset balance goes
+// (more) negative, a `BalanceDiagnostic` is recorded with the offending transaction
+// and a suggestion on what is likely missing.
+func (ap *AccountingProcessor) UseDiagnostics() *AccountingProcessor {
+
+	ap.diagnose = true
+	return ap
+
+}
+
+// GetDiagnostics returns all recorded diagnostics in order of occurrence.
+func (ap *AccountingProcessor) GetDiagnostics() []BalanceDiagnostic {
+	return ap.diagnostics
+}
+
+// SeedBalance sets the opening balance, e.g. from a `common.Inventory`, that the
+// first processed transaction is applied on.
+func (ap *AccountingProcessor) SeedBalance(status common.AccountStatus, at time.Time) *AccountingProcessor {
+
+	ap.opening = common.NewOpeningAccountLog(ap.exchange, at, status)
+	ap.previous = ap.opening
+	return ap
+
+}
+
+// GetBalance returns a copy of the current account balance.
+func (ap *AccountingProcessor) GetBalance() common.AccountStatus {
+
+	status := common.AccountStatus{}
+
+	if ap.previous != nil {
+
+		for k, v := range ap.previous.GetAccountStatus() {
+			status[k] = v
+		}
+
+	}
+
+	return status
+}
+
+// Reset clears the entries, the diagnostics and the balance. The balance starts over
+// from the seeded opening balance, if any.
 func (ap *AccountingProcessor) Reset() {
+
 	ap.entries = []common.AccountEntry{}
+	ap.diagnostics = nil
+	ap.previous = ap.opening
+
 }
 
 func (ap *AccountingProcessor) ProcessMany(tx []common.TransactionEntry) {
@@ -55,6 +109,10 @@ func (ap *AccountingProcessor) Process(tx common.TransactionEntry) {
 
 	acc := common.NextAccountLog(ap.previous, tx)
 
+	if ap.diagnose {
+		ap.diagnoseEntry(acc, tx)
+	}
+
 	ap.entries = append(ap.entries, acc)
 	ap.previous = acc
 
@@ -81,6 +139,39 @@ func (ap *AccountingProcessor) Flush() []common.TransactionEntry {
 
 	}
 
-	ap.Reset()
+	// The balance and diagnostics are kept until `Reset`
+	ap.entries = []common.AccountEntry{}
 	return list
 }
+
+func (ap *AccountingProcessor) diagnoseEntry(acc *common.AccountLog, tx common.TransactionEntry) {
+
+	pair := tx.GetAssetPair()
+	assets := []common.AssetType{pair.CostUnit}
+
+	if pair.Asset != pair.CostUnit {
+		assets = append(assets, pair.Asset)
+	}
+
+	if feeAsset := tx.GetFeeAsset(); feeAsset != "" && feeAsset != pair.CostUnit && feeAsset != pair.Asset {
+		assets = append(assets, feeAsset)
+	}
+
+	for _, asset := range assets {
+
+		previous := float64(0)
+		if ap.previous != nil {
+			previous = ap.previous.GetAccountStatus()[asset]
+		}
+
+		if d, ok := diagnoseBalance(
+			ap.exchange, asset, previous, acc.GetAccountStatus()[asset], tx,
+		); ok {
+
+			ap.diagnostics = append(ap.diagnostics, d)
+
+		}
+
+	}
+
+}
@@ -0,0 +1,249 @@
+package processors
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+)
+
+// AdjustmentProcessor implements `common.TxLogProcessor` and applies manual
+// `common.Adjustment` onto the imported transactions.
+//
+// It is intended to be used as the post processor of `txlog.TxLogReaderImpl`, and
+// if _next_ is submitted, the adjusted transactions are passed onto _next_ when
+// flushed, e.g. a `ChronologicalTxEntryProcessor`.
+//
+// Deletes and patches are applied on each flush where a transaction with same exchange
+// and id exists. Adds are only applied once, since the reader may be invoked several
+// times, and reported as duplicate if the transaction already exists. A add is only
+// applied on a flush that contains transactions from the same exchange, since the
+// reader may relabel the exchange of all transactions in a buffer, e.g.
+// `ReadBufferAsExchange`. Until then, it is reported as unmatched.
+//
+// Each applied adjustment is logged onto the log writer (default `os.Stderr`) and
+// is available in `GetLog` together with the unmatched adjustments.
+type AdjustmentProcessor struct {
+	next         common.TxLogProcessor
+	adjustments  []common.Adjustment
+	transactions []common.TransactionLog
+	added        map[int]bool
+	matched      map[int]bool
+	log          []common.AdjustmentLog
+	w            io.Writer
+}
+
+// NewAdjustmentProcessor creates a new processor that applies _adjustments_ and then
+// passes the result onto _next_ (may be `nil`).
+//
+// It panics if any of the _adjustments_ is invalid.
+func NewAdjustmentProcessor(
+	next common.TxLogProcessor,
+	adjustments ...common.Adjustment,
+) *AdjustmentProcessor {
+
+	for i := range adjustments {
+
+		if err := adjustments[i].Validate(); err != nil {
+			panic(fmt.Sprintf("[index: %d] %s", i, err.Error()))
+		}
+
+	}
+
+	return &AdjustmentProcessor{
+		next:         next,
+		adjustments:  adjustments,
+		transactions: []common.TransactionLog{},
+		added:        map[int]bool{},
+		matched:      map[int]bool{},
+		log:          []common.AdjustmentLog{},
+		w:            os.Stderr,
+	}
+
+}
+
+// UseLog sets the writer where each applied adjustment is logged. If `nil`, nothing
+// is logged (but still available in `GetLog`).
+func (proc *AdjustmentProcessor) UseLog(w io.Writer) *AdjustmentProcessor {
+
+	proc.w = w
+	return proc
+
+}
+
+// Reset clears the buffered transactions but keeps which adjustments that have been
+// added, matched and the log, since the reader resets before each buffer. Use `Restart`
+// to start over.
+func (proc *AdjustmentProcessor) Reset() {
+
+	proc.transactions = []common.TransactionLog{}
+
+	if proc.next != nil {
+		proc.next.Reset()
+	}
+
+}
+
+// Restart clears all state, as if newly created, such that the adjustments may be applied
+// once more onto a new set of transactions, e.g. when a `pipeline.Pipeline` is run again.
+func (proc *AdjustmentProcessor) Restart() {
+
+	proc.added = map[int]bool{}
+	proc.matched = map[int]bool{}
+	proc.log = []common.AdjustmentLog{}
+
+	proc.Reset()
+
+}
+
+func (proc *AdjustmentProcessor) ProcessMany(tx []common.TransactionLog) {
+
+	for i := range tx {
+		proc.Process(tx[i])
+	}
+
+}
+
+func (proc *AdjustmentProcessor) Process(tx common.TransactionLog) {
+	proc.transactions = append(proc.transactions, tx)
+}
+
+func (proc *AdjustmentProcessor) Flush() []common.TransactionLog {
+
+	tx := proc.apply(proc.transactions)
+	proc.transactions = []common.TransactionLog{}
+
+	if proc.next == nil {
+		return tx
+	}
+
+	proc.next.ProcessMany(tx)
+	return proc.next.Flush()
+
+}
+
+// GetLog returns all applied (or duplicate) adjustments, in the order they were applied,
+// followed by the adjustments that never matched a transaction.
+func (proc *AdjustmentProcessor) GetLog() []common.AdjustmentLog {
+
+	log := append([]common.AdjustmentLog{}, proc.log...)
+
+	for i, adj := range proc.adjustments {
+
+		if !proc.matched[i] {
+			log = append(log, common.AdjustmentLog{Adjustment: adj, Status: common.AdjustmentStatusUnmatched})
+		}
+
+	}
+
+	return log
+}
+
+// GetUnmatched returns the adjustments that has not yet matched any transaction, or
+// for adds, not yet been applied.
+func (proc *AdjustmentProcessor) GetUnmatched() []common.Adjustment {
+
+	unmatched := []common.Adjustment{}
+
+	for i, adj := range proc.adjustments {
+
+		if !proc.matched[i] {
+			unmatched = append(unmatched, adj)
+		}
+
+	}
+
+	return unmatched
+}
+
+func (proc *AdjustmentProcessor) apply(tx []common.TransactionLog) []common.TransactionLog {
+
+	for i, adj := range proc.adjustments {
+
+		switch adj.Action {
+		case common.AdjustmentActionDelete:
+
+			if idx := findTransaction(tx, adj.Exchange, adj.ID); idx != -1 {
+
+				tx = append(tx[:idx], tx[idx+1:]...)
+				proc.record(i, common.AdjustmentStatusApplied)
+
+			}
+
+		case common.AdjustmentActionPatch:
+
+			if idx := findTransaction(tx, adj.Exchange, adj.ID); idx != -1 {
+
+				adj.Patch.Apply(&tx[idx])
+				proc.record(i, common.AdjustmentStatusApplied)
+
+			}
+
+		case common.AdjustmentActionAdd:
+
+			if proc.added[i] || !hasExchange(tx, adj.Exchange) {
+				continue
+			}
+
+			proc.added[i] = true
+
+			if findTransaction(tx, adj.Exchange, adj.ID) != -1 {
+
+				proc.record(i, common.AdjustmentStatusDuplicate)
+				continue
+
+			}
+
+			add := adj.Tx.Clone().(*common.TransactionLog)
+			add.Exchange = adj.Exchange
+			add.ID = adj.ID
+
+			tx = append(tx, *add)
+			proc.record(i, common.AdjustmentStatusApplied)
+
+		}
+
+	}
+
+	return tx
+}
+
+func (proc *AdjustmentProcessor) record(index int, status common.AdjustmentStatus) {
+
+	proc.matched[index] = true
+
+	log := common.AdjustmentLog{Adjustment: proc.adjustments[index], Status: status}
+	proc.log = append(proc.log, log)
+
+	if proc.w != nil {
+		fmt.Fprintf(proc.w, "[adjustment] %s\n", log.String())
+	}
+
+}
+
+func findTransaction(tx []common.TransactionLog, exchange, id string) int {
+
+	for i := range tx {
+
+		if tx[i].Exchange == exchange && tx[i].ID == id {
+			return i
+		}
+
+	}
+
+	return -1
+}
+
+func hasExchange(tx []common.TransactionLog, exchange string) bool {
+
+	for i := range tx {
+
+		if tx[i].Exchange == exchange {
+			return true
+		}
+
+	}
+
+	return false
+}
@@ -0,0 +1,166 @@
+package processors
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdjustmentProcessorAddDeletePatch(t *testing.T) {
+
+	day1, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+	btceur := common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeEuro}
+	fee := 0.5
+
+	var log bytes.Buffer
+
+	proc := NewAdjustmentProcessor(
+		NewChronologicalTxEntryProcessor(),
+		common.Adjustment{
+			Action: common.AdjustmentActionDelete, Exchange: "kr", ID: "dup", Reason: "duplicate of buy",
+		},
+		common.Adjustment{
+			Action: common.AdjustmentActionPatch, Exchange: "kr", ID: "buy", Reason: "wrong fee",
+			Patch: &common.TransactionPatch{Fee: &fee},
+		},
+		common.Adjustment{
+			Action: common.AdjustmentActionAdd, Exchange: "kr", ID: "deposit", Reason: "missing deposit",
+			Tx: &common.TransactionLog{
+				Side: common.SideTypeReceive, CreatedAt: day1, AssetSize: 100, PricePerUnit: 1, TotalPrice: 100,
+				AssetPair: common.AssetPair{Asset: common.AssetTypeEuro, CostUnit: common.AssetTypeEuro},
+			},
+		},
+		common.Adjustment{
+			Action: common.AdjustmentActionDelete, Exchange: "cbx", ID: "nope", Reason: "never imported",
+		},
+	).UseLog(&log)
+
+	proc.Reset()
+	proc.ProcessMany([]common.TransactionLog{
+		{ID: "buy", Exchange: "kr", Side: common.SideTypeBuy, CreatedAt: day1.Add(time.Hour), AssetSize: 1, PricePerUnit: 50, TotalPrice: -50, AssetPair: btceur},
+		{ID: "dup", Exchange: "kr", Side: common.SideTypeBuy, CreatedAt: day1.Add(time.Hour), AssetSize: 1, PricePerUnit: 50, TotalPrice: -50, AssetPair: btceur},
+	})
+
+	tx := proc.Flush()
+
+	require.Equal(t, 2, len(tx))
+	assert.Equal(t, "deposit", tx[0].ID)
+	assert.Equal(t, "kr", tx[0].Exchange)
+	assert.Equal(t, "buy", tx[1].ID)
+	assert.Equal(t, 0.5, tx[1].Fee)
+	assert.Equal(t, -50.5, tx[1].TotalPrice, "fee in cost unit is part of the total")
+
+	entries := proc.GetLog()
+	require.Equal(t, 4, len(entries))
+	assert.Equal(t, common.AdjustmentStatusApplied, entries[0].Status)
+	assert.Equal(t, common.AdjustmentStatusUnmatched, entries[3].Status)
+	assert.Equal(t, "nope", entries[3].ID)
+
+	assert.Equal(t, 1, len(proc.GetUnmatched()))
+	assert.Contains(t, log.String(), "[adjustment] [applied] patch kr/buy fee=0.500000 reason: wrong fee")
+
+	// A second read do not add the transaction again
+	proc.Reset()
+	proc.ProcessMany([]common.TransactionLog{
+		{ID: "other", Exchange: "kr", Side: common.SideTypeBuy, CreatedAt: day1, AssetSize: 1, PricePerUnit: 50, TotalPrice: -50, AssetPair: btceur},
+	})
+
+	tx = proc.Flush()
+	require.Equal(t, 1, len(tx))
+	assert.Equal(t, "other", tx[0].ID)
+}
+
+func TestAdjustmentProcessorReportsDuplicateAdd(t *testing.T) {
+
+	proc := NewAdjustmentProcessor(
+		nil,
+		common.Adjustment{
+			Action: common.AdjustmentActionAdd, Exchange: "kr", ID: "1", Reason: "manual",
+			Tx: &common.TransactionLog{Side: common.SideTypeReceive},
+		},
+	).UseLog(nil)
+
+	proc.Process(common.TransactionLog{ID: "1", Exchange: "kr"})
+
+	tx := proc.Flush()
+	require.Equal(t, 1, len(tx))
+
+	entries := proc.GetLog()
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, common.AdjustmentStatusDuplicate, entries[0].Status)
+}
+
+func TestAdjustmentProcessorAddsOnlyIntoSameExchange(t *testing.T) {
+
+	proc := NewAdjustmentProcessor(
+		nil,
+		common.Adjustment{
+			Action: common.AdjustmentActionAdd, Exchange: "krk", ID: "deposit", Reason: "missing deposit",
+			Tx: &common.TransactionLog{Side: common.SideTypeReceive},
+		},
+	).UseLog(nil)
+
+	// A cbx buffer, e.g. from `ReadBufferAsExchange`, shall not get the krk add
+	proc.Reset()
+	proc.Process(common.TransactionLog{ID: "1", Exchange: "cbx"})
+
+	tx := proc.Flush()
+	require.Equal(t, 1, len(tx))
+	assert.Equal(t, 1, len(proc.GetUnmatched()))
+
+	proc.Reset()
+	proc.Process(common.TransactionLog{ID: "2", Exchange: "krk"})
+
+	tx = proc.Flush()
+	require.Equal(t, 2, len(tx))
+	assert.Equal(t, "deposit", tx[1].ID)
+	assert.Equal(t, "krk", tx[1].Exchange)
+	assert.Equal(t, 0, len(proc.GetUnmatched()))
+
+}
+
+func TestAdjustmentPatchRecalculatesTotal(t *testing.T) {
+
+	size, fee := 2.0, 1.0
+
+	sell := common.TransactionLog{
+		ID: "1", Exchange: "kr", Side: common.SideTypeSell, AssetSize: 1, PricePerUnit: 100,
+		Fee: 0.5, FeeAsset: common.AssetTypeEuro, TotalPrice: 99.5,
+		AssetPair: common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeEuro},
+	}
+
+	patched := sell
+	(&common.TransactionPatch{AssetSize: &size}).Apply(&patched)
+	assert.Equal(t, 199.5, patched.TotalPrice)
+
+	patched = sell
+	(&common.TransactionPatch{Fee: &fee}).Apply(&patched)
+	assert.Equal(t, 99.0, patched.TotalPrice)
+
+	// Fee in another asset is not part of the total
+	patched = sell
+	patched.FeeAsset = common.AssetTypeBNB
+	patched.TotalPrice = 100
+	(&common.TransactionPatch{Fee: &fee}).Apply(&patched)
+	assert.Equal(t, 100.0, patched.TotalPrice)
+
+	// Fee moved to another asset is no longer part of the total
+	bnb := common.AssetTypeBNB
+	patch := &common.TransactionPatch{FeeAsset: &bnb}
+	patched = sell
+	patch.Apply(&patched)
+	assert.Equal(t, common.AssetTypeBNB, patched.FeeAsset)
+	assert.Equal(t, 100.0, patched.TotalPrice)
+	assert.Equal(t, "feeasset=BNB", patch.Fields())
+
+	// A explicit total is kept as is
+	total := 42.0
+	patched = sell
+	(&common.TransactionPatch{AssetSize: &size, TotalPrice: &total}).Apply(&patched)
+	assert.Equal(t, 42.0, patched.TotalPrice)
+
+}
@@ -1,27 +1,45 @@
 package processors
 
 import (
-	"sort"
-	"strconv"
-	"strings"
-
 	"github.com/mariotoffia/gocryptoadmin/common"
 )
 
+// ChronologicalTxEntryProcessor sorts the transactions by `CreatedAt` and, when
+// equal, by the ordering rules (default `DefaultOrderingRules`).
 type ChronologicalTxEntryProcessor struct {
-	tx []common.TransactionLog
+	tx    []common.TransactionLog
+	rules []OrderingRule
 }
 
+// ChronologicalGroupTxEntryProcessor is same as `ChronologicalTxEntryProcessor`
+// but for groups.
 type ChronologicalGroupTxEntryProcessor struct {
-	tx []common.TxGroupEntry
+	tx    []common.TxGroupEntry
+	rules []OrderingRule
 }
 
 func NewChronologicalTxEntryProcessor() *ChronologicalTxEntryProcessor {
-	return &ChronologicalTxEntryProcessor{}
+	return &ChronologicalTxEntryProcessor{rules: DefaultOrderingRules()}
 }
 
 func NewChronologicalGroupTxEntryProcessor() *ChronologicalGroupTxEntryProcessor {
-	return &ChronologicalGroupTxEntryProcessor{}
+	return &ChronologicalGroupTxEntryProcessor{rules: DefaultOrderingRules()}
+}
+
+// UseOrderingRules replaces the tie-break rules, the first rule that can decide is used.
+func (c *ChronologicalTxEntryProcessor) UseOrderingRules(rules ...OrderingRule) *ChronologicalTxEntryProcessor {
+
+	c.rules = rules
+	return c
+
+}
+
+// UseOrderingRules replaces the tie-break rules, the first rule that can decide is used.
+func (c *ChronologicalGroupTxEntryProcessor) UseOrderingRules(rules ...OrderingRule) *ChronologicalGroupTxEntryProcessor {
+
+	c.rules = rules
+	return c
+
 }
 
 func (c *ChronologicalTxEntryProcessor) Reset() {
@@ -44,37 +62,19 @@ func (c *ChronologicalTxEntryProcessor) Process(tx common.TransactionLog) {
 
 func (c *ChronologicalTxEntryProcessor) Flush() []common.TransactionLog {
 
-	sort.Slice(c.tx, func(i, j int) bool {
-
-		if c.tx[i].CreatedAt.Equal(c.tx[j].CreatedAt) {
-
-			if c.tx[i].Exchange == c.tx[j].Exchange {
-
-				if c.tx[i].Exchange == "cbx" &&
-					c.tx[i].AssetPair == c.tx[j].AssetPair &&
-					!strings.HasPrefix(c.tx[i].ID, "M") &&
-					!strings.HasPrefix(c.tx[j].ID, "M") {
-
-					li, err := strconv.ParseInt(c.tx[i].ID, 10, 64)
-					if err != nil {
-						panic(err)
-					}
-
-					lj, err := strconv.ParseInt(c.tx[j].ID, 10, 64)
-					if err != nil {
-						panic(err)
-					}
-
-					return li < lj
-
-				}
-			}
+	items := make([]OrderingItem, len(c.tx))
+	for i := range c.tx {
+		items[i] = OrderingItem{Entry: &c.tx[i], Row: i}
+	}
 
-		}
+	sortChronological(items, c.rules)
 
-		return c.tx[i].CreatedAt.Before(c.tx[j].CreatedAt)
-	})
+	tx := make([]common.TransactionLog, len(items))
+	for i := range items {
+		tx[i] = *items[i].Entry.(*common.TransactionLog)
+	}
 
+	c.tx = tx
 	return c.tx
 }
 
@@ -98,34 +98,18 @@ func (c *ChronologicalGroupTxEntryProcessor) Process(tx common.TxGroupEntry) {
 
 func (c *ChronologicalGroupTxEntryProcessor) Flush() []common.TxGroupEntry {
 
-	sort.Slice(c.tx, func(i, j int) bool {
-
-		if c.tx[i].GetCreatedAt().Equal(c.tx[j].GetCreatedAt()) {
-
-			if c.tx[i].GetExchange() == c.tx[j].GetExchange() {
-
-				if c.tx[i].GetExchange() == "cbx" &&
-					c.tx[i].GetAssetPair().String() == c.tx[j].GetAssetPair().String() {
-
-					li, err := strconv.ParseInt(c.tx[i].ID, 10, 64)
-					if err != nil {
-						panic(err)
-					}
-
-					lj, err := strconv.ParseInt(c.tx[j].ID, 10, 64)
-					if err != nil {
-						panic(err)
-					}
-
-					return li < lj
-
-				}
-			}
+	items := make([]OrderingItem, len(c.tx))
+	for i := range c.tx {
+		items[i] = OrderingItem{Entry: &c.tx[i], Row: i}
+	}
 
-		}
+	sortChronological(items, c.rules)
 
-		return c.tx[i].GetCreatedAt().Before(c.tx[j].GetCreatedAt())
-	})
+	tx := make([]common.TxGroupEntry, len(items))
+	for i := range items {
+		tx[i] = *items[i].Entry.(*common.TxGroupEntry)
+	}
 
+	c.tx = tx
 	return c.tx
 }
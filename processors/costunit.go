@@ -2,6 +2,7 @@ package processors
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/mariotoffia/gocryptoadmin/common"
@@ -18,21 +19,23 @@ type CostUnitProcessor struct {
 	resolver     *txhistory.TxOHCResolver
 	tracked      []common.AssetType
 	priceCalc    PriceEntryCalculator
+	calcName     string
+	trades       *tradePriceIndex
+	leg          TradeLeg
 }
 
+// NewCostUnitProcessor creates a new processor using the _priceCalc_. If _priceCalc_
+// is `nil`, the `PriceCalcMidpoint` is used.
 func NewCostUnitProcessor(
 	resolver *txhistory.TxOHCResolver,
 	priceCalc PriceEntryCalculator,
 ) *CostUnitProcessor {
 
-	if priceCalc == nil {
-
-		priceCalc = func(side common.SideType, entry *txhistory.ResolvedOHCEntry) float64 {
-
-			return (entry.Entry.GetLow() + entry.Entry.GetHigh()) / 2
-
-		}
+	calcName := PriceCalculatorCustom
 
+	if priceCalc == nil {
+		priceCalc = PriceCalcMidpoint
+		calcName = PriceCalculatorMidpoint
 	}
 
 	return &CostUnitProcessor{
@@ -40,22 +43,90 @@ func NewCostUnitProcessor(
 		resolver:     resolver,
 		tracked:      []common.AssetType{},
 		priceCalc:    priceCalc,
+		calcName:     calcName,
 	}
 
 }
 
+// NewCostUnitProcessorByName creates a new processor using the price calculator
+// registered under _name_ (see `GetPriceCalculatorNames`). An empty _name_ selects
+// the default midpoint calculator.
+//
+// It panics if no such calculator is registered.
+func NewCostUnitProcessorByName(
+	resolver *txhistory.TxOHCResolver,
+	name string,
+) *CostUnitProcessor {
+
+	if name == "" {
+		name = PriceCalculatorMidpoint
+	}
+
+	proc := NewCostUnitProcessor(resolver, mustGetPriceCalculator(name))
+	proc.calcName = name
+
+	return proc
+}
+
+// GetPriceCalculatorName returns the name of the price calculator that is
+// recorded in the `common.TranslationProvenance`.
+func (proc *CostUnitProcessor) GetPriceCalculatorName() string {
+	return proc.calcName
+}
+
 func (proc *CostUnitProcessor) RegisterAsset(asset ...common.AssetType) {
 
 	proc.tracked = append(proc.tracked, asset...)
 
 }
 
+// UseTradePrices makes the processor derive conversion rates from the processed
+// transactions themselves. The executed price of a crypto to _FIAT_ trade is used
+// for other transactions within _window_ of that trade. Candles are only used when
+// no such trade exists.
+//
+// When a trade in another _FIAT_ exists, e.g. _LTC-EUR_ when translating to _SEK_,
+// the trade price is used and the rest of the path is resolved using candles.
+//
+// NOTE: `ProcessMany` indexes all submitted trades before translating, whereas
+// `Process` only has the trades processed so far.
+func (proc *CostUnitProcessor) UseTradePrices(window time.Duration) *CostUnitProcessor {
+
+	proc.trades = newTradePriceIndex(window)
+	return proc
+
+}
+
+// UseTradeLeg selects which leg of a crypto to crypto trade that is used when
+// translating, default is `TradeLegCostUnit`. The fee is always translated from
+// the fee asset.
+func (proc *CostUnitProcessor) UseTradeLeg(leg TradeLeg) *CostUnitProcessor {
+
+	proc.leg = leg
+	return proc
+
+}
+
 func (proc *CostUnitProcessor) Reset() {
+
 	proc.transactions = []common.TransactionLog{}
+
+	if proc.trades != nil {
+		proc.trades = newTradePriceIndex(proc.trades.window)
+	}
+
 }
 
 func (proc *CostUnitProcessor) ProcessMany(tx []common.TransactionLog) {
 
+	if proc.trades != nil {
+
+		for i := range tx {
+			proc.trades.add(&tx[i])
+		}
+
+	}
+
 	for i := range tx {
 
 		proc.Process(tx[i])
@@ -66,6 +137,10 @@ func (proc *CostUnitProcessor) ProcessMany(tx []common.TransactionLog) {
 
 func (proc *CostUnitProcessor) Process(tx common.TransactionLog) {
 
+	if proc.trades != nil {
+		proc.trades.add(&tx)
+	}
+
 	for idx, asset := range proc.tracked {
 
 		if tx.TranslatedTotalPrice == nil {
@@ -76,22 +151,25 @@ func (proc *CostUnitProcessor) Process(tx common.TransactionLog) {
 			tx.TranslatedFee = map[string]float64{}
 		}
 
-		if tx.CostUnit == asset {
+		if tx.Provenance == nil {
+			tx.Provenance = map[string]common.TranslationProvenance{}
+		}
+
+		feeAsset := tx.GetFeeAsset()
+
+		if tx.CostUnit == asset && feeAsset == asset {
 
 			tx.TranslatedTotalPrice[string(asset)] = tx.TotalPrice
 			tx.TranslatedFee[string(asset)] = tx.Fee
+			tx.Provenance[string(asset)] = common.TranslationProvenance{
+				TxID: tx.ID, Asset: asset,
+			}
 
 			continue
 
 		}
 
-		entries, ok := proc.resolver.ResolveToTarget(
-			tx.CreatedAt,
-			tx.CostUnit,
-			asset,
-			tx.Exchange,
-			common.ExchangeAll,
-		)
+		hops, ok := proc.resolveHops(&tx, tx.CostUnit, asset)
 
 		if !ok {
 
@@ -110,22 +188,186 @@ func (proc *CostUnitProcessor) Process(tx common.TransactionLog) {
 		tot := tx.TotalPrice
 		fee := tx.Fee
 
-		for _, entry := range entries {
+		for _, hop := range hops {
+
+			tot *= hop.Price
+			fee *= hop.Price
+
+		}
+
+		var feeHops []common.TranslationHop
+
+		if feeAsset != tx.CostUnit {
+
+			feeHops, ok = proc.resolveHops(&tx, feeAsset, asset)
+
+			if !ok {
+
+				panic(
+					fmt.Sprintf(
+						"[index: %d] - could not get asset: %s, via fee asset: %s at %s",
+						idx,
+						asset,
+						feeAsset,
+						tx.CreatedAt.Format(time.RFC3339),
+					),
+				)
+
+			}
 
-			price := proc.priceCalc(tx.Side, &entry)
-			tot *= price
-			fee *= price
+			fee = tx.Fee
+			for _, hop := range feeHops {
+				fee *= hop.Price
+			}
+
+		}
+
+		if proc.leg == TradeLegAsset && isCryptoTrade(&tx) {
+
+			assetHops, ok := proc.resolveHops(&tx, tx.Asset, asset)
+
+			if !ok {
+
+				panic(
+					fmt.Sprintf(
+						"[index: %d] - could not get asset: %s, via asset: %s at %s",
+						idx,
+						asset,
+						tx.Asset,
+						tx.CreatedAt.Format(time.RFC3339),
+					),
+				)
+
+			}
+
+			tot = valueAssetLeg(&tx, assetHops, fee)
+			hops = assetHops
+
+		} else if feeAsset != tx.CostUnit {
+
+			// The fee is not part of the total, but the translated total includes
+			// the fee the same way as when in the cost unit.
+			tot -= fee
 
 		}
 
 		tx.TranslatedTotalPrice[string(asset)] = tot
 		tx.TranslatedFee[string(asset)] = fee
+		tx.Provenance[string(asset)] = common.TranslationProvenance{
+			TxID:       tx.ID,
+			Asset:      asset,
+			Calculator: proc.calcName,
+			Hops:       hops,
+			FeeHops:    feeHops,
+		}
 
 	}
 
 	proc.transactions = append(proc.transactions, tx)
 }
 
+// resolveHops resolves the hops, with prices, needed to translate _from_ into _to_
+// for _tx_.
+//
+// If trade prices are enabled, a trade of _from_ in _to_ is first used, then a
+// trade in any other _FIAT_ where the rest is resolved using candles. Otherwise
+// it will resolve using candles only.
+func (proc *CostUnitProcessor) resolveHops(
+	tx *common.TransactionLog,
+	from, to common.AssetType,
+) ([]common.TranslationHop, bool) {
+
+	if from == to {
+		return []common.TranslationHop{}, true
+	}
+
+	if proc.trades != nil {
+
+		if trade, ok := proc.trades.find(from, to, tx.CreatedAt); ok {
+			return []common.TranslationHop{trade.toHop(from, to)}, true
+		}
+
+		for _, fiat := range proc.trades.costUnits(from) {
+
+			trade, ok := proc.trades.find(from, fiat, tx.CreatedAt)
+			if !ok {
+				continue
+			}
+
+			if rest, ok := proc.resolveCandleHops(tx, fiat, to); ok {
+				return append([]common.TranslationHop{trade.toHop(from, fiat)}, rest...), true
+			}
+
+		}
+
+	}
+
+	return proc.resolveCandleHops(tx, from, to)
+}
+
+func (proc *CostUnitProcessor) resolveCandleHops(
+	tx *common.TransactionLog,
+	from, to common.AssetType,
+) ([]common.TranslationHop, bool) {
+
+	entries, ok := proc.resolver.ResolveToTarget(
+		tx.CreatedAt,
+		from,
+		to,
+		tx.Exchange,
+		common.ExchangeAll,
+	)
+
+	if !ok {
+		return nil, false
+	}
+
+	hops := make([]common.TranslationHop, 0, len(entries))
+
+	for _, entry := range entries {
+
+		hops = append(hops, common.TranslationHop{
+			Exchange:   entry.Exchange,
+			AssetPair:  entry.AssetPair,
+			CandleTime: entry.Entry.GetDateTime(),
+			Resolution: entry.Entry.GetResolution(),
+			Price:      proc.priceCalc(tx.Side, &entry),
+			Source:     common.TranslationSourceCandle,
+		})
+
+	}
+
+	return hops, true
+}
+
+// isCryptoTrade returns `true` when _tx_ is a buy or sell between two crypto currencies.
+func isCryptoTrade(tx *common.TransactionLog) bool {
+
+	return (tx.Side == common.SideTypeBuy || tx.Side == common.SideTypeSell) &&
+		tx.Asset.IsCrypto() && tx.CostUnit.IsCrypto()
+
+}
+
+// valueAssetLeg values the _tx_ by the market value of its asset. The total keeps
+// the sign of `TotalPrice` and includes the already translated _fee_ the same way
+// as the readers do, i.e. added on buy and subtracted on sell.
+func valueAssetLeg(tx *common.TransactionLog, hops []common.TranslationHop, fee float64) float64 {
+
+	value := tx.AssetSize
+
+	for _, hop := range hops {
+		value *= hop.Price
+	}
+
+	if tx.Side == common.SideTypeBuy {
+		value += fee
+	} else {
+		value -= fee
+	}
+
+	return math.Copysign(value, tx.TotalPrice)
+}
+
 func (proc *CostUnitProcessor) Flush() []common.TransactionLog {
 
 	tx := proc.transactions
@@ -0,0 +1,96 @@
+package processors
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/utils"
+)
+
+// BalanceDiagnostic is reported when a transaction makes an asset balance
+// negative, which indicates missing history (e.g. a import that was not done).
+type BalanceDiagnostic struct {
+	Exchange string
+	Asset    common.AssetType
+	At       time.Time
+	// Balance is the balance after _Tx_ was applied.
+	Balance float64
+	// Missing is the amount of _Asset_ that is missing due to _Tx_.
+	Missing float64
+	// Tx is the offending transaction.
+	Tx common.TransactionEntry
+	// SuggestedSides are the likely missing transaction sides.
+	SuggestedSides []common.SideType
+	// Suggestion is a human readable suggestion on what is missing.
+	Suggestion string
+}
+
+func (d BalanceDiagnostic) String() string {
+
+	return fmt.Sprintf(
+		"%s %s %s balance: %f after tx: %s (%s %s) - %s",
+		d.At.Format(time.RFC3339), d.Exchange, d.Asset, d.Balance,
+		d.Tx.GetID(), d.Tx.GetSide(), d.Tx.GetAssetPair().String(), d.Suggestion,
+	)
+
+}
+
+// diagnoseBalance returns a diagnostic if _asset_ got more negative when going from
+// _previous_ to _current_ balance.
+func diagnoseBalance(
+	exchange string,
+	asset common.AssetType,
+	previous, current float64,
+	tx common.TransactionEntry,
+) (BalanceDiagnostic, bool) {
+
+	current = utils.ToFixed(current, 8)
+	if current >= 0 || current >= previous {
+		return BalanceDiagnostic{}, false
+	}
+
+	missing := utils.ToFixed(-current, 8)
+	if previous < 0 {
+		missing = utils.ToFixed(previous-current, 8)
+	}
+
+	sides := []common.SideType{common.SideTypeReceive}
+	if asset.IsCrypto() && tx.GetSide() != common.SideTypeTransfer {
+		sides = []common.SideType{common.SideTypeBuy, common.SideTypeReceive}
+	}
+
+	names := fmt.Sprintf("%s", sides[0])
+	for _, side := range sides[1:] {
+		names += fmt.Sprintf(" or %s", side)
+	}
+
+	return BalanceDiagnostic{
+		Exchange:       exchange,
+		Asset:          asset,
+		At:             tx.GetCreatedAt(),
+		Balance:        current,
+		Missing:        missing,
+		Tx:             tx,
+		SuggestedSides: sides,
+		Suggestion: fmt.Sprintf(
+			"likely missing %s of %f %s on %s before %s",
+			names, missing, asset, exchange, tx.GetCreatedAt().Format(time.RFC3339),
+		),
+	}, true
+}
+
+func sortDiagnostics(list []BalanceDiagnostic) {
+
+	sort.SliceStable(list, func(i, j int) bool {
+
+		if !list[i].At.Equal(list[j].At) {
+			return list[i].At.Before(list[j].At)
+		}
+
+		return list[i].Exchange < list[j].Exchange
+
+	})
+
+}
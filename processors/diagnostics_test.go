@@ -0,0 +1,138 @@
+package processors
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func diagnosticsFixture() []common.TransactionEntry {
+
+	at, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+	btceur := common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeEuro}
+
+	return []common.TransactionEntry{
+		&common.TransactionLog{
+			ID: "deposit", Exchange: "kr", Side: common.SideTypeReceive, CreatedAt: at,
+			AssetSize: 50, PricePerUnit: 1, TotalPrice: 50,
+			AssetPair: common.AssetPair{Asset: common.AssetTypeEuro, CostUnit: common.AssetTypeEuro},
+		},
+		&common.TransactionLog{
+			ID: "buy", Exchange: "kr", Side: common.SideTypeBuy, CreatedAt: at.Add(time.Hour),
+			AssetSize: 1, PricePerUnit: 100, TotalPrice: -100, AssetPair: btceur,
+		},
+		&common.TransactionLog{
+			ID: "sell", Exchange: "kr", Side: common.SideTypeSell, CreatedAt: at.Add(time.Hour * 2),
+			AssetSize: 2, PricePerUnit: 100, TotalPrice: 200, AssetPair: btceur,
+		},
+		&common.TransactionLog{
+			ID: "withdraw", Exchange: "cb", Side: common.SideTypeTransfer, CreatedAt: at.Add(time.Hour * 3),
+			AssetSize: 3, PricePerUnit: 0, TotalPrice: 0,
+			AssetPair: common.AssetPair{Asset: common.AssetTypeLTC, CostUnit: common.AssetTypeEuro},
+		},
+	}
+
+}
+
+func TestAccountingDiagnosticsReportsNegativeBalances(t *testing.T) {
+
+	proc := NewAccountingProcessor("kr").UseDiagnostics()
+	proc.ProcessMany(diagnosticsFixture())
+
+	diag := proc.GetDiagnostics()
+	require.Equal(t, 2, len(diag))
+
+	assert.Equal(t, "buy", diag[0].Tx.GetID())
+	assert.Equal(t, common.AssetTypeEuro, diag[0].Asset)
+	assert.Equal(t, float64(-50), diag[0].Balance)
+	assert.Equal(t, float64(50), diag[0].Missing)
+	assert.Equal(t, []common.SideType{common.SideTypeReceive}, diag[0].SuggestedSides)
+
+	assert.Equal(t, "sell", diag[1].Tx.GetID())
+	assert.Equal(t, common.AssetTypeBTC, diag[1].Asset)
+	assert.Equal(t, float64(1), diag[1].Missing)
+	assert.Equal(
+		t, []common.SideType{common.SideTypeBuy, common.SideTypeReceive}, diag[1].SuggestedSides,
+	)
+	assert.Equal(
+		t, "likely missing BUY or RECEIVE of 1.000000 BTC on kr before 2021-01-01T02:00:00Z",
+		diag[1].Suggestion,
+	)
+}
+
+func TestAccountingDiagnosticsReportsNegativeFeeAsset(t *testing.T) {
+
+	at, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+
+	proc := NewAccountingProcessor("bn").UseDiagnostics()
+	proc.ProcessMany([]common.TransactionEntry{
+		&common.TransactionLog{
+			ID: "deposit", Exchange: "bn", Side: common.SideTypeReceive, CreatedAt: at,
+			AssetSize: 1, PricePerUnit: 1, TotalPrice: 1,
+			AssetPair: common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeBTC},
+		},
+		&common.TransactionLog{
+			ID: "buy", Exchange: "bn", Side: common.SideTypeBuy, CreatedAt: at.Add(time.Hour),
+			AssetSize: 10, PricePerUnit: 0.01, TotalPrice: -0.1, Fee: 0.5, FeeAsset: common.AssetTypeBNB,
+			AssetPair: common.AssetPair{Asset: common.AssetTypeLTC, CostUnit: common.AssetTypeBTC},
+		},
+	})
+
+	diag := proc.GetDiagnostics()
+	require.Equal(t, 1, len(diag))
+
+	assert.Equal(t, "buy", diag[0].Tx.GetID())
+	assert.Equal(t, common.AssetTypeBNB, diag[0].Asset)
+	assert.Equal(t, float64(0.5), diag[0].Missing)
+
+}
+
+func TestAccountingResetClearsDiagnosticsAndBalance(t *testing.T) {
+
+	proc := NewAccountingProcessor("kr").UseDiagnostics()
+
+	for run := 0; run < 2; run++ {
+
+		proc.Reset()
+		proc.ProcessMany(diagnosticsFixture())
+		proc.Flush()
+
+		assert.Equal(t, 2, len(proc.GetDiagnostics()), "run: %d", run)
+		assert.Equal(t, float64(150), proc.GetBalance()[common.AssetTypeEuro], "run: %d", run)
+
+	}
+
+}
+
+func TestMultiAccountingDiagnosticsPerExchange(t *testing.T) {
+
+	proc := NewMultiExchangeAccountingProcessor().UseDiagnostics()
+	proc.ProcessMany(diagnosticsFixture())
+
+	diag := proc.GetDiagnostics(false)
+	require.Equal(t, 3, len(diag))
+
+	assert.Equal(t, "cb", diag[2].Exchange)
+	assert.Equal(t, common.AssetTypeLTC, diag[2].Asset)
+	assert.Equal(t, []common.SideType{common.SideTypeReceive}, diag[2].SuggestedSides)
+
+	assert.Equal(t, 6, len(proc.GetDiagnostics(true)), "all repeats the exchange diagnostics")
+}
+
+func TestDrainBuysPanicNamesOffendingTransaction(t *testing.T) {
+
+	defer func() {
+
+		r := recover()
+		require.NotNil(t, r)
+		assert.Contains(t, fmt.Sprint(r), "(tx: sell SELL on kr at 2021-01-01T02:00:00Z)")
+		assert.Contains(t, fmt.Sprint(r), "likely missing BUY or RECEIVE of 1.000000 BTC")
+
+	}()
+
+	NewTxBuySellProcessor().ProcessMany(diagnosticsFixture()[1:3])
+}
@@ -0,0 +1,91 @@
+package processors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/parsers"
+	"github.com/mariotoffia/gocryptoadmin/txhistory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func feeAssetFixture() []common.TransactionLog {
+
+	day, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+	at := day.Add(time.Hour * 12)
+
+	return []common.TransactionLog{
+		{
+			ID: "deposit", Exchange: "bn", Side: common.SideTypeReceive, CreatedAt: at,
+			AssetSize: 1000, PricePerUnit: 1, TotalPrice: 1000,
+			AssetPair: common.AssetPair{Asset: common.AssetTypeEuro, CostUnit: common.AssetTypeEuro},
+		},
+		{
+			ID: "bnb", Exchange: "bn", Side: common.SideTypeReceive, CreatedAt: at,
+			AssetSize: 1, PricePerUnit: 1, TotalPrice: 1,
+			AssetPair: common.AssetPair{Asset: common.AssetTypeBNB, CostUnit: common.AssetTypeBNB},
+		},
+		{
+			// Fee charged in BNB and hence not part of the total
+			ID: "buy", Exchange: "bn", Side: common.SideTypeBuy, CreatedAt: at.Add(time.Minute),
+			AssetSize: 0.01, PricePerUnit: 20000, TotalPrice: -200, Fee: 0.1, FeeAsset: common.AssetTypeBNB,
+			AssetPair: common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeEuro},
+		},
+	}
+
+}
+
+func TestCostUnitTranslatesFeeFromFeeAsset(t *testing.T) {
+
+	day, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+
+	cache := txhistory.NewTxOHCCache().Add([]common.TxOHCHistory{
+		{
+			Exchange: "bn", Resolution: 1440, DateTime: day,
+			Open: 40, High: 40, Low: 40, Close: 40,
+			AssetPair: common.AssetPair{Asset: common.AssetTypeBNB, CostUnit: common.AssetTypeEuro},
+		},
+	})
+
+	resolver := txhistory.NewTxOHCResolver(cache).AddTranslations(
+		parsers.NewResolverParser().Parse("BNB = bn:EUR").GetExpressions()...,
+	)
+
+	proc := NewCostUnitProcessor(resolver, nil)
+	proc.RegisterAsset(common.AssetTypeEuro)
+	proc.ProcessMany(feeAssetFixture())
+
+	res := proc.Flush()
+	require.Equal(t, 3, len(res))
+
+	buy := res[2]
+	assert.Equal(t, common.AssetTypeBNB, buy.GetFeeAsset())
+	assert.InDelta(t, 4, buy.GetTranslatedFee(common.AssetTypeEuro), 0.0001)
+	assert.InDelta(t, -204, buy.GetTranslatedTotalPrice(common.AssetTypeEuro), 0.0001)
+
+	p := buy.Provenance["EUR"]
+	assert.Equal(t, 0, len(p.Hops))
+	require.Equal(t, 1, len(p.FeeHops))
+	assert.Equal(t, "BNB-EUR", p.FeeHops[0].AssetPair.String())
+
+	// The BNB receive is translated via candles as well
+	assert.InDelta(t, 40, res[1].GetTranslatedTotalPrice(common.AssetTypeEuro), 0.0001)
+}
+
+func TestAccountingDeductsFeeFromFeeAsset(t *testing.T) {
+
+	acc := NewAccountingProcessor("bn")
+
+	for _, tx := range feeAssetFixture() {
+		acc.Process(tx.Clone())
+	}
+
+	entries := acc.Flush()
+	status := entries[len(entries)-1].(common.AccountEntry).GetAccountStatus()
+
+	assert.Equal(t, float64(800), status[common.AssetTypeEuro])
+	assert.Equal(t, 0.01, status[common.AssetTypeBTC])
+	assert.InDelta(t, 0.9, status[common.AssetTypeBNB], 0.0000001)
+}
@@ -0,0 +1,130 @@
+package processors
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventoryCarriesLotsAndBalancesToNextYear(t *testing.T) {
+
+	year1, _ := time.Parse(time.RFC3339, "2020-06-01T00:00:00Z")
+	yearEnd, _ := time.Parse(time.RFC3339, "2020-12-31T23:59:59Z")
+	year2, _ := time.Parse(time.RFC3339, "2021-03-01T00:00:00Z")
+
+	btceur := common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeEuro}
+
+	first := []common.TransactionEntry{
+		&common.TransactionLog{
+			ID: "deposit", Exchange: "kr", Side: common.SideTypeReceive, CreatedAt: year1,
+			AssetSize: 500, PricePerUnit: 1, TotalPrice: 500,
+			AssetPair: common.AssetPair{Asset: common.AssetTypeEuro, CostUnit: common.AssetTypeEuro},
+		},
+		&common.TransactionLog{
+			ID: "buy", Exchange: "kr", Side: common.SideTypeBuy, CreatedAt: year1.Add(time.Hour),
+			AssetSize: 2, PricePerUnit: 100, TotalPrice: -200, AssetPair: btceur,
+		},
+		&common.TransactionLog{
+			ID: "sell1", Exchange: "kr", Side: common.SideTypeSell, CreatedAt: year1.Add(time.Hour * 2),
+			AssetSize: 0.5, PricePerUnit: 120, TotalPrice: 60, AssetPair: btceur,
+		},
+	}
+
+	bs := NewTxBuySellProcessor()
+	bs.ProcessMany(first)
+
+	acc := NewMultiExchangeAccountingProcessor()
+	acc.ProcessMany(first)
+
+	var buff bytes.Buffer
+	require.NoError(t, common.NewInventory(yearEnd, bs.ExportLots(), acc.GetBalances()).Write(&buff))
+
+	_, noPairing := bs.Flush()
+	require.Equal(t, 1, len(noPairing), "export do not remove the lots")
+
+	inv, err := common.ReadInventory(&buff)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(inv.Lots))
+	assert.Equal(t, common.AssetTypeBTC, inv.Lots[0].Queue)
+	assert.Equal(t, 1.5, inv.Lots[0].Tx.AssetSize)
+	assert.Equal(t, float64(360), inv.Balances["kr"][common.AssetTypeEuro])
+
+	// Next year only process the new transactions
+	second := []common.TransactionEntry{
+		&common.TransactionLog{
+			ID: "sell2", Exchange: "kr", Side: common.SideTypeSell, CreatedAt: year2,
+			AssetSize: 1, PricePerUnit: 150, TotalPrice: 150, AssetPair: btceur,
+		},
+	}
+
+	bs = NewTxBuySellProcessor().SeedLots(inv.Lots)
+	bs.ProcessMany(second)
+
+	entries, noPairing := bs.Flush()
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, float64(1), entries[0].GetBuy().GetAssetSize())
+	assert.Equal(t, float64(100), entries[0].GetBuy().GetPricePerUnit())
+	require.Equal(t, 1, len(noPairing))
+	assert.Equal(t, 0.5, noPairing[0].GetAssetSize())
+
+	acc = NewMultiExchangeAccountingProcessor().SeedBalances(inv.Balances, inv.At)
+	acc.ProcessMany(second)
+
+	balances := acc.GetBalances()
+	assert.Equal(t, float64(510), balances["kr"][common.AssetTypeEuro])
+	assert.Equal(t, 0.5, balances["kr"][common.AssetTypeBTC])
+	assert.Equal(t, 0.5, balances[common.ExchangeAll][common.AssetTypeBTC])
+
+	_, err = common.ReadInventory(bytes.NewBufferString(`{"version": 99}`))
+	assert.EqualError(t, err, "unsupported inventory version: 99")
+}
+
+func TestSeededProcessorsKeepSeedsOnReset(t *testing.T) {
+
+	at, _ := time.Parse(time.RFC3339, "2021-03-01T00:00:00Z")
+	btceur := common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeEuro}
+
+	lots := []common.InventoryLot{
+		{
+			Queue: common.AssetTypeBTC,
+			Tx: common.TransactionLog{
+				ID: "buy", Exchange: "kr", Side: common.SideTypeBuy, CreatedAt: at.Add(-time.Hour),
+				AssetSize: 2, PricePerUnit: 100, TotalPrice: -200, AssetPair: btceur,
+			},
+		},
+	}
+
+	sell := &common.TransactionLog{
+		ID: "sell", Exchange: "kr", Side: common.SideTypeSell, CreatedAt: at,
+		AssetSize: 1, PricePerUnit: 150, TotalPrice: 150, AssetPair: btceur,
+	}
+
+	bs := NewTxBuySellProcessor().SeedLots(lots)
+	bs.Process(sell)
+	bs.Reset()
+	bs.Process(sell)
+
+	entries, noPairing := bs.Flush()
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, float64(1), entries[0].GetBuy().GetAssetSize())
+	require.Equal(t, 1, len(noPairing))
+	assert.Equal(t, float64(1), noPairing[0].GetAssetSize())
+
+	acc := NewMultiExchangeAccountingProcessor().SeedBalances(map[string]common.AccountStatus{
+		"kr":               {common.AssetTypeBTC: 2},
+		common.ExchangeAll: {common.AssetTypeBTC: 2},
+	}, at.Add(-time.Hour))
+
+	acc.Process(sell)
+	acc.Reset()
+	acc.Process(sell)
+
+	balances := acc.GetBalances()
+	assert.Equal(t, float64(1), balances["kr"][common.AssetTypeBTC])
+	assert.Equal(t, float64(150), balances["kr"][common.AssetTypeEuro])
+	assert.Equal(t, float64(1), balances[common.ExchangeAll][common.AssetTypeBTC])
+}
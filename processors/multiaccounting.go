@@ -1,6 +1,10 @@
 package processors
 
-import "github.com/mariotoffia/gocryptoadmin/common"
+import (
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+)
 
 // MultiExchangeAccountingProcessor is a multi account processor
 // that processes all and each exchange individual and hence
@@ -10,6 +14,14 @@ import "github.com/mariotoffia/gocryptoadmin/common"
 // It implements the `common.MultiAccountTxProcessor` interface.
 type MultiExchangeAccountingProcessor struct {
 	processors map[string]*AccountingProcessor
+	seeds      map[string]multiAccountSeed
+	diagnose   bool
+}
+
+// multiAccountSeed is a seeded opening balance that is re-applied on `Reset`.
+type multiAccountSeed struct {
+	status common.AccountStatus
+	at     time.Time
 }
 
 // NewMultiExchangeAccountingProcessor creates a new `MultiExchangeAccountingProcessor`.
@@ -19,11 +31,92 @@ func NewMultiExchangeAccountingProcessor() *MultiExchangeAccountingProcessor {
 
 	return &MultiExchangeAccountingProcessor{
 		processors: map[string]*AccountingProcessor{},
+		seeds:      map[string]multiAccountSeed{},
+	}
+}
+
+// UseDiagnostics enables diagnostics on all exchange accounting processors (see
+// `AccountingProcessor.UseDiagnostics`).
+func (m *MultiExchangeAccountingProcessor) UseDiagnostics() *MultiExchangeAccountingProcessor {
+
+	m.diagnose = true
+
+	for _, proc := range m.processors {
+		proc.UseDiagnostics()
+	}
+
+	return m
+}
+
+// GetDiagnostics returns the diagnostics for each exchange, sorted by time. The
+// `common.ExchangeAll` processor is excluded unless _includeAll_ is `true`.
+func (m *MultiExchangeAccountingProcessor) GetDiagnostics(includeAll bool) []BalanceDiagnostic {
+
+	list := []BalanceDiagnostic{}
+
+	for exchange, proc := range m.processors {
+
+		if exchange == common.ExchangeAll && !includeAll {
+			continue
+		}
+
+		list = append(list, proc.GetDiagnostics()...)
+
+	}
+
+	sortDiagnostics(list)
+	return list
+}
+
+// SeedBalances sets the opening balance for each exchange in _balances_ (see
+// `AccountingProcessor.SeedBalance`). The seeds are kept when the processor is `Reset`.
+func (m *MultiExchangeAccountingProcessor) SeedBalances(
+	balances map[string]common.AccountStatus,
+	at time.Time,
+) *MultiExchangeAccountingProcessor {
+
+	for exchange, status := range balances {
+
+		proc := m.processors[exchange]
+		if proc == nil {
+			proc = m.newProcessor(exchange)
+			m.processors[exchange] = proc
+		}
+
+		proc.SeedBalance(status, at)
+		m.seeds[exchange] = multiAccountSeed{status: status, at: at}
+
 	}
+
+	return m
 }
 
+// GetBalances returns the current balance keyed by exchange (and `common.ExchangeAll`).
+func (m *MultiExchangeAccountingProcessor) GetBalances() map[string]common.AccountStatus {
+
+	balances := map[string]common.AccountStatus{}
+
+	for exchange, proc := range m.processors {
+		balances[exchange] = proc.GetBalance()
+	}
+
+	return balances
+}
+
+// Reset clears all processed transactions. Exchanges seeded by `SeedBalances` starts
+// over from their opening balance.
 func (m *MultiExchangeAccountingProcessor) Reset() {
+
 	m.processors = map[string]*AccountingProcessor{}
+
+	for exchange, seed := range m.seeds {
+
+		proc := m.newProcessor(exchange)
+		proc.SeedBalance(seed.status, seed.at)
+		m.processors[exchange] = proc
+
+	}
+
 }
 
 func (m *MultiExchangeAccountingProcessor) ProcessMany(tx []common.TransactionEntry) {
@@ -41,7 +134,7 @@ func (m *MultiExchangeAccountingProcessor) Process(tx common.TransactionEntry) {
 	all := m.processors[common.ExchangeAll]
 	if all == nil {
 
-		all = NewAccountingProcessor(common.ExchangeAll)
+		all = m.newProcessor(common.ExchangeAll)
 		m.processors[common.ExchangeAll] = all
 
 	}
@@ -49,7 +142,7 @@ func (m *MultiExchangeAccountingProcessor) Process(tx common.TransactionEntry) {
 	local := m.processors[tx.GetExchange()]
 	if local == nil {
 
-		local = NewAccountingProcessor(tx.GetExchange())
+		local = m.newProcessor(tx.GetExchange())
 		m.processors[tx.GetExchange()] = local
 
 	}
@@ -72,3 +165,14 @@ func (m *MultiExchangeAccountingProcessor) Flush() map[string][]common.Transacti
 	return r
 
 }
+
+func (m *MultiExchangeAccountingProcessor) newProcessor(exchange string) *AccountingProcessor {
+
+	proc := NewAccountingProcessor(exchange)
+
+	if m.diagnose {
+		proc.UseDiagnostics()
+	}
+
+	return proc
+}
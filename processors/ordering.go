@@ -0,0 +1,318 @@
+package processors
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+)
+
+const (
+	// OrderingRuleExchange orders by exchange name.
+	OrderingRuleExchange = "exchange"
+	// OrderingRuleTradeID orders by numeric trade ID within same exchange and asset pair.
+	OrderingRuleTradeID = "tradeid"
+	// OrderingRuleSide orders by `DefaultSidePriority`.
+	OrderingRuleSide = "side"
+	// OrderingRuleRow orders by the order the entries were processed, i.e. the source
+	// file row order.
+	OrderingRuleRow = "row"
+)
+
+// OrderingItem is a entry to be ordered together with the _Row_, i.e. the index in
+// which it was processed.
+type OrderingItem struct {
+	Entry common.TransactionEntry
+	Row   int
+}
+
+// OrderingRule is a tie-break rule for entries with same `CreatedAt`. It returns a
+// negative value if _a_ should be before _b_, a positive if after and zero if the
+// rule cannot decide.
+type OrderingRule func(a, b *OrderingItem) int
+
+// DefaultSidePriority is buys before sells and receives before transfers.
+var DefaultSidePriority = []common.SideType{
+	common.SideTypeBuy,
+	common.SideTypeReceive,
+	common.SideTypeSell,
+	common.SideTypeTransfer,
+}
+
+var orderingRules = map[string]OrderingRule{
+	OrderingRuleExchange: OrderByExchange,
+	OrderingRuleTradeID:  OrderByTradeID(),
+	OrderingRuleSide:     OrderBySide(DefaultSidePriority...),
+	OrderingRuleRow:      OrderByRow,
+}
+
+// DefaultOrderingRules is numeric trade ID, all other entries keeps the source row order.
+func DefaultOrderingRules() []OrderingRule {
+	return []OrderingRule{OrderByTradeID()}
+}
+
+// RegisterOrderingRule registers (or replaces) a named `OrderingRule` so it can be
+// selected by name, e.g. from configuration.
+func RegisterOrderingRule(name string, rule OrderingRule) {
+	orderingRules[name] = rule
+}
+
+// GetOrderingRule returns the rule registered under _name_.
+func GetOrderingRule(name string) (OrderingRule, bool) {
+
+	rule, ok := orderingRules[name]
+	return rule, ok
+
+}
+
+// GetOrderingRuleNames returns all registered rule names, sorted.
+func GetOrderingRuleNames() []string {
+
+	names := make([]string, 0, len(orderingRules))
+	for name := range orderingRules {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// MustGetOrderingRules returns the rules registered under _names_. It panics if
+// any is not registered.
+func MustGetOrderingRules(names ...string) []OrderingRule {
+
+	rules := make([]OrderingRule, 0, len(names))
+
+	for _, name := range names {
+
+		rule, ok := orderingRules[name]
+		if !ok {
+
+			panic(
+				fmt.Sprintf("unknown ordering rule: %s, available: %v", name, GetOrderingRuleNames()),
+			)
+
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+// OrderByExchange orders by exchange name.
+func OrderByExchange(a, b *OrderingItem) int {
+	return compareStrings(a.Entry.GetExchange(), b.Entry.GetExchange())
+}
+
+// OrderByRow orders by the order the entries were processed.
+func OrderByRow(a, b *OrderingItem) int {
+	return a.Row - b.Row
+}
+
+// OrderByTradeID orders by numeric trade ID when both entries are on same exchange
+// and asset pair. IDs that are not numeric, e.g. _coinbase_ match IDs prefixed with
+// _M_, are ordered after the numeric IDs but not amongst themselves.
+//
+// If _exchange_ is submitted, the rule only applies to those exchanges.
+//
+// NOTE: Since it only decides for some of the entries, do not follow it with `OrderByRow`
+// since that may yield a inconsistent order. Undecided entries keep the row order anyway.
+func OrderByTradeID(exchange ...string) OrderingRule {
+
+	return func(a, b *OrderingItem) int {
+
+		if a.Entry.GetExchange() != b.Entry.GetExchange() ||
+			a.Entry.GetAssetPair() != b.Entry.GetAssetPair() {
+			return 0
+		}
+
+		if len(exchange) > 0 && !containsString(exchange, a.Entry.GetExchange()) {
+			return 0
+		}
+
+		ia, erra := strconv.ParseInt(a.Entry.GetID(), 10, 64)
+		ib, errb := strconv.ParseInt(b.Entry.GetID(), 10, 64)
+
+		switch {
+		case erra != nil && errb != nil:
+			return 0
+		case erra != nil:
+			return 1
+		case errb != nil:
+			return -1
+		case ia < ib:
+			return -1
+		case ia > ib:
+			return 1
+		}
+
+		return 0
+	}
+
+}
+
+// OrderBySide orders by the position of the side in _priority_. Sides not in
+// _priority_ are ordered last.
+func OrderBySide(priority ...common.SideType) OrderingRule {
+
+	index := func(side common.SideType) int {
+
+		for i := range priority {
+
+			if priority[i] == side {
+				return i
+			}
+
+		}
+
+		return len(priority)
+	}
+
+	return func(a, b *OrderingItem) int {
+		return index(a.Entry.GetSide()) - index(b.Entry.GetSide())
+	}
+
+}
+
+// sortChronological sorts the _items_ by `CreatedAt` and, when equal, by the rules
+// (see `orderTies`).
+func sortChronological(items []OrderingItem, rules []OrderingRule) {
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Entry.GetCreatedAt().Before(items[j].Entry.GetCreatedAt())
+	})
+
+	for start := 0; start < len(items); {
+
+		end := start + 1
+		for end < len(items) && items[end].Entry.GetCreatedAt().Equal(items[start].Entry.GetCreatedAt()) {
+			end++
+		}
+
+		if end-start > 1 && len(rules) > 0 {
+			orderTies(items[start:end], rules)
+		}
+
+		start = end
+
+	}
+
+}
+
+// orderTies orders _items_, with same `CreatedAt`, so that a item the first deciding
+// rule puts before another comes first. Otherwise, the lowest row comes first.
+//
+// The rules may only decide for some pairs, e.g. `OrderByTradeID`, and hence are not
+// a total order that a comparison sort requires. Instead, this is a topological sort
+// where the lowest row, not preceded by any remaining item, is picked next. If the rules
+// are contradicting, the lowest row remaining is picked.
+func orderTies(items []OrderingItem, rules []OrderingRule) {
+
+	n := len(items)
+	before := make([][]bool, n)
+
+	for i := range items {
+
+		before[i] = make([]bool, n)
+
+		for j := range items {
+
+			if i == j {
+				continue
+			}
+
+			for _, rule := range rules {
+
+				if c := rule(&items[i], &items[j]); c != 0 {
+					before[i][j] = c < 0
+					break
+				}
+
+			}
+
+		}
+
+	}
+
+	preceded := make([]int, n)
+	for i := range items {
+
+		for j := range items {
+
+			if before[i][j] {
+				preceded[j]++
+			}
+
+		}
+
+	}
+
+	picked := make([]bool, n)
+	ordered := make([]OrderingItem, 0, n)
+
+	for len(ordered) < n {
+
+		next, fallback := -1, -1
+
+		for i := range items {
+
+			if picked[i] {
+				continue
+			}
+
+			if fallback == -1 || items[i].Row < items[fallback].Row {
+				fallback = i
+			}
+
+			if preceded[i] == 0 && (next == -1 || items[i].Row < items[next].Row) {
+				next = i
+			}
+
+		}
+
+		if next == -1 {
+			next = fallback
+		}
+
+		picked[next] = true
+		ordered = append(ordered, items[next])
+
+		for j := range items {
+
+			if before[next][j] {
+				preceded[j]--
+			}
+
+		}
+
+	}
+
+	copy(items, ordered)
+}
+
+func compareStrings(a, b string) int {
+
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+func containsString(list []string, s string) bool {
+
+	for i := range list {
+
+		if list[i] == s {
+			return true
+		}
+
+	}
+
+	return false
+}
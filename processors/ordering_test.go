@@ -0,0 +1,106 @@
+package processors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func orderingTestTx() []common.TransactionLog {
+
+	at, _ := time.Parse(time.RFC3339, "2021-01-01T10:00:00Z")
+	btceur := common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeEuro}
+
+	return []common.TransactionLog{
+		{ID: "12", Exchange: "coinbase", Side: common.SideTypeSell, CreatedAt: at, AssetPair: btceur},
+		{ID: "9", Exchange: "coinbase", Side: common.SideTypeBuy, CreatedAt: at, AssetPair: btceur},
+		{ID: "M-1", Exchange: "coinbase", Side: common.SideTypeTransfer, CreatedAt: at, AssetPair: common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeBTC}},
+		{ID: "a", Exchange: "bitstamp", Side: common.SideTypeReceive, CreatedAt: at, AssetPair: btceur},
+		{ID: "first", Exchange: "kraken", Side: common.SideTypeSell, CreatedAt: at.Add(-time.Hour), AssetPair: btceur},
+	}
+
+}
+
+func ids(tx []common.TransactionLog) []string {
+
+	list := make([]string, len(tx))
+	for i := range tx {
+		list[i] = tx[i].ID
+	}
+
+	return list
+}
+
+func TestChronologicalDefaultOrderingIsTradeIDThenRow(t *testing.T) {
+
+	proc := NewChronologicalTxEntryProcessor()
+	proc.ProcessMany(orderingTestTx())
+
+	// Numeric IDs is ordered regardless of exchange name, the rest keeps row order.
+	assert.Equal(t, []string{"first", "9", "12", "M-1", "a"}, ids(proc.Flush()))
+}
+
+func TestChronologicalTradeIDOrderingWithUndecidedInBetween(t *testing.T) {
+
+	at, _ := time.Parse(time.RFC3339, "2021-01-01T10:00:00Z")
+	btceur := common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeEuro}
+
+	proc := NewChronologicalTxEntryProcessor()
+	proc.ProcessMany([]common.TransactionLog{
+		{ID: "12", Exchange: "coinbase", CreatedAt: at, AssetPair: btceur},
+		{ID: "a", Exchange: "bitstamp", CreatedAt: at, AssetPair: btceur},
+		{ID: "M-1", Exchange: "coinbase", CreatedAt: at, AssetPair: btceur},
+		{ID: "9", Exchange: "coinbase", CreatedAt: at, AssetPair: btceur},
+	})
+
+	// Undecided, by the rule, in between may not break the numeric order nor numeric before non-numeric
+	assert.Equal(t, []string{"a", "9", "12", "M-1"}, ids(proc.Flush()))
+}
+
+func TestChronologicalOrderingRulesByName(t *testing.T) {
+
+	proc := NewChronologicalTxEntryProcessor().
+		UseOrderingRules(MustGetOrderingRules(OrderingRuleExchange, OrderingRuleSide)...)
+
+	proc.ProcessMany(orderingTestTx())
+
+	assert.Equal(t, []string{"first", "a", "9", "12", "M-1"}, ids(proc.Flush()))
+
+	proc = NewChronologicalTxEntryProcessor().UseOrderingRules(OrderByRow)
+	proc.ProcessMany(orderingTestTx())
+
+	assert.Equal(t, []string{"first", "12", "9", "M-1", "a"}, ids(proc.Flush()))
+}
+
+func TestChronologicalGroupOrderingRules(t *testing.T) {
+
+	groups := []common.TxGroupEntry{}
+	for _, tx := range orderingTestTx() {
+
+		group := common.TxGroupEntry{TransactionLog: common.TransactionLog{ID: tx.ID}}
+		group.AddTransactionEntry(tx.Clone())
+		groups = append(groups, group)
+
+	}
+
+	proc := NewChronologicalGroupTxEntryProcessor().
+		UseOrderingRules(OrderBySide(common.SideTypeReceive, common.SideTypeBuy))
+
+	proc.ProcessMany(groups)
+
+	result := proc.Flush()
+	require.Equal(t, 5, len(result))
+
+	assert.Equal(t, "first", result[0].ID)
+	assert.Equal(t, "a", result[1].ID)
+	assert.Equal(t, "9", result[2].ID)
+	assert.Equal(t, "12", result[3].ID)
+	assert.Equal(t, "M-1", result[4].ID)
+}
+
+func TestUnknownOrderingRulePanics(t *testing.T) {
+	assert.Panics(t, func() { MustGetOrderingRules("nope") })
+}
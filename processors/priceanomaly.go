@@ -0,0 +1,218 @@
+package processors
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/txhistory"
+)
+
+// PriceAnomaly is a transaction where the `PricePerUnit` is outside of the
+// tolerance band of the market candle.
+type PriceAnomaly struct {
+	Tx common.TransactionLog
+	// Exchange is where the candle was found.
+	Exchange string
+	// CandleTime is the start of the candle.
+	CandleTime time.Time
+	// Low is the lowest expected price (candle low minus tolerance).
+	Low float64
+	// High is the highest expected price (candle high plus tolerance).
+	High float64
+	// Deviation is how much, relative to the closest bound, the price is off. E.g.
+	// 9.0 when the price is ten times the upper bound (misplaced decimal point).
+	Deviation float64
+}
+
+// PriceAnomalyProcessor implements `common.TxLogProcessor` and checks each
+// transaction _BUY_ or _SELL_ price against the candle range in the `txhistory.TxOHCCache`.
+//
+// The transactions are passed through unmodified. Anomalies are collected and may be
+// retrieved by `GetAnomalies` or written using `WriteReport`. Transactions where no
+// candle was found are available in `GetUnchecked`.
+//
+// If the asset pair do not exist, it will try the inverted pair, e.g. _EUR-BTC_ when
+// _BTC-EUR_ is missing.
+type PriceAnomalyProcessor struct {
+	cache        *txhistory.TxOHCCache
+	tolerance    float64
+	exchanges    []string
+	transactions []common.TransactionLog
+	anomalies    []PriceAnomaly
+	unchecked    []common.TransactionLog
+}
+
+// NewPriceAnomalyProcessor creates a new processor where _tolerance_ is the fraction
+// the price may be outside of the candle low and high, e.g. 0.05 is five percent.
+func NewPriceAnomalyProcessor(
+	cache *txhistory.TxOHCCache,
+	tolerance float64,
+) *PriceAnomalyProcessor {
+
+	return &PriceAnomalyProcessor{
+		cache:        cache,
+		tolerance:    tolerance,
+		exchanges:    []string{common.ExchangeAll},
+		transactions: []common.TransactionLog{},
+		anomalies:    []PriceAnomaly{},
+		unchecked:    []common.TransactionLog{},
+	}
+
+}
+
+// UseExchanges sets the fallback exchanges to look for candles in when the candles
+// do not exist for the transaction exchange. Default is `common.ExchangeAll`.
+func (proc *PriceAnomalyProcessor) UseExchanges(exchange ...string) *PriceAnomalyProcessor {
+
+	proc.exchanges = exchange
+	return proc
+
+}
+
+func (proc *PriceAnomalyProcessor) Reset() {
+
+	proc.transactions = []common.TransactionLog{}
+	proc.anomalies = []PriceAnomaly{}
+	proc.unchecked = []common.TransactionLog{}
+
+}
+
+func (proc *PriceAnomalyProcessor) ProcessMany(tx []common.TransactionLog) {
+
+	for i := range tx {
+		proc.Process(tx[i])
+	}
+
+}
+
+func (proc *PriceAnomalyProcessor) Process(tx common.TransactionLog) {
+
+	proc.transactions = append(proc.transactions, tx)
+
+	if tx.Side != common.SideTypeBuy && tx.Side != common.SideTypeSell {
+		return
+	}
+
+	if tx.PricePerUnit <= 0 || tx.Asset == tx.CostUnit {
+		return
+	}
+
+	exchanges := append([]string{tx.Exchange}, proc.exchanges...)
+
+	low, high, entry, exchange, ok := proc.candleRange(tx.AssetPair, tx.CreatedAt, exchanges)
+	if !ok {
+
+		proc.unchecked = append(proc.unchecked, tx)
+		return
+
+	}
+
+	low *= 1 - proc.tolerance
+	high *= 1 + proc.tolerance
+
+	if tx.PricePerUnit >= low && tx.PricePerUnit <= high {
+		return
+	}
+
+	deviation := tx.PricePerUnit/high - 1
+	if tx.PricePerUnit < low {
+		deviation = tx.PricePerUnit/low - 1
+	}
+
+	proc.anomalies = append(proc.anomalies, PriceAnomaly{
+		Tx:         tx,
+		Exchange:   exchange,
+		CandleTime: entry.DateTime,
+		Low:        low,
+		High:       high,
+		Deviation:  deviation,
+	})
+
+}
+
+func (proc *PriceAnomalyProcessor) Flush() []common.TransactionLog {
+
+	tx := proc.transactions
+	proc.transactions = []common.TransactionLog{}
+
+	return tx
+
+}
+
+// GetAnomalies returns all transactions found to be outside of the tolerance band.
+func (proc *PriceAnomalyProcessor) GetAnomalies() []PriceAnomaly {
+	return proc.anomalies
+}
+
+// GetUnchecked returns all transactions that could not be checked since no candle
+// was found.
+func (proc *PriceAnomalyProcessor) GetUnchecked() []common.TransactionLog {
+	return proc.unchecked
+}
+
+// WriteReport writes a human readable report of all anomalies and unchecked
+// transactions. If _w_ is `nil`, `os.Stdout` is used.
+func (proc *PriceAnomalyProcessor) WriteReport(w io.Writer) {
+
+	if w == nil {
+		w = os.Stdout
+	}
+
+	fmt.Fprintf(
+		w, "Price anomalies: %d (tolerance %.2f%%)\n",
+		len(proc.anomalies), proc.tolerance*100,
+	)
+
+	for _, a := range proc.anomalies {
+
+		fmt.Fprintf(
+			w, "%s %s %s %s %s price: %f expected: %f - %f (%s %s) deviation: %.2f%%\n",
+			a.Tx.CreatedAt.Format(time.RFC3339), a.Tx.Exchange, a.Tx.ID, a.Tx.Side,
+			a.Tx.AssetPair.String(), a.Tx.PricePerUnit, a.Low, a.High,
+			a.Exchange, a.CandleTime.Format(time.RFC3339), a.Deviation*100,
+		)
+
+	}
+
+	if len(proc.unchecked) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "Unchecked (no candle): %d\n", len(proc.unchecked))
+
+	for _, tx := range proc.unchecked {
+
+		fmt.Fprintf(
+			w, "%s %s %s %s %s price: %f\n",
+			tx.CreatedAt.Format(time.RFC3339), tx.Exchange, tx.ID, tx.Side,
+			tx.AssetPair.String(), tx.PricePerUnit,
+		)
+
+	}
+
+}
+
+// candleRange returns the low and high of the candle for _pair_ at _at_. If not found
+// it will try the inverted pair.
+func (proc *PriceAnomalyProcessor) candleRange(
+	pair common.AssetPair,
+	at time.Time,
+	exchanges []string,
+) (float64, float64, *common.TxOHCHistory, string, bool) {
+
+	if entry, exchange := proc.cache.GetEntryForAssset(pair, at, exchanges...); entry != nil {
+		return entry.Low, entry.High, entry, exchange, true
+	}
+
+	inverted := common.AssetPair{Asset: pair.CostUnit, CostUnit: pair.Asset}
+
+	entry, exchange := proc.cache.GetEntryForAssset(inverted, at, exchanges...)
+	if entry == nil || entry.Low <= 0 || entry.High <= 0 {
+		return 0, 0, nil, "", false
+	}
+
+	return 1 / entry.High, 1 / entry.Low, entry, exchange, true
+}
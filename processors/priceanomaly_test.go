@@ -0,0 +1,76 @@
+package processors
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/txhistory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceAnomalyFlagsMisplacedDecimalPoint(t *testing.T) {
+
+	day, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+
+	cache := txhistory.NewTxOHCCache().Add([]common.TxOHCHistory{{
+		Exchange:   "kr",
+		Resolution: 1440,
+		DateTime:   day,
+		Open:       100, High: 110, Low: 90, Close: 105,
+		AssetPair: common.AssetPair{Asset: common.AssetTypeLTC, CostUnit: common.AssetTypeEuro},
+	}})
+
+	tx := func(id string, price float64, pair common.AssetPair) common.TransactionLog {
+
+		return common.TransactionLog{
+			ID: id, Exchange: "kr", Side: common.SideTypeBuy,
+			CreatedAt: day.Add(time.Hour), AssetSize: 1, PricePerUnit: price, AssetPair: pair,
+		}
+
+	}
+
+	ltceur := common.AssetPair{Asset: common.AssetTypeLTC, CostUnit: common.AssetTypeEuro}
+	eurltc := common.AssetPair{Asset: common.AssetTypeEuro, CostUnit: common.AssetTypeLTC}
+	btceur := common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeEuro}
+
+	proc := NewPriceAnomalyProcessor(cache, 0.1)
+	proc.ProcessMany([]common.TransactionLog{
+		tx("ok", 115, ltceur),
+		tx("tenfold", 1050, ltceur),
+		tx("low", 80, ltceur),
+		tx("inverted", 0.01, eurltc),
+		tx("inverted-bad", 0.1, eurltc),
+		tx("missing", 20000, btceur),
+	})
+
+	assert.Equal(t, 6, len(proc.Flush()), "all transactions are passed through")
+
+	anomalies := proc.GetAnomalies()
+	require.Equal(t, 3, len(anomalies))
+
+	assert.Equal(t, "tenfold", anomalies[0].Tx.ID)
+	assert.InDelta(t, 81, anomalies[0].Low, 0.0001)
+	assert.InDelta(t, 121, anomalies[0].High, 0.0001)
+	assert.Equal(t, "kr", anomalies[0].Exchange)
+	assert.InDelta(t, 1050.0/121-1, anomalies[0].Deviation, 0.0001)
+
+	assert.Equal(t, "low", anomalies[1].Tx.ID)
+	assert.True(t, anomalies[1].Deviation < 0)
+
+	assert.Equal(t, "inverted-bad", anomalies[2].Tx.ID)
+
+	require.Equal(t, 1, len(proc.GetUnchecked()))
+	assert.Equal(t, "missing", proc.GetUnchecked()[0].ID)
+
+	var buff bytes.Buffer
+	proc.WriteReport(&buff)
+
+	report := buff.String()
+	assert.True(t, strings.HasPrefix(report, "Price anomalies: 3 (tolerance 10.00%)\n"))
+	assert.Contains(t, report, "tenfold BUY LTC-EUR price: 1050.000000 expected: 81.000000 - 121.000000")
+	assert.Contains(t, report, "Unchecked (no candle): 1\n")
+}
@@ -0,0 +1,194 @@
+package processors
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/txhistory"
+)
+
+const (
+	// PriceCalculatorMidpoint is (low + high) / 2 and is the default.
+	PriceCalculatorMidpoint = "midpoint"
+	// PriceCalculatorOpen is the candle open price.
+	PriceCalculatorOpen = "open"
+	// PriceCalculatorClose is the candle close price.
+	PriceCalculatorClose = "close"
+	// PriceCalculatorTypical is (high + low + close) / 3.
+	PriceCalculatorTypical = "typical"
+	// PriceCalculatorVWAP is the cost unit volume / asset volume.
+	PriceCalculatorVWAP = "vwap"
+	// PriceCalculatorInterpolated interpolates linearly between adjacent candles.
+	PriceCalculatorInterpolated = "interpolated"
+	// PriceCalculatorConservative buys at high and sells at low.
+	PriceCalculatorConservative = "conservative"
+	// PriceCalculatorCustom is recorded when a unnamed `PriceEntryCalculator` is used.
+	PriceCalculatorCustom = "custom"
+)
+
+var priceCalculators = map[string]PriceEntryCalculator{
+	PriceCalculatorMidpoint:     PriceCalcMidpoint,
+	PriceCalculatorOpen:         PriceCalcOpen,
+	PriceCalculatorClose:        PriceCalcClose,
+	PriceCalculatorTypical:      PriceCalcTypical,
+	PriceCalculatorVWAP:         PriceCalcVWAP,
+	PriceCalculatorInterpolated: PriceCalcInterpolated,
+	PriceCalculatorConservative: SideAwarePriceCalc(
+		PriceCalcHigh, PriceCalcLow, PriceCalcMidpoint,
+	),
+}
+
+// RegisterPriceCalculator registers (or replaces) a named `PriceEntryCalculator`
+// so it can be selected by name, e.g. from configuration.
+func RegisterPriceCalculator(name string, calc PriceEntryCalculator) {
+	priceCalculators[name] = calc
+}
+
+// GetPriceCalculator returns the calculator registered under _name_.
+func GetPriceCalculator(name string) (PriceEntryCalculator, bool) {
+
+	calc, ok := priceCalculators[name]
+	return calc, ok
+
+}
+
+// GetPriceCalculatorNames returns all registered calculator names, sorted.
+func GetPriceCalculatorNames() []string {
+
+	names := make([]string, 0, len(priceCalculators))
+	for name := range priceCalculators {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// PriceCalcMidpoint returns (low + high) / 2.
+func PriceCalcMidpoint(side common.SideType, entry *txhistory.ResolvedOHCEntry) float64 {
+	return (entry.Entry.GetLow() + entry.Entry.GetHigh()) / 2
+}
+
+// PriceCalcOpen returns the open price.
+func PriceCalcOpen(side common.SideType, entry *txhistory.ResolvedOHCEntry) float64 {
+	return entry.Entry.GetOpen()
+}
+
+// PriceCalcClose returns the close price.
+func PriceCalcClose(side common.SideType, entry *txhistory.ResolvedOHCEntry) float64 {
+	return entry.Entry.GetClose()
+}
+
+// PriceCalcHigh returns the high price.
+func PriceCalcHigh(side common.SideType, entry *txhistory.ResolvedOHCEntry) float64 {
+	return entry.Entry.GetHigh()
+}
+
+// PriceCalcLow returns the low price.
+func PriceCalcLow(side common.SideType, entry *txhistory.ResolvedOHCEntry) float64 {
+	return entry.Entry.GetLow()
+}
+
+// PriceCalcTypical returns the typical price (high + low + close) / 3.
+func PriceCalcTypical(side common.SideType, entry *txhistory.ResolvedOHCEntry) float64 {
+
+	e := entry.Entry
+	return (e.GetHigh() + e.GetLow() + e.GetClose()) / 3
+
+}
+
+// PriceCalcVWAP returns the volume weighted average price, i.e. the cost unit
+// volume divided by the asset volume.
+//
+// If the candle lacks volumes (not all exchanges reports the cost unit volume),
+// the typical price is returned.
+func PriceCalcVWAP(side common.SideType, entry *txhistory.ResolvedOHCEntry) float64 {
+
+	e := entry.Entry
+
+	if e.GetVolumeAsset() > 0 && e.GetVolumeCostUnit() > 0 {
+		return e.GetVolumeCostUnit() / e.GetVolumeAsset()
+	}
+
+	return PriceCalcTypical(side, entry)
+}
+
+// PriceCalcInterpolated interpolates the price linearly at the resolved point in time.
+//
+// When the next candle is known, it interpolates between the open of the resolved
+// candle and the open of the next. Otherwise it interpolates between open and close
+// of the resolved candle. If no point in time was resolved, the open is returned.
+func PriceCalcInterpolated(side common.SideType, entry *txhistory.ResolvedOHCEntry) float64 {
+
+	e := entry.Entry
+	start := e.GetDateTime()
+
+	if entry.At.IsZero() {
+		return e.GetOpen()
+	}
+
+	if entry.Next != nil && entry.Next.GetDateTime().After(start) {
+
+		return interpolate(
+			start, e.GetOpen(), entry.Next.GetDateTime(), entry.Next.GetOpen(), entry.At,
+		)
+
+	}
+
+	end := start.Add(time.Duration(e.GetResolution()) * time.Minute)
+	return interpolate(start, e.GetOpen(), end, e.GetClose(), entry.At)
+}
+
+// SideAwarePriceCalc selects calculator based on the transaction side. The _other_
+// is used for all sides that is neither buy nor sell.
+func SideAwarePriceCalc(buy, sell, other PriceEntryCalculator) PriceEntryCalculator {
+
+	return func(side common.SideType, entry *txhistory.ResolvedOHCEntry) float64 {
+
+		switch side {
+		case common.SideTypeBuy:
+			return buy(side, entry)
+		case common.SideTypeSell:
+			return sell(side, entry)
+		}
+
+		return other(side, entry)
+	}
+
+}
+
+// interpolate returns the linear value at _at_ between (_t0_, _v0_) and (_t1_, _v1_),
+// clamped to the interval.
+func interpolate(t0 time.Time, v0 float64, t1 time.Time, v1 float64, at time.Time) float64 {
+
+	span := t1.Sub(t0)
+	if span <= 0 {
+		return v0
+	}
+
+	f := float64(at.Sub(t0)) / float64(span)
+
+	if f < 0 {
+		f = 0
+	} else if f > 1 {
+		f = 1
+	}
+
+	return v0 + (v1-v0)*f
+}
+
+func mustGetPriceCalculator(name string) PriceEntryCalculator {
+
+	calc, ok := GetPriceCalculator(name)
+	if !ok {
+
+		panic(
+			fmt.Sprintf("unknown price calculator: %s, available: %v", name, GetPriceCalculatorNames()),
+		)
+
+	}
+
+	return calc
+}
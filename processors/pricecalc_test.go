@@ -0,0 +1,152 @@
+package processors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/parsers"
+	"github.com/mariotoffia/gocryptoadmin/txhistory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resolvedEntry(at string) *txhistory.ResolvedOHCEntry {
+
+	start, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+	t, _ := time.Parse(time.RFC3339, at)
+
+	return &txhistory.ResolvedOHCEntry{
+		Entry: &common.TxOHCHistory{
+			Resolution:     1440,
+			DateTime:       start,
+			Open:           100,
+			High:           130,
+			Low:            90,
+			Close:          110,
+			AssetVolume:    2,
+			CostUnitVolume: 210,
+		},
+		Next: &common.TxOHCHistory{
+			Resolution: 1440,
+			DateTime:   start.AddDate(0, 0, 1),
+			Open:       120,
+		},
+		At: t,
+	}
+
+}
+
+func TestNamedPriceCalculators(t *testing.T) {
+
+	entry := resolvedEntry("2021-01-01T06:00:00Z")
+
+	expected := map[string]float64{
+		PriceCalculatorMidpoint:     110,
+		PriceCalculatorOpen:         100,
+		PriceCalculatorClose:        110,
+		PriceCalculatorTypical:      110,
+		PriceCalculatorVWAP:         105,
+		PriceCalculatorInterpolated: 105,
+	}
+
+	for name, price := range expected {
+
+		calc, ok := GetPriceCalculator(name)
+		require.True(t, ok, name)
+		assert.InDelta(t, price, calc(common.SideTypeBuy, entry), 0.0001, name)
+
+	}
+
+}
+
+func TestVWAPFallsBackToTypicalWithoutVolume(t *testing.T) {
+
+	entry := resolvedEntry("2021-01-01T06:00:00Z")
+	entry.Entry.(*common.TxOHCHistory).CostUnitVolume = 0
+
+	assert.Equal(t, float64(110), PriceCalcVWAP(common.SideTypeBuy, entry))
+}
+
+func TestInterpolatedWithoutNextUsesClose(t *testing.T) {
+
+	entry := resolvedEntry("2021-01-01T12:00:00Z")
+	entry.Next = nil
+
+	assert.Equal(t, float64(105), PriceCalcInterpolated(common.SideTypeSell, entry))
+}
+
+func TestConservativeIsSideAware(t *testing.T) {
+
+	entry := resolvedEntry("2021-01-01T06:00:00Z")
+	calc, _ := GetPriceCalculator(PriceCalculatorConservative)
+
+	assert.Equal(t, float64(130), calc(common.SideTypeBuy, entry))
+	assert.Equal(t, float64(90), calc(common.SideTypeSell, entry))
+	assert.Equal(t, float64(110), calc(common.SideTypeReceive, entry))
+}
+
+func TestUnknownPriceCalculatorPanics(t *testing.T) {
+
+	assert.Panics(t, func() {
+		NewCostUnitProcessorByName(nil, "no-such-calculator")
+	})
+
+	assert.NotPanics(t, func() {
+		NewCostUnitProcessorByName(nil, "")
+	})
+
+}
+
+func TestCostUnitProcessorRecordsProvenance(t *testing.T) {
+
+	day, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+
+	cache := txhistory.NewTxOHCCache().Add([]common.TxOHCHistory{{
+		Exchange:   "ecb",
+		Resolution: 1440,
+		DateTime:   day,
+		Open:       10, High: 10, Low: 10, Close: 10,
+		AssetPair: common.AssetPair{
+			Asset:    common.AssetTypeEuro,
+			CostUnit: common.AssetTypeSvenskKrona,
+		},
+	}})
+
+	resolver := txhistory.NewTxOHCResolver(cache).AddTranslations(
+		parsers.NewResolverParser().Parse("EUR = ecb:SEK").GetExpressions()...,
+	)
+
+	proc := NewCostUnitProcessorByName(resolver, PriceCalculatorClose)
+	proc.RegisterAsset(common.AssetTypeEuro, common.AssetTypeSvenskKrona)
+
+	proc.Process(common.TransactionLog{
+		ID:         "tx1",
+		Side:       common.SideTypeBuy,
+		CreatedAt:  day.Add(time.Hour),
+		TotalPrice: 100,
+		AssetPair: common.AssetPair{
+			Asset:    common.AssetTypeBTC,
+			CostUnit: common.AssetTypeEuro,
+		},
+	})
+
+	tx := proc.Flush()
+	require.Equal(t, 1, len(tx))
+	assert.Equal(t, float64(1000), tx[0].GetTranslatedTotalPrice(common.AssetTypeSvenskKrona))
+
+	eur := tx[0].GetProvenance(common.AssetTypeEuro)
+	require.Equal(t, 1, len(eur))
+	assert.Equal(t, 0, len(eur[0].Hops))
+
+	sek := tx[0].GetProvenance(common.AssetTypeSvenskKrona)
+	require.Equal(t, 1, len(sek))
+	assert.Equal(t, "tx1", sek[0].TxID)
+	assert.Equal(t, PriceCalculatorClose, sek[0].Calculator)
+	require.Equal(t, 1, len(sek[0].Hops))
+	assert.Equal(t, "ecb", sek[0].Hops[0].Exchange)
+	assert.Equal(t, "EUR-SEK", sek[0].Hops[0].AssetPair.String())
+	assert.Equal(t, day, sek[0].Hops[0].CandleTime)
+	assert.Equal(t, 1440, sek[0].Hops[0].Resolution)
+	assert.Equal(t, float64(10), sek[0].Hops[0].Price)
+}
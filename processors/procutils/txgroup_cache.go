@@ -29,7 +29,7 @@ func NewTxGroupCache(timewindow time.Duration, render CacheKeyRenderer) *TxGroup
 		render = func(tx common.TransactionEntry, side common.SideType) string {
 
 			return fmt.Sprintf(
-				"%s_%s_%s", tx.GetExchange(), tx.GetAssetPair(), side,
+				"%s_%s_%s_%s", tx.GetExchange(), tx.GetAssetPair(), side, tx.GetFeeAsset(),
 			)
 
 		}
@@ -0,0 +1,263 @@
+package processors
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/utils"
+)
+
+// ReconciliationDifference is a asset where the computed balance differs from the
+// exchange reported balance more than the tolerance.
+type ReconciliationDifference struct {
+	Exchange string
+	Asset    common.AssetType
+	At       time.Time
+	Reported float64
+	Computed float64
+	// Difference is _Computed_ - _Reported_.
+	Difference float64
+	// FirstDivergence is the transaction where the divergence is likely to start
+	// or `nil` if no transaction has affected the asset.
+	FirstDivergence common.TransactionEntry
+}
+
+// ReconciliationReport is the result of a `Reconciler.Reconcile`.
+type ReconciliationReport struct {
+	// Matched is the number of asset balances that are within tolerance.
+	Matched     int
+	Differences []ReconciliationDifference
+}
+
+// Reconciler compares balance snapshots, reported by the exchanges, with the computed
+// `common.AccountStatus` from e.g. `MultiExchangeAccountingProcessor`.
+//
+// The computed balance is the account status of the last transaction on or before the
+// snapshot time on the same exchange. Assets that are not present in the snapshot is
+// treated as reported zero.
+//
+// The first divergence is located by searching the transactions, that changed the
+// asset balance, after the latest earlier snapshot where the asset did match (or from
+// the beginning). If any of those makes the balance negative, the first such is used,
+// otherwise the first that changed the balance.
+type Reconciler struct {
+	tolerance  float64
+	tolerances map[common.AssetType]float64
+	snapshots  []common.BalanceSnapshot
+}
+
+// NewReconciler creates a new `Reconciler` where _tolerance_ is the absolute
+// difference accepted for all assets.
+func NewReconciler(tolerance float64) *Reconciler {
+
+	return &Reconciler{
+		tolerance:  tolerance,
+		tolerances: map[common.AssetType]float64{},
+		snapshots:  []common.BalanceSnapshot{},
+	}
+
+}
+
+// UseAssetTolerance overrides the tolerance for a specific _asset_.
+func (r *Reconciler) UseAssetTolerance(asset common.AssetType, tolerance float64) *Reconciler {
+
+	r.tolerances[asset] = tolerance
+	return r
+
+}
+
+// AddSnapshots adds exchange reported balances.
+func (r *Reconciler) AddSnapshots(snapshots ...common.BalanceSnapshot) *Reconciler {
+
+	r.snapshots = append(r.snapshots, snapshots...)
+	return r
+
+}
+
+// Reconcile compares all snapshots with the _accounts_, keyed by exchange, as returned
+// from `MultiExchangeAccountingProcessor.Flush`.
+func (r *Reconciler) Reconcile(accounts map[string][]common.TransactionEntry) *ReconciliationReport {
+
+	snapshots := append([]common.BalanceSnapshot{}, r.snapshots...)
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].At.Before(snapshots[j].At)
+	})
+
+	report := &ReconciliationReport{Differences: []ReconciliationDifference{}}
+	lastMatch := map[string]time.Time{}
+
+	for _, snapshot := range snapshots {
+
+		entries := accounts[snapshot.Exchange]
+		computed := computedStatusAt(entries, snapshot.At)
+
+		for _, asset := range unionAssets(snapshot.Balances, computed) {
+
+			reported := snapshot.Balances[asset]
+			balance := computed[asset]
+			diff := utils.ToFixed(balance-reported, 8)
+
+			key := snapshot.Exchange + "/" + string(asset)
+
+			if math.Abs(diff) <= r.toleranceOf(asset) {
+
+				report.Matched++
+				lastMatch[key] = snapshot.At
+				continue
+
+			}
+
+			report.Differences = append(report.Differences, ReconciliationDifference{
+				Exchange:        snapshot.Exchange,
+				Asset:           asset,
+				At:              snapshot.At,
+				Reported:        reported,
+				Computed:        balance,
+				Difference:      diff,
+				FirstDivergence: firstDivergence(entries, asset, lastMatch[key], snapshot.At),
+			})
+
+		}
+
+	}
+
+	return report
+}
+
+// Write writes a human readable report. If _w_ is `nil`, `os.Stdout` is used.
+func (report *ReconciliationReport) Write(w io.Writer) {
+
+	if w == nil {
+		w = os.Stdout
+	}
+
+	fmt.Fprintf(
+		w, "Reconciliation: %d matched, %d differences\n",
+		report.Matched, len(report.Differences),
+	)
+
+	for _, d := range report.Differences {
+
+		fmt.Fprintf(
+			w, "%s %s %s reported: %f computed: %f difference: %f",
+			d.At.Format(time.RFC3339), d.Exchange, d.Asset, d.Reported, d.Computed, d.Difference,
+		)
+
+		if d.FirstDivergence != nil {
+
+			fmt.Fprintf(
+				w, " first divergence: %s (%s %s at %s)",
+				d.FirstDivergence.GetID(), d.FirstDivergence.GetSide(),
+				d.FirstDivergence.GetAssetPair().String(),
+				d.FirstDivergence.GetCreatedAt().Format(time.RFC3339),
+			)
+
+		}
+
+		fmt.Fprintln(w)
+	}
+
+}
+
+func (r *Reconciler) toleranceOf(asset common.AssetType) float64 {
+
+	if t, ok := r.tolerances[asset]; ok {
+		return t
+	}
+
+	return r.tolerance
+}
+
+// computedStatusAt returns the account status of the last entry on or before _at_.
+func computedStatusAt(entries []common.TransactionEntry, at time.Time) common.AccountStatus {
+
+	status := common.AccountStatus{}
+
+	for _, entry := range entries {
+
+		if entry.GetCreatedAt().After(at) {
+			break
+		}
+
+		if acc, ok := entry.(common.AccountEntry); ok {
+			status = acc.GetAccountStatus()
+		}
+
+	}
+
+	return status
+}
+
+// firstDivergence returns the first entry after _since_ up to _until_ that made the
+// _asset_ balance negative or, if none, the first that changed the balance.
+func firstDivergence(
+	entries []common.TransactionEntry,
+	asset common.AssetType,
+	since, until time.Time,
+) common.TransactionEntry {
+
+	var first common.TransactionEntry
+	previous := float64(0)
+
+	for _, entry := range entries {
+
+		if entry.GetCreatedAt().After(until) {
+			break
+		}
+
+		acc, ok := entry.(common.AccountEntry)
+		if !ok {
+			continue
+		}
+
+		balance := acc.GetAccountStatus()[asset]
+		changed := utils.ToFixed(balance-previous, 8) != 0
+		previous = balance
+
+		if !changed || !entry.GetCreatedAt().After(since) {
+			continue
+		}
+
+		if utils.ToFixed(balance, 8) < 0 {
+			return entry
+		}
+
+		if first == nil {
+			first = entry
+		}
+
+	}
+
+	return first
+}
+
+func unionAssets(reported map[common.AssetType]float64, computed common.AccountStatus) []common.AssetType {
+
+	seen := map[common.AssetType]bool{}
+	assets := []common.AssetType{}
+
+	for _, m := range []map[common.AssetType]float64{reported, computed} {
+
+		for asset := range m {
+
+			if !seen[asset] {
+				seen[asset] = true
+				assets = append(assets, asset)
+			}
+
+		}
+
+	}
+
+	sort.Slice(assets, func(i, j int) bool {
+		return assets[i] < assets[j]
+	})
+
+	return assets
+}
@@ -0,0 +1,75 @@
+package processors
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileLocatesFirstDivergence(t *testing.T) {
+
+	day1, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+	day2 := day1.AddDate(0, 0, 1)
+	btceur := common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeEuro}
+
+	acc := NewMultiExchangeAccountingProcessor()
+	acc.ProcessMany([]common.TransactionEntry{
+		&common.TransactionLog{
+			ID: "deposit", Exchange: "kr", Side: common.SideTypeReceive, CreatedAt: day1,
+			AssetSize: 100, PricePerUnit: 1, TotalPrice: 100,
+			AssetPair: common.AssetPair{Asset: common.AssetTypeEuro, CostUnit: common.AssetTypeEuro},
+		},
+		&common.TransactionLog{
+			ID: "buy", Exchange: "kr", Side: common.SideTypeBuy, CreatedAt: day1.Add(time.Hour),
+			AssetSize: 1, PricePerUnit: 50, TotalPrice: -50, AssetPair: btceur,
+		},
+		&common.TransactionLog{
+			ID: "sell", Exchange: "kr", Side: common.SideTypeSell, CreatedAt: day2.Add(time.Hour),
+			AssetSize: 0.5, PricePerUnit: 80, TotalPrice: 40, AssetPair: btceur,
+		},
+	})
+
+	report := NewReconciler(0.00001).
+		UseAssetTolerance(common.AssetTypeEuro, 0.5).
+		AddSnapshots(
+			common.BalanceSnapshot{
+				Exchange: "kr", At: day2.Add(time.Hour * 23),
+				Balances: map[common.AssetType]float64{
+					common.AssetTypeBTC: 0.4, common.AssetTypeEuro: 90.2,
+				},
+			},
+			common.BalanceSnapshot{
+				Exchange: "kr", At: day1.Add(time.Hour * 23),
+				Balances: map[common.AssetType]float64{
+					common.AssetTypeBTC: 1, common.AssetTypeEuro: 50,
+				},
+			},
+		).
+		Reconcile(acc.Flush())
+
+	assert.Equal(t, 3, report.Matched)
+	require.Equal(t, 1, len(report.Differences))
+
+	diff := report.Differences[0]
+	assert.Equal(t, common.AssetTypeBTC, diff.Asset)
+	assert.Equal(t, float64(0.4), diff.Reported)
+	assert.Equal(t, float64(0.5), diff.Computed)
+	assert.Equal(t, float64(0.1), diff.Difference)
+	require.NotNil(t, diff.FirstDivergence)
+	assert.Equal(t, "sell", diff.FirstDivergence.GetID())
+
+	var buff bytes.Buffer
+	report.Write(&buff)
+
+	assert.Equal(
+		t,
+		"Reconciliation: 3 matched, 1 differences\n"+
+			"2021-01-02T23:00:00Z kr BTC reported: 0.400000 computed: 0.500000 difference: 0.100000 "+
+			"first divergence: sell (SELL BTC-EUR at 2021-01-02T01:00:00Z)\n",
+		buff.String(),
+	)
+}
@@ -0,0 +1,186 @@
+package processors
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+)
+
+// TradeLeg selects which side of a crypto to crypto trade that is valued when
+// translating it to a tracked asset.
+type TradeLeg int
+
+const (
+	// TradeLegCostUnit values the trade by the paid (or received) cost unit, e.g. the
+	// _BTC_ in a _LTC-BTC_ trade. This is the default.
+	TradeLegCostUnit TradeLeg = 0
+	// TradeLegAsset values the trade by the market value of the asset, e.g. the
+	// _LTC_ in a _LTC-BTC_ trade.
+	TradeLegAsset TradeLeg = 1
+)
+
+type tradePrice struct {
+	at       time.Time
+	price    float64
+	exchange string
+	txID     string
+}
+
+// tradePriceIndex keeps the executed prices from crypto to _FIAT_ trades, keyed
+// by asset and cost unit. The lists are appended when indexing and sorted by time
+// before the first lookup.
+type tradePriceIndex struct {
+	prices   map[common.AssetType]map[common.AssetType][]tradePrice
+	seen     map[string]bool
+	window   time.Duration
+	unsorted bool
+}
+
+func newTradePriceIndex(window time.Duration) *tradePriceIndex {
+
+	return &tradePriceIndex{
+		prices: map[common.AssetType]map[common.AssetType][]tradePrice{},
+		seen:   map[string]bool{},
+		window: window,
+	}
+
+}
+
+// tradePriceKey identifies a trade when de-duplicating. Trades without an ID are
+// keyed by their time, side, pair, size and price so they are not merged into one.
+func tradePriceKey(tx *common.TransactionLog) string {
+
+	if tx.ID != "" {
+		return tx.Exchange + "/" + tx.ID
+	}
+
+	return fmt.Sprintf(
+		"%s/%s/%s/%s/%v/%v",
+		tx.Exchange, tx.CreatedAt.Format(time.RFC3339Nano), tx.Side, tx.AssetPair, tx.AssetSize, tx.PricePerUnit,
+	)
+
+}
+
+// add indexes the _tx_ if it is a crypto to _FIAT_ buy or sell.
+func (idx *tradePriceIndex) add(tx *common.TransactionLog) {
+
+	if tx.Side != common.SideTypeBuy && tx.Side != common.SideTypeSell {
+		return
+	}
+
+	if !tx.CostUnit.IsFIAT() || tx.Asset.IsFIAT() || tx.PricePerUnit <= 0 {
+		return
+	}
+
+	key := tradePriceKey(tx)
+	if idx.seen[key] {
+		return
+	}
+
+	idx.seen[key] = true
+
+	costUnits := idx.prices[tx.Asset]
+	if costUnits == nil {
+		costUnits = map[common.AssetType][]tradePrice{}
+		idx.prices[tx.Asset] = costUnits
+	}
+
+	costUnits[tx.CostUnit] = append(costUnits[tx.CostUnit], tradePrice{
+		at:       tx.CreatedAt,
+		price:    tx.PricePerUnit,
+		exchange: tx.Exchange,
+		txID:     tx.ID,
+	})
+
+	idx.unsorted = true
+}
+
+// sort sorts all lists by time, if any trade has been added since last sort.
+func (idx *tradePriceIndex) sort() {
+
+	if !idx.unsorted {
+		return
+	}
+
+	for _, costUnits := range idx.prices {
+
+		for _, list := range costUnits {
+
+			sort.SliceStable(list, func(i, j int) bool {
+				return list[i].at.Before(list[j].at)
+			})
+
+		}
+
+	}
+
+	idx.unsorted = false
+}
+
+// find returns the trade of _asset_ in _costUnit_ that is closest to _at_ and
+// within the window.
+func (idx *tradePriceIndex) find(
+	asset, costUnit common.AssetType,
+	at time.Time,
+) (tradePrice, bool) {
+
+	idx.sort()
+
+	list := idx.prices[asset][costUnit]
+
+	i := sort.Search(len(list), func(i int) bool {
+		return !list[i].at.Before(at)
+	})
+
+	var found tradePrice
+	best := time.Duration(-1)
+
+	for _, j := range []int{i - 1, i} {
+
+		if j < 0 || j >= len(list) {
+			continue
+		}
+
+		d := list[j].at.Sub(at)
+		if d < 0 {
+			d = -d
+		}
+
+		if d <= idx.window && (best == -1 || d < best) {
+			found, best = list[j], d
+		}
+
+	}
+
+	return found, best != -1
+}
+
+// costUnits returns the _FIAT_ cost units, sorted, that _asset_ has been traded in.
+func (idx *tradePriceIndex) costUnits(asset common.AssetType) []common.AssetType {
+
+	list := []common.AssetType{}
+	for costUnit := range idx.prices[asset] {
+		list = append(list, costUnit)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i] < list[j]
+	})
+
+	return list
+}
+
+func (trade tradePrice) toHop(asset, costUnit common.AssetType) common.TranslationHop {
+
+	return common.TranslationHop{
+		Exchange:   trade.exchange,
+		AssetPair:  common.AssetPair{Asset: asset, CostUnit: costUnit},
+		CandleTime: trade.at,
+		Price:      trade.price,
+		Source:     common.TranslationSourceTrade,
+		TxID:       trade.txID,
+	}
+
+}
@@ -0,0 +1,182 @@
+package processors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/parsers"
+	"github.com/mariotoffia/gocryptoadmin/txhistory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tradePriceFixture() (*txhistory.TxOHCResolver, []common.TransactionLog) {
+
+	day, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+	at := day.Add(time.Hour * 12)
+
+	candle := func(exchange string, asset, costUnit common.AssetType, price float64) common.TxOHCHistory {
+
+		return common.TxOHCHistory{
+			Exchange:   exchange,
+			Resolution: 1440,
+			DateTime:   day,
+			Open:       price, High: price, Low: price, Close: price,
+			AssetPair: common.AssetPair{Asset: asset, CostUnit: costUnit},
+		}
+
+	}
+
+	cache := txhistory.NewTxOHCCache().Add([]common.TxOHCHistory{
+		candle("kr", common.AssetTypeBTC, common.AssetTypeEuro, 20000),
+		candle("ecb", common.AssetTypeEuro, common.AssetTypeSvenskKrona, 10),
+	})
+
+	resolver := txhistory.NewTxOHCResolver(cache).AddTranslations(
+		parsers.NewResolverParser().
+			Parse("BTC = kr:EUR\nEUR = ecb:SEK").
+			GetExpressions()...,
+	)
+
+	tx := []common.TransactionLog{
+		{
+			ID: "c1", Exchange: "kr", Side: common.SideTypeBuy, CreatedAt: at.Add(time.Minute),
+			AssetSize: 10, PricePerUnit: 0.01, TotalPrice: -0.1,
+			AssetPair: common.AssetPair{Asset: common.AssetTypeLTC, CostUnit: common.AssetTypeBTC},
+		},
+		{
+			ID: "s1", Exchange: "kr", Side: common.SideTypeSell, CreatedAt: at,
+			AssetSize: 0.1, PricePerUnit: 21000, TotalPrice: 2100,
+			AssetPair: common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeEuro},
+		},
+		{
+			ID: "s2", Exchange: "kr", Side: common.SideTypeSell, CreatedAt: at.Add(time.Minute * 2),
+			AssetSize: 10, PricePerUnit: 250, TotalPrice: 2500,
+			AssetPair: common.AssetPair{Asset: common.AssetTypeLTC, CostUnit: common.AssetTypeEuro},
+		},
+	}
+
+	return resolver, tx
+}
+
+func TestCandlesAreUsedWithoutTradePrices(t *testing.T) {
+
+	resolver, tx := tradePriceFixture()
+
+	proc := NewCostUnitProcessor(resolver, nil)
+	proc.RegisterAsset(common.AssetTypeEuro)
+	proc.ProcessMany(tx)
+
+	res := proc.Flush()
+	assert.InDelta(t, -2000, res[0].GetTranslatedTotalPrice(common.AssetTypeEuro), 0.0001)
+	assert.Equal(t, common.TranslationSourceCandle, res[0].Provenance["EUR"].Hops[0].Source)
+}
+
+func TestTradePricesArePreferredOverCandles(t *testing.T) {
+
+	resolver, tx := tradePriceFixture()
+
+	proc := NewCostUnitProcessor(resolver, nil).UseTradePrices(time.Hour)
+	proc.RegisterAsset(common.AssetTypeEuro, common.AssetTypeSvenskKrona)
+	proc.ProcessMany(tx)
+
+	res := proc.Flush()
+	require.Equal(t, 3, len(res))
+
+	assert.InDelta(t, -2100, res[0].GetTranslatedTotalPrice(common.AssetTypeEuro), 0.0001)
+
+	hops := res[0].Provenance["EUR"].Hops
+	require.Equal(t, 1, len(hops))
+	assert.Equal(t, common.TranslationSourceTrade, hops[0].Source)
+	assert.Equal(t, "s1", hops[0].TxID)
+
+	// Trade BTC-EUR then candle EUR-SEK
+	assert.InDelta(t, -21000, res[0].GetTranslatedTotalPrice(common.AssetTypeSvenskKrona), 0.0001)
+
+	hops = res[0].Provenance["SEK"].Hops
+	require.Equal(t, 2, len(hops))
+	assert.Equal(t, common.TranslationSourceTrade, hops[0].Source)
+	assert.Equal(t, common.TranslationSourceCandle, hops[1].Source)
+}
+
+func TestTradePricesOutsideWindowFallsBackToCandles(t *testing.T) {
+
+	resolver, tx := tradePriceFixture()
+
+	proc := NewCostUnitProcessor(resolver, nil).UseTradePrices(time.Second)
+	proc.RegisterAsset(common.AssetTypeEuro)
+	proc.ProcessMany(tx)
+
+	assert.InDelta(t, -2000, proc.Flush()[0].GetTranslatedTotalPrice(common.AssetTypeEuro), 0.0001)
+}
+
+func TestAssetLegValuesCryptoTradeByAsset(t *testing.T) {
+
+	resolver, tx := tradePriceFixture()
+
+	proc := NewCostUnitProcessor(resolver, nil).
+		UseTradePrices(time.Hour).
+		UseTradeLeg(TradeLegAsset)
+
+	proc.RegisterAsset(common.AssetTypeEuro)
+	proc.ProcessMany(tx)
+
+	res := proc.Flush()
+	assert.InDelta(t, -2500, res[0].GetTranslatedTotalPrice(common.AssetTypeEuro), 0.0001)
+	assert.Equal(t, "s2", res[0].Provenance["EUR"].Hops[0].TxID)
+
+	// Crypto to FIAT trades are not affected
+	assert.InDelta(t, 2100, res[1].GetTranslatedTotalPrice(common.AssetTypeEuro), 0.0001)
+}
+
+func TestTradePriceIndexSortsBeforeLookup(t *testing.T) {
+
+	at, _ := time.Parse(time.RFC3339, "2021-01-01T12:00:00Z")
+	idx := newTradePriceIndex(time.Hour)
+
+	for i, offset := range []time.Duration{time.Minute * 30, -time.Minute * 20, time.Minute * 5} {
+
+		idx.add(&common.TransactionLog{
+			ID: string(rune('a' + i)), Exchange: "kr", Side: common.SideTypeBuy, CreatedAt: at.Add(offset),
+			AssetSize: 1, PricePerUnit: float64(i + 1),
+			AssetPair: common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeEuro},
+		})
+
+	}
+
+	trade, ok := idx.find(common.AssetTypeBTC, common.AssetTypeEuro, at)
+	require.True(t, ok)
+	assert.Equal(t, "c", trade.txID)
+
+	list := idx.prices[common.AssetTypeBTC][common.AssetTypeEuro]
+	assert.True(t, list[0].at.Before(list[1].at) && list[1].at.Before(list[2].at))
+
+}
+
+func TestTradePriceIndexKeepsTradesWithoutID(t *testing.T) {
+
+	at, _ := time.Parse(time.RFC3339, "2021-01-01T12:00:00Z")
+	idx := newTradePriceIndex(time.Hour)
+
+	trade := common.TransactionLog{
+		Exchange: "kr", Side: common.SideTypeBuy, CreatedAt: at,
+		AssetSize: 1, PricePerUnit: 20000,
+		AssetPair: common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeEuro},
+	}
+
+	later := trade
+	later.CreatedAt = at.Add(time.Minute * 10)
+	later.PricePerUnit = 21000
+
+	idx.add(&trade)
+	idx.add(&later)
+	idx.add(&later)
+
+	assert.Len(t, idx.prices[common.AssetTypeBTC][common.AssetTypeEuro], 2)
+
+	found, ok := idx.find(common.AssetTypeBTC, common.AssetTypeEuro, at.Add(time.Minute*9))
+	require.True(t, ok)
+	assert.Equal(t, 21000.0, found.price)
+
+}
@@ -2,6 +2,8 @@ package processors
 
 import (
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/mariotoffia/gocryptoadmin/common"
 	"github.com/mariotoffia/gocryptoadmin/utils"
@@ -12,6 +14,7 @@ import (
 type TxBuySellProcessor struct {
 	queue    *common.TxAssetFIFOQueues
 	entries  []common.TxBuySellEntry
+	seeds    []common.InventoryLot
 	log      bool
 	taxation bool
 }
@@ -25,9 +28,14 @@ func NewTxBuySellProcessor() *TxBuySellProcessor {
 
 }
 
+// Reset clears all processed transactions and re-enqueues the lots seeded by
+// `SeedLots`.
 func (bs *TxBuySellProcessor) Reset() {
+
 	bs.entries = []common.TxBuySellEntry{}
 	bs.queue.Reset()
+	bs.enqueueLots(bs.seeds)
+
 }
 
 // UseTaxationMarking enables the taxation marking, reducing the
@@ -81,7 +89,7 @@ func (bs *TxBuySellProcessor) Process(tx common.TransactionEntry) {
 		}
 	}
 
-	entries, res, size := bs.drainBuys(assetPair.Asset, tx.GetAssetSize())
+	entries, res, size := bs.drainBuys(assetPair.Asset, tx.GetAssetSize(), tx)
 
 	// Split last entry and PutBack overflow into queue again.
 	if res == common.DequeueUntilResultOverflow {
@@ -133,7 +141,7 @@ func (bs *TxBuySellProcessor) ProcessBuy(tx common.TransactionEntry) {
 	// up to BUY tx GetAssetSize().
 	//
 	// It is negated since the buy in crypto will log entry as with fiat -> negative value.
-	entries, res, size := bs.drainBuys(assetPair.CostUnit, -tx.GetTotalPrice())
+	entries, res, size := bs.drainBuys(assetPair.CostUnit, -tx.GetTotalPrice(), tx)
 
 	if bs.log {
 		log(
@@ -201,6 +209,64 @@ func log(dir string, asset common.AssetType, entries []common.TransactionEntry,
 	}
 }
 
+// ExportLots returns the open lots, i.e. the entries that would be returned as
+// _noPairing_ from `Flush`, without removing them.
+//
+// The lots are sorted by queue asset and in FIFO order within each queue. Use
+// `SeedLots` to continue from the lots in a later run.
+func (bs *TxBuySellProcessor) ExportLots() []common.InventoryLot {
+
+	entries := bs.queue.Entries()
+
+	assets := make([]common.AssetType, 0, len(entries))
+	for asset := range entries {
+		assets = append(assets, asset)
+	}
+
+	sort.Slice(assets, func(i, j int) bool {
+		return assets[i] < assets[j]
+	})
+
+	lots := []common.InventoryLot{}
+
+	for _, asset := range assets {
+
+		for _, entry := range entries[asset] {
+
+			lots = append(lots, common.InventoryLot{
+				Queue: asset,
+				Tx:    common.ToTransactionLog(entry),
+			})
+
+		}
+
+	}
+
+	return lots
+}
+
+// SeedLots enqueues the _lots_, exported by `ExportLots`, as if they where
+// processed before any other transaction. The lots are kept when the processor
+// is `Reset`.
+func (bs *TxBuySellProcessor) SeedLots(lots []common.InventoryLot) *TxBuySellProcessor {
+
+	bs.seeds = append(bs.seeds, lots...)
+	bs.enqueueLots(lots)
+
+	return bs
+}
+
+func (bs *TxBuySellProcessor) enqueueLots(lots []common.InventoryLot) {
+
+	for i := range lots {
+
+		tx := lots[i].Tx
+		bs.queue.Enq(lots[i].Queue, &tx)
+
+	}
+
+}
+
 func (bs *TxBuySellProcessor) Flush() (entries []common.TxBuySellEntry, noPairing []common.TransactionEntry) {
 
 	// Get the overflow
@@ -226,10 +292,12 @@ func splitEntryByOverflow(
 
 // drainBuys will remove BUYs from the queue until satisfied _size_.
 //
-// If `common.DequeueUntilResultUnderflow`, it will *panic*.
+// If `common.DequeueUntilResultUnderflow`, it will *panic* with the _tx_ that could
+// not be satisfied and the likely missing transaction.
 func (bs *TxBuySellProcessor) drainBuys(
 	asset common.AssetType,
 	size float64,
+	tx common.TransactionEntry,
 ) ([]common.TransactionEntry, common.DequeueUntilResult, float64) {
 
 	fullSize := utils.ToFixed(size, 8)
@@ -255,8 +323,11 @@ func (bs *TxBuySellProcessor) drainBuys(
 
 		panic(
 			fmt.Sprintf(
-				"Could not find all BUY entries for asset: %s size: %f, missing: %f",
+				"Could not find all BUY entries for asset: %s size: %f, missing: %f "+
+					"(tx: %s %s on %s at %s) - likely missing BUY or RECEIVE of %f %s before %s",
 				asset, fullSize, size,
+				tx.GetID(), tx.GetSide(), tx.GetExchange(), tx.GetCreatedAt().Format(time.RFC3339),
+				size, asset, tx.GetCreatedAt().Format(time.RFC3339),
 			),
 		)
 
@@ -18,6 +18,8 @@ type TxGroupProcessor struct {
 
 // NewTxGroupProcessor creates a new processor with windowsize of 5 minutes.
 //
+// Transactions where the fee is charged in different assets are kept in separate groups.
+//
 // This `Processor` _REQUIRES_ that the log entries are ordered in chronological
 // order since it will not sort entries.
 //
@@ -33,7 +35,7 @@ func NewTxGroupProcessor(timewindow time.Duration) *TxGroupProcessor {
 
 	render := func(tx common.TransactionEntry, side common.SideType) string {
 		return fmt.Sprintf(
-			"%s_%s_%s", tx.GetExchange(), tx.GetAssetPair(), side,
+			"%s_%s_%s_%s", tx.GetExchange(), tx.GetAssetPair(), side, tx.GetFeeAsset(),
 		)
 	}
 
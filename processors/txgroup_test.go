@@ -6,11 +6,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mariotoffia/gocryptoadmin/common"
 	"github.com/mariotoffia/gocryptoadmin/output"
 	"github.com/mariotoffia/gocryptoadmin/txlog"
 	"github.com/mariotoffia/gocryptoadmin/txlog/coinbasepro"
 	"github.com/mariotoffia/gocryptoadmin/utils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestReadCoinbasedTxLogFiles(t *testing.T) {
@@ -69,3 +71,40 @@ func TestBuyTransferShallBeSplitWhenTransfer(t *testing.T) {
 	assert.Equal(t, 11, len(txg))
 	assert.Equal(t, 12, len(tx))
 }
+
+func TestGroupsAreSplitByFeeAsset(t *testing.T) {
+
+	at, _ := time.Parse(time.RFC3339, "2021-01-01T12:00:00Z")
+	btceur := common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeEuro}
+
+	buy := func(id string, offset time.Duration, feeAsset common.AssetType) *common.TransactionLog {
+
+		return &common.TransactionLog{
+			ID: id, Exchange: "kr", Side: common.SideTypeBuy, CreatedAt: at.Add(offset),
+			AssetSize: 1, PricePerUnit: 100, TotalPrice: -100, Fee: 1, FeeAsset: feeAsset, AssetPair: btceur,
+		}
+
+	}
+
+	proc := NewTxGroupProcessor(time.Hour)
+	proc.ProcessMany([]common.TransactionEntry{
+		buy("b1", 0, common.AssetTypeEuro),
+		buy("b2", time.Minute, ""),
+		buy("b3", time.Minute*2, common.AssetTypeBNB),
+	})
+
+	txg := proc.Flush()
+	require.Equal(t, 2, len(txg))
+
+	assert.Equal(t, 2, len(txg[0].GetTransactionEntries()))
+	assert.Equal(t, common.AssetTypeEuro, txg[0].GetFeeAsset())
+	assert.Equal(t, float64(2), txg[0].GetFee())
+
+	assert.Equal(t, 1, len(txg[1].GetTransactionEntries()))
+	assert.Equal(t, common.AssetTypeBNB, txg[1].GetFeeAsset())
+
+	assert.Panics(t, func() {
+		txg[0].AddTransactionEntry(buy("b4", time.Minute*3, common.AssetTypeBNB))
+	})
+
+}
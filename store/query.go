@@ -0,0 +1,107 @@
+package store
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+)
+
+// Query filters the stored transactions. Empty fields do not filter.
+type Query struct {
+	Exchanges []string
+	// Assets matches either the asset or the cost unit.
+	Assets []common.AssetType
+	Sides  []common.SideType
+	// From is inclusive.
+	From time.Time
+	// To is exclusive.
+	To time.Time
+}
+
+// Matches returns `true` if _tx_ is matched by the query.
+func (q *Query) Matches(tx *common.TransactionLog) bool {
+
+	if len(q.Exchanges) > 0 && !containsString(q.Exchanges, tx.Exchange) {
+		return false
+	}
+
+	if len(q.Assets) > 0 &&
+		!containsAsset(q.Assets, tx.Asset) && !containsAsset(q.Assets, tx.CostUnit) {
+		return false
+	}
+
+	if len(q.Sides) > 0 && !containsSide(q.Sides, tx.Side) {
+		return false
+	}
+
+	if !q.From.IsZero() && tx.CreatedAt.Before(q.From) {
+		return false
+	}
+
+	if !q.To.IsZero() && !tx.CreatedAt.Before(q.To) {
+		return false
+	}
+
+	return true
+}
+
+// GetTransactions returns copies of all transactions matching _q_ in chronological
+// order. Transactions with same time keeps the order they were added.
+func (s *Store) GetTransactions(q Query) []common.TransactionLog {
+
+	list := []common.TransactionLog{}
+
+	for i := range s.transactions {
+
+		if q.Matches(&s.transactions[i]) {
+			list = append(list, *s.transactions[i].Clone().(*common.TransactionLog))
+		}
+
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+
+	return list
+}
+
+func containsString(list []string, s string) bool {
+
+	for i := range list {
+
+		if list[i] == s {
+			return true
+		}
+
+	}
+
+	return false
+}
+
+func containsAsset(list []common.AssetType, asset common.AssetType) bool {
+
+	for i := range list {
+
+		if list[i] == asset {
+			return true
+		}
+
+	}
+
+	return false
+}
+
+func containsSide(list []common.SideType, side common.SideType) bool {
+
+	for i := range list {
+
+		if list[i] == side {
+			return true
+		}
+
+	}
+
+	return false
+}
@@ -0,0 +1,479 @@
+// Package store is a embedded, file based, store for transactions, candles and
+// derived results.
+//
+// Transactions, candles and import records are kept in append only _JSON_ line files
+// in the store directory. Derived results are stored as one _JSON_ file each.
+package store
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/txhistory"
+	"github.com/mariotoffia/gocryptoadmin/txlog"
+)
+
+const (
+	transactionsFile = "transactions.jsonl"
+	candlesFile      = "candles.jsonl"
+	importsFile      = "imports.jsonl"
+	resultsDir       = "results"
+)
+
+// ImportRecord is a imported source.
+type ImportRecord struct {
+	// Hash is the hex encoded _SHA-256_ of the source data.
+	Hash       string    `json:"hash"`
+	Source     string    `json:"source"`
+	Exchange   string    `json:"exchange"`
+	ImportedAt time.Time `json:"imported"`
+	// Count is the number of transactions that was added, i.e. not already in the store.
+	Count int `json:"count"`
+}
+
+// Store keeps all data in memory and appends all additions to the files in the
+// store directory.
+type Store struct {
+	dir          string
+	imports      map[string]ImportRecord
+	transactions []common.TransactionLog
+	txKeys       map[string]bool
+	candles      []common.TxOHCHistory
+	candleKeys   map[string]bool
+}
+
+// Open opens, or creates, a store in _dir_.
+func Open(dir string) (*Store, error) {
+
+	if err := os.MkdirAll(filepath.Join(dir, resultsDir), 0755); err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		dir:          dir,
+		imports:      map[string]ImportRecord{},
+		transactions: []common.TransactionLog{},
+		txKeys:       map[string]bool{},
+		candles:      []common.TxOHCHistory{},
+		candleKeys:   map[string]bool{},
+	}
+
+	err := readLines(filepath.Join(dir, importsFile), func(line []byte) error {
+
+		var rec ImportRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return err
+		}
+
+		s.imports[rec.Hash] = rec
+		return nil
+
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = readLines(filepath.Join(dir, transactionsFile), func(line []byte) error {
+
+		var tx common.TransactionLog
+		if err := json.Unmarshal(line, &tx); err != nil {
+			return err
+		}
+
+		s.txKeys[txKey(&tx)] = true
+		s.transactions = append(s.transactions, tx)
+		return nil
+
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = readLines(filepath.Join(dir, candlesFile), func(line []byte) error {
+
+		var candle common.TxOHCHistory
+		if err := json.Unmarshal(line, &candle); err != nil {
+			return err
+		}
+
+		s.candleKeys[candleKey(&candle)] = true
+		s.candles = append(s.candles, candle)
+		return nil
+
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// HashSource returns the hex encoded _SHA-256_ of _data_.
+func HashSource(data []byte) string {
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+
+}
+
+// IsImported returns `true` if a source with the _hash_ has been imported.
+func (s *Store) IsImported(hash string) bool {
+
+	_, ok := s.imports[hash]
+	return ok
+
+}
+
+// GetImports returns all import records sorted by import time.
+func (s *Store) GetImports() []ImportRecord {
+
+	list := make([]ImportRecord, 0, len(s.imports))
+	for _, rec := range s.imports {
+		list = append(list, rec)
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+
+		if list[i].ImportedAt.Equal(list[j].ImportedAt) {
+			return list[i].Source < list[j].Source
+		}
+
+		return list[i].ImportedAt.Before(list[j].ImportedAt)
+	})
+
+	return list
+}
+
+// Import imports the _data_ from _source_, using _parse_ to get the transactions, unless
+// the same data already has been imported. Transactions with same exchange and id as
+// an already stored transaction are skipped, hence overlapping exports are fine.
+//
+// It returns the import record and `true` if the data was imported.
+func (s *Store) Import(
+	source string,
+	exchange string,
+	data []byte,
+	parse func(data []byte) []common.TransactionLog,
+) (ImportRecord, bool, error) {
+
+	hash := HashSource(data)
+
+	if rec, ok := s.imports[hash]; ok {
+		return rec, false, nil
+	}
+
+	added, err := s.AddTransactions(parse(data))
+	if err != nil {
+		return ImportRecord{}, false, err
+	}
+
+	rec := ImportRecord{
+		Hash:       hash,
+		Source:     source,
+		Exchange:   exchange,
+		ImportedAt: time.Now().UTC(),
+		Count:      added,
+	}
+
+	if err := appendLines(filepath.Join(s.dir, importsFile), []interface{}{rec}); err != nil {
+		return ImportRecord{}, false, err
+	}
+
+	s.imports[hash] = rec
+	return rec, true, nil
+}
+
+// ImportFile imports the _path_ using the reader, in _lr_, that `txlog.ReaderNameFromFileName`
+// selects. The transactions are post processed by _lr_ as when using `ReadBufferAsExchange`.
+func (s *Store) ImportFile(lr *txlog.TxLogReaderImpl, path string) (ImportRecord, bool, error) {
+
+	name := txlog.ReaderNameFromFileName(path)
+
+	if !lr.HasReader(name) {
+		return ImportRecord{}, false, fmt.Errorf("no reader registered for file: %s", path)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ImportRecord{}, false, err
+	}
+
+	return s.Import(filepath.Base(path), name, data, func(data []byte) []common.TransactionLog {
+		return lr.ReadBufferAsExchange(name, data)
+	})
+
+}
+
+// ImportDir imports all _.csv_ files, that has a registered reader in _lr_, in _dir_.
+// Only files not already imported are read. It returns the records of the new imports.
+func (s *Store) ImportDir(lr *txlog.TxLogReaderImpl, dir string) ([]ImportRecord, error) {
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	records := []ImportRecord{}
+
+	for _, file := range files {
+
+		if file.IsDir() || filepath.Ext(file.Name()) != ".csv" {
+			continue
+		}
+
+		if !lr.HasReader(txlog.ReaderNameFromFileName(file.Name())) {
+			continue
+		}
+
+		rec, imported, err := s.ImportFile(lr, filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		if imported {
+			records = append(records, rec)
+		}
+
+	}
+
+	return records, nil
+}
+
+// AddTransactions adds the transactions that are not already stored (by exchange and
+// id) and returns the number of added. The transactions are only kept in memory when
+// successfully written to disk.
+func (s *Store) AddTransactions(tx []common.TransactionLog) (int, error) {
+
+	keys := map[string]bool{}
+	added := []common.TransactionLog{}
+	lines := []interface{}{}
+
+	for i := range tx {
+
+		key := txKey(&tx[i])
+		if s.txKeys[key] || keys[key] {
+			continue
+		}
+
+		keys[key] = true
+		added = append(added, tx[i])
+		lines = append(lines, &tx[i])
+
+	}
+
+	if err := appendLines(filepath.Join(s.dir, transactionsFile), lines); err != nil {
+		return 0, err
+	}
+
+	for key := range keys {
+		s.txKeys[key] = true
+	}
+
+	s.transactions = append(s.transactions, added...)
+	return len(added), nil
+}
+
+// AddCandles adds the candles that are not already stored (by exchange, asset pair,
+// resolution and time) and returns the number of added. The candles are only kept in
+// memory when successfully written to disk.
+func (s *Store) AddCandles(candles []common.TxOHCHistory) (int, error) {
+
+	keys := map[string]bool{}
+	added := []common.TxOHCHistory{}
+	lines := []interface{}{}
+
+	for i := range candles {
+
+		key := candleKey(&candles[i])
+		if s.candleKeys[key] || keys[key] {
+			continue
+		}
+
+		keys[key] = true
+		added = append(added, candles[i])
+		lines = append(lines, &candles[i])
+
+	}
+
+	if err := appendLines(filepath.Join(s.dir, candlesFile), lines); err != nil {
+		return 0, err
+	}
+
+	for key := range keys {
+		s.candleKeys[key] = true
+	}
+
+	s.candles = append(s.candles, added...)
+	return len(added), nil
+}
+
+// GetCandles returns all candles for _exchange_, or all if empty.
+func (s *Store) GetCandles(exchange string) []common.TxOHCHistory {
+
+	list := []common.TxOHCHistory{}
+
+	for _, candle := range s.candles {
+
+		if exchange == "" || candle.Exchange == exchange {
+			list = append(list, candle)
+		}
+
+	}
+
+	return list
+}
+
+// FillCache adds all stored candles into the _cache_, per exchange.
+func (s *Store) FillCache(cache *txhistory.TxOHCCache) *txhistory.TxOHCCache {
+
+	exchanges := map[string][]common.TxOHCHistory{}
+	for _, candle := range s.candles {
+		exchanges[candle.Exchange] = append(exchanges[candle.Exchange], candle)
+	}
+
+	names := make([]string, 0, len(exchanges))
+	for name := range exchanges {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		cache.Add(exchanges[name], name)
+	}
+
+	return cache
+}
+
+// SaveResult stores a derived result, as _JSON_, under _name_. It replaces any
+// earlier result with same name.
+func (s *Store) SaveResult(name string, v interface{}) error {
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := s.resultPath(name)
+	tmp := path + ".tmp"
+
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// LoadResult loads the result stored under _name_ into _v_. It returns `false` if
+// no such result exists.
+func (s *Store) LoadResult(name string, v interface{}) (bool, error) {
+
+	data, err := ioutil.ReadFile(s.resultPath(name))
+
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, json.Unmarshal(data, v)
+}
+
+func (s *Store) resultPath(name string) string {
+	return filepath.Join(s.dir, resultsDir, filepath.Base(name)+".json")
+}
+
+func txKey(tx *common.TransactionLog) string {
+	return tx.Exchange + "/" + tx.ID
+}
+
+func candleKey(candle *common.TxOHCHistory) string {
+
+	return fmt.Sprintf(
+		"%s/%s/%d/%d",
+		candle.Exchange, candle.AssetPair.String(), candle.Resolution, candle.DateTime.Unix(),
+	)
+
+}
+
+func readLines(path string, fn func(line []byte) error) error {
+
+	f, err := os.Open(path)
+
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		if err := fn(line); err != nil {
+			return fmt.Errorf("%s: %s", path, err.Error())
+		}
+
+	}
+
+	return scanner.Err()
+}
+
+func appendLines(path string, lines []interface{}) error {
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+
+	for _, line := range lines {
+
+		if err := enc.Encode(line); err != nil {
+
+			f.Close()
+			return err
+
+		}
+
+	}
+
+	if err := w.Flush(); err != nil {
+
+		f.Close()
+		return err
+
+	}
+
+	return f.Close()
+}
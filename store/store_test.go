@@ -0,0 +1,169 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/processors"
+	"github.com/mariotoffia/gocryptoadmin/txhistory"
+	"github.com/mariotoffia/gocryptoadmin/txlog"
+	"github.com/mariotoffia/gocryptoadmin/txlog/coinbasepro"
+	"github.com/mariotoffia/gocryptoadmin/txlog/kraken"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func copyFile(t *testing.T, from, dir string) {
+
+	data, err := ioutil.ReadFile(from)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, filepath.Base(from)), data, 0644))
+
+}
+
+func newReader() *txlog.TxLogReaderImpl {
+
+	return txlog.NewTxLogReader(processors.NewChronologicalTxEntryProcessor()).
+		RegisterReader("cbx", coinbasepro.NewTransactionLogReader()).
+		RegisterReader("krk", kraken.NewTransactionLogReader())
+
+}
+
+func TestImportIsIdempotentAndIncremental(t *testing.T) {
+
+	exports := t.TempDir()
+	dir := t.TempDir()
+
+	copyFile(t, "../txlog/testfiles/cbx/cbx_buysell.csv", exports)
+
+	s, err := Open(dir)
+	require.NoError(t, err)
+
+	records, err := s.ImportDir(newReader(), exports)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(records))
+	assert.Equal(t, "cbx", records[0].Exchange)
+	assert.Equal(t, 3, records[0].Count)
+
+	// Same file again is skipped
+	records, err = s.ImportDir(newReader(), exports)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(records))
+
+	// A new export is ingested, after reopening the store
+	copyFile(t, "../txlog/testfiles/krk/krk_buysell.csv", exports)
+
+	s, err = Open(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 3, len(s.GetTransactions(Query{})))
+
+	records, err = s.ImportDir(newReader(), exports)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(records))
+	assert.Equal(t, "krk_buysell.csv", records[0].Source)
+
+	s, err = Open(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(s.GetImports()))
+	assert.Equal(t, 16, len(s.GetTransactions(Query{})))
+
+	cbx := s.GetTransactions(Query{Exchanges: []string{"cbx"}, Sides: []common.SideType{common.SideTypeSell}})
+	require.Equal(t, 2, len(cbx))
+	assert.Equal(t, "382592", cbx[0].ID)
+
+	from, _ := time.Parse(time.RFC3339, "2019-06-26T10:00:00Z")
+	xlm := s.GetTransactions(Query{Assets: []common.AssetType{common.AssetTypeXLM}, From: from})
+	require.Equal(t, 2, len(xlm))
+	assert.Equal(t, "382593", xlm[1].ID)
+
+	to, _ := time.Parse(time.RFC3339, "2018-01-01T00:00:00Z")
+	assert.True(t, len(s.GetTransactions(Query{To: to})) > 0)
+
+	for _, tx := range s.GetTransactions(Query{To: to}) {
+		assert.Equal(t, "krk", tx.Exchange)
+	}
+}
+
+func TestCandlesAndResults(t *testing.T) {
+
+	dir := t.TempDir()
+	day, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+
+	candle := common.TxOHCHistory{
+		Exchange: "ecb", Resolution: 1440, DateTime: day, Open: 10, High: 10, Low: 10, Close: 10,
+		AssetPair: common.AssetPair{Asset: common.AssetTypeEuro, CostUnit: common.AssetTypeSvenskKrona},
+	}
+
+	s, err := Open(dir)
+	require.NoError(t, err)
+
+	added, err := s.AddCandles([]common.TxOHCHistory{candle, candle})
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+
+	require.NoError(t, s.SaveResult("balances", map[string]float64{"EUR": 12.5}))
+
+	s, err = Open(dir)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(s.GetCandles("ecb")))
+
+	cache := s.FillCache(txhistory.NewTxOHCCache())
+	entry, exchange := cache.GetEntryForAssset(candle.AssetPair, day.Add(time.Hour), "ecb")
+	require.NotNil(t, entry)
+	assert.Equal(t, "ecb", exchange)
+
+	balances := map[string]float64{}
+	found, err := s.LoadResult("balances", &balances)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 12.5, balances["EUR"])
+
+	found, err = s.LoadResult("missing", &balances)
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestFailedWriteIsNotKeptInMemory(t *testing.T) {
+
+	dir := t.TempDir()
+
+	s, err := Open(dir)
+	require.NoError(t, err)
+
+	// A directory in place of the files makes the append fail
+	require.NoError(t, os.Mkdir(filepath.Join(dir, transactionsFile), 0755))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, candlesFile), 0755))
+
+	tx := []common.TransactionLog{{ID: "1", Exchange: "krk"}, {ID: "1", Exchange: "krk"}}
+	candles := []common.TxOHCHistory{{Exchange: "ecb", Resolution: 1440}}
+
+	_, err = s.AddTransactions(tx)
+	assert.Error(t, err)
+	assert.Equal(t, 0, len(s.GetTransactions(Query{})))
+
+	_, err = s.AddCandles(candles)
+	assert.Error(t, err)
+	assert.Equal(t, 0, len(s.GetCandles("")))
+
+	// A retry, once writable, adds them
+	require.NoError(t, os.Remove(filepath.Join(dir, transactionsFile)))
+	require.NoError(t, os.Remove(filepath.Join(dir, candlesFile)))
+
+	added, err := s.AddTransactions(tx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+
+	added, err = s.AddCandles(candles)
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+
+	s, err = Open(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(s.GetTransactions(Query{})))
+	assert.Equal(t, 1, len(s.GetCandles("")))
+
+}
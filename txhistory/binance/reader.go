@@ -0,0 +1,288 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/txhistory/httputils"
+	"github.com/mariotoffia/gocryptoadmin/utils"
+)
+
+// https://api.binance.com/api/v3/klines?symbol=BTCUSDT&interval=1d&startTime=1609459200000&limit=1000
+
+// Binance reads from the public klines (candlestick) API.
+type Binance struct {
+	baseURL  string
+	exchange string
+	limit    int
+	client   *httputils.Client
+	now      func() time.Time
+}
+
+type candleInterval struct {
+	name     string
+	duration time.Duration
+}
+
+// candleIntervals are the supported _Binance_ intervals in ascending order.
+//
+// NOTE: The _1M_ (month) interval is not included since it do not have a
+// fixed duration.
+var candleIntervals = []candleInterval{
+	{"1m", time.Minute},
+	{"3m", time.Minute * 3},
+	{"5m", time.Minute * 5},
+	{"15m", time.Minute * 15},
+	{"30m", time.Minute * 30},
+	{"1h", time.Hour},
+	{"2h", time.Hour * 2},
+	{"4h", time.Hour * 4},
+	{"6h", time.Hour * 6},
+	{"8h", time.Hour * 8},
+	{"12h", time.Hour * 12},
+	{"1d", time.Hour * 24},
+	{"3d", time.Hour * 24 * 3},
+	{"1w", time.Hour * 24 * 7},
+}
+
+// quoteAssets are used when splitting a _Binance_ symbol into a `common.AssetPair`.
+//
+// Longer tickers are listed before shorter ones that are a suffix of them (e.g.
+// _USDT_ before _USD_).
+var quoteAssets = []string{
+	"USDT", "BUSD", "USDC", "TUSD", "BTC", "ETH", "BNB", "EUR", "GBP", "TRY", "AUD",
+}
+
+// New creates a new _Binance_ reader.
+//
+// If _baseURL_ is empty, the public _Binance_ API is used. By default, it will
+// use a `httputils.Client` that allows five requests per second, well below the
+// request weight limit of the public API.
+func New(baseURL string) *Binance {
+
+	if baseURL == "" {
+		baseURL = "https://api.binance.com/api/v3"
+	}
+
+	return &Binance{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		exchange: "bnc",
+		limit:    1000,
+		client:   httputils.NewClient(nil).UseRateLimit(5, time.Second),
+		now:      time.Now,
+	}
+}
+
+// UseClient replaces the default `httputils.Client`.
+func (bnc *Binance) UseClient(client *httputils.Client) *Binance {
+
+	bnc.client = client
+	return bnc
+
+}
+
+// UsePageSize sets the number of candles requested per page (max 1000).
+func (bnc *Binance) UsePageSize(limit int) *Binance {
+
+	if limit <= 0 || limit > 1000 {
+		limit = 1000
+	}
+
+	bnc.limit = limit
+	return bnc
+
+}
+
+func (bnc *Binance) SetExchangeName(name string) {
+	bnc.exchange = name
+}
+
+func (bnc *Binance) Read(
+	pair common.AssetPair,
+	since time.Time,
+	interval time.Duration,
+) []common.TxOHCHistory {
+
+	list, err := bnc.ReadContext(context.Background(), pair, since, interval)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return list
+}
+
+// ReadContext reads all candles from _since_ until now.
+//
+// The _interval_ is mapped onto the largest _Binance_ candle size that do not
+// exceed _interval_ and the `common.TxOHCHistory.Resolution` reflects the
+// actual candle size. It will page through the time range using _startTime_
+// until a page returns less entries than the page size.
+func (bnc *Binance) ReadContext(
+	ctx context.Context,
+	pair common.AssetPair,
+	since time.Time,
+	interval time.Duration,
+) ([]common.TxOHCHistory, error) {
+
+	ci := toCandleInterval(interval)
+	pair = common.AssetPair{
+		Asset:    pair.Asset.Normalize(),
+		CostUnit: pair.CostUnit.Normalize(),
+	}
+
+	list := []common.TxOHCHistory{}
+	now := bnc.now()
+	start := since
+
+	for start.Before(now) {
+
+		req := fmt.Sprintf(
+			"%s/klines?symbol=%s&interval=%s&startTime=%d&limit=%d",
+			bnc.baseURL, ToSymbol(pair), ci.name,
+			start.UnixNano()/int64(time.Millisecond),
+			bnc.limit,
+		)
+
+		data, err := bnc.client.Get(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		var klines [][]interface{}
+		if err = json.Unmarshal(data, &klines); err != nil {
+			return nil, fmt.Errorf("data: %s, err: %s", string(data), err.Error())
+		}
+
+		for _, kline := range klines {
+
+			entry, err := bnc.toEntry(kline, pair, ci)
+			if err != nil {
+				return nil, err
+			}
+
+			list = append(list, entry)
+
+		}
+
+		if len(klines) < bnc.limit {
+			break
+		}
+
+		start = list[len(list)-1].DateTime.Add(ci.duration)
+	}
+
+	return list, nil
+}
+
+func (bnc *Binance) toEntry(
+	arr []interface{},
+	pair common.AssetPair,
+	ci candleInterval,
+) (common.TxOHCHistory, error) {
+
+	if len(arr) < 8 {
+		return common.TxOHCHistory{}, fmt.Errorf("unexpected kline: %v", arr)
+	}
+
+	openTime, ok := arr[0].(float64)
+	if !ok {
+		return common.TxOHCHistory{}, fmt.Errorf("unexpected kline open time: %v", arr[0])
+	}
+
+	values := make([]float64, 8)
+	for _, i := range []int{1, 2, 3, 4, 5, 7} {
+
+		s, ok := arr[i].(string)
+		if !ok {
+			return common.TxOHCHistory{}, fmt.Errorf("unexpected kline value: %v", arr[i])
+		}
+
+		values[i] = utils.Float64FromString(s)
+
+	}
+
+	entry := common.TxOHCHistory{
+		Exchange:       bnc.exchange,
+		AssetPair:      pair,
+		DateTime:       utils.ToUnixMillisFromTimeStamp(int64(openTime)).UTC(),
+		Resolution:     int(ci.duration / time.Minute),
+		Open:           values[1],
+		High:           values[2],
+		Low:            values[3],
+		Close:          values[4],
+		AssetVolume:    values[5],
+		CostUnitVolume: values[7],
+	}
+
+	entry.ID = utils.ToString(utils.HashFromTime(entry.DateTime))
+
+	return entry, nil
+}
+
+// toCandleInterval returns the largest candle interval that do not exceed
+// _interval_. If _interval_ is less than a minute, the _1m_ is returned.
+func toCandleInterval(interval time.Duration) candleInterval {
+
+	found := candleIntervals[0]
+
+	for _, ci := range candleIntervals {
+
+		if ci.duration > interval {
+			break
+		}
+
+		found = ci
+
+	}
+
+	return found
+}
+
+// ToSymbol renders the _Binance_ symbol for the _pair_, e.g. _BTCUSDT_.
+func ToSymbol(pair common.AssetPair) string {
+
+	return toBinanceAsset(pair.Asset.Normalize()) +
+		toBinanceAsset(pair.CostUnit.Normalize())
+
+}
+
+// FromSymbol splits a _Binance_ symbol such as _LTCUSDT_ into a normalized
+// `common.AssetPair`. If no known quote asset matches, it returns `false`.
+func FromSymbol(symbol string) (common.AssetPair, bool) {
+
+	symbol = strings.ToUpper(symbol)
+
+	for _, quote := range quoteAssets {
+
+		if len(symbol) > len(quote) && strings.HasSuffix(symbol, quote) {
+
+			return common.AssetPair{
+				Asset:    fromBinanceAsset(strings.TrimSuffix(symbol, quote)),
+				CostUnit: fromBinanceAsset(quote),
+			}, true
+
+		}
+
+	}
+
+	return common.AssetPair{}, false
+}
+
+func toBinanceAsset(asset common.AssetType) string {
+	return strings.ToUpper(string(asset))
+}
+
+func fromBinanceAsset(asset string) common.AssetType {
+
+	switch asset {
+	case "BCC", "BCHABC":
+		return common.AssetTypeBCH
+	}
+
+	return common.AssetType(asset).Normalize()
+}
@@ -0,0 +1,112 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/txhistory/httputils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPagesByStartTime(t *testing.T) {
+
+	day := int64(24 * 60 * 60 * 1000)
+	first := int64(1609459200000) // 2021-01-01
+	requests := []string{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		requests = append(requests, r.URL.RawQuery)
+
+		assert.Equal(t, "/klines", r.URL.Path)
+		assert.Equal(t, "LTCUSDT", r.URL.Query().Get("symbol"))
+		assert.Equal(t, "1d", r.URL.Query().Get("interval"))
+
+		start, _ := strconv.ParseInt(r.URL.Query().Get("startTime"), 10, 64)
+
+		// Five candles in total, page size is two
+		body := "["
+		for i := int64(0); i < 2; i++ {
+
+			open := start + i*day
+			if open >= first+5*day {
+				break
+			}
+
+			if i > 0 {
+				body += ","
+			}
+
+			body += fmt.Sprintf(
+				`[%d,"10.0","12.5","9.5","11.0","100.0",%d,"1100.0",42,"50.0","550.0","0"]`,
+				open, open+day-1,
+			)
+
+		}
+
+		w.Write([]byte(body + "]"))
+
+	}))
+
+	defer srv.Close()
+
+	reader := New(srv.URL).
+		UseClient(httputils.NewClient(srv.Client())).
+		UsePageSize(2)
+
+	reader.now = func() time.Time { return time.Unix(0, (first+30*day)*int64(time.Millisecond)) }
+
+	entries, err := reader.ReadContext(context.Background(), common.AssetPair{
+		Asset:    common.AssetTypeLTC,
+		CostUnit: common.AssetTypeUSDT,
+	}, time.Unix(first/1000, 0), time.Hour*24)
+
+	require.Equal(t, nil, err)
+	require.Equal(t, 5, len(entries))
+	assert.Equal(t, 3, len(requests))
+
+	assert.Equal(t, "2021-01-01T00:00:00Z", entries[0].DateTime.Format(time.RFC3339))
+	assert.Equal(t, "2021-01-05T00:00:00Z", entries[4].DateTime.Format(time.RFC3339))
+	assert.Equal(t, "bnc", entries[0].Exchange)
+	assert.Equal(t, "LTC-USDT", entries[0].AssetPair.String())
+	assert.Equal(t, 1440, entries[0].Resolution)
+	assert.Equal(t, float64(12.5), entries[0].High)
+	assert.Equal(t, float64(9.5), entries[0].Low)
+	assert.Equal(t, float64(100), entries[0].AssetVolume)
+	assert.Equal(t, float64(1100), entries[0].CostUnitVolume)
+}
+
+func TestIntervalMapsToLargestCandleNotExceeding(t *testing.T) {
+
+	assert.Equal(t, "1m", toCandleInterval(time.Second*30).name)
+	assert.Equal(t, "15m", toCandleInterval(time.Minute*20).name)
+	assert.Equal(t, "4h", toCandleInterval(time.Hour*4).name)
+	assert.Equal(t, "1d", toCandleInterval(time.Hour*48).name)
+	assert.Equal(t, "1w", toCandleInterval(time.Hour*24*31).name)
+}
+
+func TestSymbolNormalization(t *testing.T) {
+
+	assert.Equal(t, "BTCUSDT", ToSymbol(common.AssetPair{
+		Asset:    common.AssetType("XBT"),
+		CostUnit: common.AssetTypeUSDT,
+	}))
+
+	pair, ok := FromSymbol("BCCUSDT")
+	require.Equal(t, true, ok)
+	assert.Equal(t, "BCH-USDT", pair.String())
+
+	pair, ok = FromSymbol("ethbtc")
+	require.Equal(t, true, ok)
+	assert.Equal(t, "ETH-BTC", pair.String())
+
+	_, ok = FromSymbol("FOO")
+	assert.Equal(t, false, ok)
+}
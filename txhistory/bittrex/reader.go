@@ -1,19 +1,20 @@
 package bittrex
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"time"
 
 	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/txhistory/httputils"
 	"github.com/mariotoffia/gocryptoadmin/utils"
 )
 
 type Bittrex struct {
 	baseURL  string
 	exchange string
+	client   *httputils.Client
 }
 
 type Point struct {
@@ -26,6 +27,10 @@ type Point struct {
 	QuoteVolume string `json:"quoteVolume"`
 }
 
+// New creates a new _Bittrex_ reader.
+//
+// If _baseURL_ is empty, the public _Bittrex_ v3 API is used. By default, it
+// will use a `httputils.Client` that allows one request per second.
 func New(baseURL string) *Bittrex {
 
 	if baseURL == "" {
@@ -35,9 +40,18 @@ func New(baseURL string) *Bittrex {
 	return &Bittrex{
 		baseURL:  baseURL,
 		exchange: "btx",
+		client:   httputils.NewClient(nil).UseRateLimit(1, time.Second),
 	}
 }
 
+// UseClient replaces the default `httputils.Client`.
+func (btx *Bittrex) UseClient(client *httputils.Client) *Bittrex {
+
+	btx.client = client
+	return btx
+
+}
+
 func (btx *Bittrex) SetExchangeName(name string) {
 	btx.exchange = name
 }
@@ -48,6 +62,23 @@ func (btx *Bittrex) Read(
 	interval time.Duration,
 ) []common.TxOHCHistory {
 
+	list, err := btx.ReadContext(context.Background(), pair, since, interval)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return list
+}
+
+// ReadContext reads all historical reporting periods from _since_ until now.
+func (btx *Bittrex) ReadContext(
+	ctx context.Context,
+	pair common.AssetPair,
+	since time.Time,
+	interval time.Duration,
+) ([]common.TxOHCHistory, error) {
+
 	list := []common.TxOHCHistory{}
 
 	candleInterval := toCandleInterval(interval)
@@ -61,40 +92,36 @@ func (btx *Bittrex) Read(
 			period,
 		)
 
-		list = append(
-			list, btx.processRequest(req, pair, interval)...,
-		)
+		entries, err := btx.processRequest(ctx, req, pair, interval)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, entries...)
 
 	}
 
-	return list
+	return list, nil
 
 }
 
 func (btx *Bittrex) processRequest(
+	ctx context.Context,
 	req string,
 	pair common.AssetPair,
 	interval time.Duration,
-) []common.TxOHCHistory {
+) ([]common.TxOHCHistory, error) {
 
 	list := []common.TxOHCHistory{}
 
-	response, err := http.Get(req)
-
+	data, err := btx.client.Get(ctx, req)
 	if err != nil {
-		panic(err)
-	}
-
-	defer response.Body.Close()
-
-	data, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	var result []Point
 	if err = json.Unmarshal(data, &result); err != nil {
-		panic(fmt.Sprintf("data: %s err: %s", string(data), err.Error()))
+		return nil, fmt.Errorf("data: %s err: %s", string(data), err.Error())
 	}
 
 	for _, v := range result {
@@ -103,7 +130,7 @@ func (btx *Bittrex) processRequest(
 
 	}
 
-	return list
+	return list, nil
 
 }
 func (btx *Bittrex) toEntry(
@@ -1,13 +1,13 @@
 package coinbasepro
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"time"
 
 	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/txhistory/httputils"
 	"github.com/mariotoffia/gocryptoadmin/utils"
 )
 
@@ -19,6 +19,7 @@ type Coinbase struct {
 	baseURL  string
 	format   string
 	exchange string
+	client   *httputils.Client
 }
 
 type QueryRange struct {
@@ -27,6 +28,11 @@ type QueryRange struct {
 	granularity int
 }
 
+// New creates a new _Coinbase Pro_ reader.
+//
+// If _baseURL_ is empty, the public _Coinbase Pro_ API is used. By default, it
+// will use a `httputils.Client` that allows three requests per second (the
+// public API rate limit).
 func New(baseURL string) *Coinbase {
 
 	if baseURL == "" {
@@ -37,9 +43,18 @@ func New(baseURL string) *Coinbase {
 		baseURL:  baseURL,
 		exchange: "cbx",
 		format:   "2006-01-02T15:04:05Z",
+		client:   httputils.NewClient(nil).UseRateLimit(3, time.Second),
 	}
 }
 
+// UseClient replaces the default `httputils.Client`.
+func (cbx *Coinbase) UseClient(client *httputils.Client) *Coinbase {
+
+	cbx.client = client
+	return cbx
+
+}
+
 func (k *Coinbase) SetExchangeName(name string) {
 	k.exchange = name
 }
@@ -50,10 +65,28 @@ func (cbx *Coinbase) Read(
 	interval time.Duration,
 ) []common.TxOHCHistory {
 
+	list, err := cbx.ReadContext(context.Background(), pair, since, interval)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return list
+}
+
+// ReadContext reads all entries from _since_ until now in batches of 300 entries
+// (the maximum that the API delivers in one request).
+func (cbx *Coinbase) ReadContext(
+	ctx context.Context,
+	pair common.AssetPair,
+	since time.Time,
+	interval time.Duration,
+) ([]common.TxOHCHistory, error) {
+
 	granularity := interval / time.Second
 	if granularity > 86400 || granularity < 60 {
 
-		panic(
+		return nil, fmt.Errorf(
 			"interval must be the following seconds:" +
 				"{60, 300, 900, 3600, 21600, 86400}",
 		)
@@ -64,20 +97,24 @@ func (cbx *Coinbase) Read(
 
 	for _, qr := range cbx.calcRanges(since, int(granularity)) {
 
-		list = append(
-			list, cbx.getRange(pair, interval, &qr)...,
-		)
+		entries, err := cbx.getRange(ctx, pair, interval, &qr)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, entries...)
 
 	}
 
-	return list
+	return list, nil
 }
 
 func (cbx *Coinbase) getRange(
+	ctx context.Context,
 	pair common.AssetPair,
 	interval time.Duration,
 	qr *QueryRange,
-) []common.TxOHCHistory {
+) ([]common.TxOHCHistory, error) {
 
 	list := []common.TxOHCHistory{}
 
@@ -89,32 +126,23 @@ func (cbx *Coinbase) getRange(
 		qr.granularity,
 	)
 
-	response, err := http.Get(req)
-
+	data, err := cbx.client.Get(ctx, req)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	defer response.Body.Close()
-
-	data, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		panic(err)
-	}
-
-	var results []interface{}
+	var results [][]interface{}
 	if err = json.Unmarshal(data, &results); err != nil {
-		panic(fmt.Sprintf("data: %s, err: %s", string(data), err.Error()))
+		return nil, fmt.Errorf("data: %s, err: %s", string(data), err.Error())
 	}
 
-	for _, v := range results {
+	for _, ohlc := range results {
 
-		ohlc := v.([]interface{})
 		list = append(list, cbx.toEntry(ohlc, pair, interval))
 
 	}
 
-	return list
+	return list, nil
 }
 
 func (cbx *Coinbase) toEntry(
@@ -0,0 +1,381 @@
+package ecb
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/txhistory/httputils"
+	"github.com/mariotoffia/gocryptoadmin/utils"
+)
+
+// https://data-api.ecb.europa.eu/service/data/EXR/D.SEK.EUR.SP00.A?startPeriod=2017-08-31&format=csvdata
+// https://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist.zip (eurofxref-hist.csv)
+
+// Ecb reads the official _ECB_ euro foreign exchange reference rates.
+//
+// The rates are published once each banking day and are quoted as units
+// of currency per one _EUR_. Days without a published rate are filled with
+// the previous day rate.
+//
+// All pairs where _EUR_ is either asset or cost unit is supported. Other pairs,
+// e.g. _USD-SEK_, are calculated as cross rates via _EUR_.
+type Ecb struct {
+	baseURL  string
+	exchange string
+	file     string
+	client   *httputils.Client
+	now      func() time.Time
+}
+
+// Rates is keyed by date and currency and contains the number of units of
+// currency per one _EUR_.
+type Rates map[time.Time]map[common.AssetType]float64
+
+// New creates a new _ECB_ reader.
+//
+// If _baseURL_ is empty, the _ECB_ data portal API is used.
+func New(baseURL string) *Ecb {
+
+	if baseURL == "" {
+		baseURL = "https://data-api.ecb.europa.eu/service/data/EXR"
+	}
+
+	return &Ecb{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		exchange: "ecb",
+		client:   httputils.NewClient(nil).UseRateLimit(1, time.Second),
+		now:      time.Now,
+	}
+}
+
+// UseClient replaces the default `httputils.Client`.
+func (ecb *Ecb) UseClient(client *httputils.Client) *Ecb {
+
+	ecb.client = client
+	return ecb
+
+}
+
+// UseFile will read the rates from a downloaded file instead of the API.
+//
+// Both the _eurofxref-hist.csv_ (unzipped) and the data portal _csvdata_
+// formats are supported.
+func (ecb *Ecb) UseFile(path string) *Ecb {
+
+	ecb.file = path
+	return ecb
+
+}
+
+func (ecb *Ecb) SetExchangeName(name string) {
+	ecb.exchange = name
+}
+
+func (ecb *Ecb) Read(
+	pair common.AssetPair,
+	since time.Time,
+	interval time.Duration,
+) []common.TxOHCHistory {
+
+	list, err := ecb.ReadContext(context.Background(), pair, since, interval)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return list
+}
+
+// ReadContext reads the daily rates for _pair_ from _since_ until today.
+//
+// The _interval_ is ignored since the reference rates are only published
+// daily.
+func (ecb *Ecb) ReadContext(
+	ctx context.Context,
+	pair common.AssetPair,
+	since time.Time,
+	interval time.Duration,
+) ([]common.TxOHCHistory, error) {
+
+	currencies := []common.AssetType{}
+	for _, c := range []common.AssetType{pair.Asset, pair.CostUnit} {
+
+		if c != common.AssetTypeEuro {
+			currencies = append(currencies, c)
+		}
+
+	}
+
+	// Read earlier to have a rate for _since_ when not a banking day
+	from := since.AddDate(0, 0, -common.DailyRatesLookback)
+	rates := Rates{}
+
+	if ecb.file != "" {
+
+		data, err := ioutil.ReadFile(ecb.file)
+		if err != nil {
+			return nil, err
+		}
+
+		if rates, err = ParseCSV(data); err != nil {
+			return nil, err
+		}
+
+	} else {
+
+		for _, currency := range currencies {
+
+			req := fmt.Sprintf(
+				"%s/D.%s.EUR.SP00.A?startPeriod=%s&format=csvdata",
+				ecb.baseURL, currency, from.Format("2006-01-02"),
+			)
+
+			data, err := ecb.client.Get(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+
+			r, err := ParseCSV(data)
+			if err != nil {
+				return nil, err
+			}
+
+			rates.merge(r)
+
+		}
+
+	}
+
+	return common.FillDailyGapsSince(
+		ecb.toEntries(rates, pair, from), since, ecb.now(),
+	), nil
+}
+
+func (ecb *Ecb) toEntries(
+	rates Rates,
+	pair common.AssetPair,
+	since time.Time,
+) []common.TxOHCHistory {
+
+	since = time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.UTC)
+	list := []common.TxOHCHistory{}
+
+	for day, currencies := range rates {
+
+		if day.Before(since) {
+			continue
+		}
+
+		rate, ok := crossRate(currencies, pair)
+		if !ok {
+			continue
+		}
+
+		entry := common.TxOHCHistory{
+			Exchange:   ecb.exchange,
+			AssetPair:  pair,
+			DateTime:   day,
+			Resolution: 1440,
+			Open:       rate,
+			High:       rate,
+			Low:        rate,
+			Close:      rate,
+		}
+
+		entry.ID = utils.ToString(utils.HashFromTime(entry.DateTime))
+		list = append(list, entry)
+
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].DateTime.Before(list[j].DateTime)
+	})
+
+	return list
+}
+
+// crossRate returns the price of one _Asset_ in _CostUnit_.
+func crossRate(
+	currencies map[common.AssetType]float64,
+	pair common.AssetPair,
+) (float64, bool) {
+
+	perEUR := func(c common.AssetType) (float64, bool) {
+
+		if c == common.AssetTypeEuro {
+			return 1, true
+		}
+
+		r, ok := currencies[c]
+		return r, ok && r != 0
+
+	}
+
+	asset, ok := perEUR(pair.Asset)
+	if !ok {
+		return 0, false
+	}
+
+	costUnit, ok := perEUR(pair.CostUnit)
+	if !ok {
+		return 0, false
+	}
+
+	return costUnit / asset, true
+}
+
+func (r Rates) merge(other Rates) {
+
+	for day, currencies := range other {
+
+		if r[day] == nil {
+			r[day] = map[common.AssetType]float64{}
+		}
+
+		for c, v := range currencies {
+			r[day][c] = v
+		}
+
+	}
+
+}
+
+// ParseCSV parses either the _eurofxref-hist.csv_ format (one column per currency)
+// or the data portal _csvdata_ format (one row per observation).
+func ParseCSV(data []byte) (Rates, error) {
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	if len(header) > 0 && header[0] == "Date" {
+		return parseReferenceHistory(reader, header)
+	}
+
+	return parseDataPortal(reader, header)
+}
+
+func parseReferenceHistory(reader *csv.Reader, header []string) (Rates, error) {
+
+	rates := Rates{}
+
+	for {
+
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		day, err := time.Parse("2006-01-02", strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, err
+		}
+
+		currencies := map[common.AssetType]float64{}
+
+		for i := 1; i < len(record) && i < len(header); i++ {
+
+			if header[i] == "" {
+				continue
+			}
+
+			v, err := strconv.ParseFloat(strings.TrimSpace(record[i]), 64)
+			if err != nil {
+				continue // N/A
+			}
+
+			currencies[common.AssetType(header[i])] = v
+
+		}
+
+		rates[day] = currencies
+	}
+
+	return rates, nil
+}
+
+func parseDataPortal(reader *csv.Reader, header []string) (Rates, error) {
+
+	currency, period, value := -1, -1, -1
+
+	for i, h := range header {
+
+		switch h {
+		case "CURRENCY":
+			currency = i
+		case "TIME_PERIOD":
+			period = i
+		case "OBS_VALUE":
+			value = i
+		}
+
+	}
+
+	if currency == -1 || period == -1 || value == -1 {
+
+		return nil, fmt.Errorf(
+			"not a ECB csv file, missing CURRENCY, TIME_PERIOD or OBS_VALUE in header: %v",
+			header,
+		)
+
+	}
+
+	rates := Rates{}
+
+	for {
+
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if len(record) <= currency || len(record) <= period || len(record) <= value {
+
+			return nil, fmt.Errorf(
+				"ECB csv record has %d fields, expected at least %d: %v",
+				len(record), len(header), record,
+			)
+
+		}
+
+		day, err := time.Parse("2006-01-02", record[period])
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := strconv.ParseFloat(record[value], 64)
+		if err != nil {
+			continue // No observation
+		}
+
+		if rates[day] == nil {
+			rates[day] = map[common.AssetType]float64{}
+		}
+
+		rates[day][common.AssetType(record[currency])] = v
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,136 @@
+package ecb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/txhistory/httputils"
+	"github.com/mariotoffia/gocryptoadmin/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFromDataPortalFillsNonBankingDays(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		assert.Equal(t, "/D.SEK.EUR.SP00.A", r.URL.Path)
+		// Read earlier to have a rate when since is not a banking day
+		assert.Equal(t, "2020-12-28", r.URL.Query().Get("startPeriod"))
+		w.Write(utils.ReadFile("testfiles/csvdata-sek.csv"))
+
+	}))
+
+	defer srv.Close()
+
+	reader := New(srv.URL).UseClient(httputils.NewClient(srv.Client()))
+	reader.now = func() time.Time { return time.Date(2021, 1, 12, 15, 0, 0, 0, time.UTC) }
+
+	since, _ := time.Parse(time.RFC3339, "2021-01-07T00:00:00Z")
+
+	entries, err := reader.ReadContext(context.Background(), common.AssetPair{
+		Asset:    common.AssetTypeEuro,
+		CostUnit: common.AssetTypeSvenskKrona,
+	}, since, time.Hour*24)
+
+	require.Equal(t, nil, err)
+	require.Equal(t, 6, len(entries), "2021-01-07 -> 2021-01-12")
+
+	assert.Equal(t, float64(10.0655), entries[0].Close)
+	assert.Equal(t, float64(10.0743), entries[1].Close)
+
+	// Weekend uses friday rate
+	assert.Equal(t, "2021-01-09", entries[2].DateTime.Format("2006-01-02"))
+	assert.Equal(t, float64(10.0743), entries[2].Close)
+	assert.Equal(t, "2021-01-10", entries[3].DateTime.Format("2006-01-02"))
+	assert.Equal(t, float64(10.0743), entries[3].Close)
+
+	assert.Equal(t, float64(10.0963), entries[4].Close)
+	assert.Equal(t, "2021-01-12", entries[5].DateTime.Format("2006-01-02"))
+	assert.Equal(t, float64(10.0963), entries[5].Close)
+
+	assert.Equal(t, "ecb", entries[0].Exchange)
+	assert.Equal(t, 1440, entries[0].Resolution)
+}
+
+func TestReadSinceWeekendUsesRateBefore(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(utils.ReadFile("testfiles/csvdata-sek.csv"))
+	}))
+
+	defer srv.Close()
+
+	reader := New(srv.URL).UseClient(httputils.NewClient(srv.Client()))
+	reader.now = func() time.Time { return time.Date(2021, 1, 12, 15, 0, 0, 0, time.UTC) }
+
+	since, _ := time.Parse(time.RFC3339, "2021-01-09T00:00:00Z")
+
+	entries, err := reader.ReadContext(context.Background(), common.AssetPair{
+		Asset:    common.AssetTypeEuro,
+		CostUnit: common.AssetTypeSvenskKrona,
+	}, since, time.Hour*24)
+
+	require.Equal(t, nil, err)
+	require.Equal(t, 4, len(entries), "2021-01-09 -> 2021-01-12")
+
+	// Saturday and sunday uses friday rate
+	assert.Equal(t, "2021-01-09", entries[0].DateTime.Format("2006-01-02"))
+	assert.Equal(t, float64(10.0743), entries[0].Close)
+	assert.Equal(t, float64(10.0743), entries[1].Close)
+	assert.Equal(t, float64(10.0963), entries[2].Close)
+}
+
+func TestShortDataPortalRecordFails(t *testing.T) {
+
+	_, err := ParseCSV([]byte(
+		"KEY,FREQ,CURRENCY,CURRENCY_DENOM,EXR_TYPE,EXR_SUFFIX,TIME_PERIOD,OBS_VALUE,OBS_STATUS\n" +
+			"EXR.D.SEK.EUR.SP00.A,D,SEK,EUR,SP00,A,2021-01-07\n",
+	))
+
+	assert.EqualError(
+		t, err,
+		"ECB csv record has 7 fields, expected at least 9: [EXR.D.SEK.EUR.SP00.A D SEK EUR SP00 A 2021-01-07]",
+	)
+}
+
+func TestReadCrossRateFromReferenceHistoryFile(t *testing.T) {
+
+	reader := New("").UseFile("testfiles/eurofxref-hist.csv")
+	reader.now = func() time.Time { return time.Date(2021, 1, 8, 0, 0, 0, 0, time.UTC) }
+
+	since, _ := time.Parse(time.RFC3339, "2021-01-07T00:00:00Z")
+
+	entries, err := reader.ReadContext(context.Background(), common.AssetPair{
+		Asset:    common.AssetTypeUsDollar,
+		CostUnit: common.AssetTypeSvenskKrona,
+	}, since, time.Hour*24)
+
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, len(entries))
+
+	assert.Equal(t, "2021-01-07", entries[0].DateTime.Format("2006-01-02"))
+	assert.InDelta(t, 10.0655/1.2271, entries[0].Close, 1e-12)
+	assert.InDelta(t, 10.0743/1.2250, entries[1].Close, 1e-12)
+}
+
+func TestInvertedPairFromReferenceHistory(t *testing.T) {
+
+	rates, err := ParseCSV(utils.ReadFile("testfiles/eurofxref-hist.csv"))
+	require.Equal(t, nil, err)
+
+	day := time.Date(2021, 1, 6, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, 3, len(rates[day]), "N/A is skipped")
+
+	rate, ok := crossRate(rates[day], common.AssetPair{
+		Asset:    common.AssetTypeSvenskKrona,
+		CostUnit: common.AssetTypeEuro,
+	})
+
+	require.Equal(t, true, ok)
+	assert.InDelta(t, 1/10.0833, rate, 1e-12)
+}
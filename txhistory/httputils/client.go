@@ -0,0 +1,241 @@
+package httputils
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Client is a shared _HTTP_ layer for the price history readers.
+//
+// It wraps a `http.Client` and adds rate limiting, retries with exponential
+// backoff on _429_ and _5xx_ responses, context cancellation and optional
+// response recording.
+type Client struct {
+	client   *http.Client
+	limiter  *RateLimiter
+	recorder Recorder
+	retries  int
+	backoff  time.Duration
+	maxWait  time.Duration
+}
+
+// StatusError is returned when the server responded with a non _2xx_
+// status code that could not be resolved by retries.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+
+	return fmt.Sprintf(
+		"request: %s failed with status: %d, body: %s",
+		e.URL, e.StatusCode, string(e.Body),
+	)
+
+}
+
+// NewClient creates a new `Client` that uses _client_ for all requests.
+//
+// If _client_ is `nil`, a `http.Client` with a 30 second timeout is used. By
+// default it will retry five times, beginning with a one second backoff that
+// doubles on each retry (capped at one minute). No rate limit is applied
+// unless `UseRateLimit` is invoked.
+func NewClient(client *http.Client) *Client {
+
+	if client == nil {
+		client = &http.Client{Timeout: time.Second * 30}
+	}
+
+	return &Client{
+		client:  client,
+		retries: 5,
+		backoff: time.Second,
+		maxWait: time.Minute,
+	}
+
+}
+
+// UseHTTPClient replaces the underlying `http.Client`.
+func (c *Client) UseHTTPClient(client *http.Client) *Client {
+
+	c.client = client
+	return c
+
+}
+
+// UseRateLimit will allow at most _requests_ per _per_ duration. If _requests_
+// is equal or less than zero, the rate limit is removed.
+func (c *Client) UseRateLimit(requests int, per time.Duration) *Client {
+
+	if requests <= 0 {
+		c.limiter = nil
+		return c
+	}
+
+	c.limiter = NewRateLimiter(per / time.Duration(requests))
+	return c
+
+}
+
+// UseRetries sets the number of _retries_ on _429_ and _5xx_ responses and the
+// initial _backoff_. The backoff is doubled for each retry.
+func (c *Client) UseRetries(retries int, backoff time.Duration) *Client {
+
+	c.retries = retries
+	c.backoff = backoff
+	return c
+
+}
+
+// UseMaxBackoff caps the wait time between two retries.
+func (c *Client) UseMaxBackoff(max time.Duration) *Client {
+
+	c.maxWait = max
+	return c
+
+}
+
+// UseRecorder records all responses onto _recorder_. If `nil`, recording
+// is turned off.
+func (c *Client) UseRecorder(recorder Recorder) *Client {
+
+	c.recorder = recorder
+	return c
+
+}
+
+// Get performs a _GET_ request and returns the body.
+//
+// When the server responds with _429_ or any _5xx_, it will back off and retry
+// until the number of retries is exhausted. If the response carries a
+// _Retry-After_ header (in seconds) it is used as backoff for that retry.
+func (c *Client) Get(ctx context.Context, url string) ([]byte, error) {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	wait := c.backoff
+
+	for attempt := 0; ; attempt++ {
+
+		if c.limiter != nil {
+
+			if err := c.limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+
+		}
+
+		data, status, retryAfter, err := c.do(ctx, url)
+
+		if err != nil {
+
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			if attempt >= c.retries {
+				return nil, err
+			}
+
+		} else {
+
+			if c.recorder != nil {
+				c.recorder.Record(url, status, data)
+			}
+
+			if status >= 200 && status < 300 {
+				return data, nil
+			}
+
+			if !isRetryable(status) || attempt >= c.retries {
+
+				return nil, &StatusError{
+					URL:        url,
+					StatusCode: status,
+					Body:       data,
+				}
+
+			}
+
+		}
+
+		delay := wait
+		if retryAfter > 0 {
+			delay = retryAfter
+		}
+
+		if delay > c.maxWait {
+			delay = c.maxWait
+		}
+
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+
+		wait *= 2
+	}
+
+}
+
+func (c *Client) do(
+	ctx context.Context,
+	url string,
+) (data []byte, status int, retryAfter time.Duration, err error) {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	response, err := c.client.Do(req)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	defer response.Body.Close()
+
+	data, err = ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	if s := response.Header.Get("Retry-After"); s != "" {
+
+		if seconds, err := strconv.Atoi(s); err == nil {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+
+	}
+
+	return data, response.StatusCode, retryAfter, nil
+}
+
+func isRetryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+
+}
@@ -0,0 +1,125 @@
+package httputils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryOnTooManyRequestsAndServerError(t *testing.T) {
+
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write([]byte(`{"ok":true}`))
+		}
+
+	}))
+
+	defer srv.Close()
+
+	rec := NewMemoryRecorder()
+	client := NewClient(srv.Client()).
+		UseRetries(3, time.Millisecond).
+		UseRecorder(rec)
+
+	data, err := client.Get(context.Background(), srv.URL+"/candles")
+
+	require.Equal(t, nil, err)
+	assert.Equal(t, `{"ok":true}`, string(data))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	responses := rec.GetResponses()
+	require.Equal(t, 3, len(responses))
+	assert.Equal(t, http.StatusTooManyRequests, responses[0].Status)
+	assert.Equal(t, http.StatusBadGateway, responses[1].Status)
+	assert.Equal(t, http.StatusOK, responses[2].Status)
+}
+
+func TestNoRetryOnClientError(t *testing.T) {
+
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("bad pair"))
+
+	}))
+
+	defer srv.Close()
+
+	_, err := NewClient(srv.Client()).
+		UseRetries(3, time.Millisecond).
+		Get(context.Background(), srv.URL)
+
+	var serr *StatusError
+
+	require.Equal(t, true, errors.As(err, &serr))
+	assert.Equal(t, http.StatusBadRequest, serr.StatusCode)
+	assert.Equal(t, "bad pair", string(serr.Body))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestGiveUpWhenRetriesExhausted(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	defer srv.Close()
+
+	_, err := NewClient(srv.Client()).
+		UseRetries(2, time.Millisecond).
+		Get(context.Background(), srv.URL)
+
+	var serr *StatusError
+
+	require.Equal(t, true, errors.As(err, &serr))
+	assert.Equal(t, http.StatusServiceUnavailable, serr.StatusCode)
+}
+
+func TestCancelledContextStopsRetries(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	_, err := NewClient(srv.Client()).
+		UseRetries(100, time.Second).
+		Get(ctx, srv.URL)
+
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestRateLimiterSpacesRequests(t *testing.T) {
+
+	limiter := NewRateLimiter(time.Millisecond * 20)
+	start := time.Now()
+
+	for i := 0; i < 4; i++ {
+		require.Equal(t, nil, limiter.Wait(context.Background()))
+	}
+
+	assert.True(t, time.Since(start) >= time.Millisecond*60)
+}
@@ -0,0 +1,46 @@
+package httputils
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter spaces out requests so that two consecutive requests
+// are at least _interval_ apart.
+//
+// It is safe for concurrent use and may be shared between several
+// `Client` instances that talks to the same exchange.
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// NewRateLimiter creates a limiter that allows one request each _interval_.
+func NewRateLimiter(interval time.Duration) *RateLimiter {
+
+	return &RateLimiter{
+		interval: interval,
+	}
+
+}
+
+// Wait blocks until next request is allowed or the _ctx_ is done.
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+
+	rl.mu.Lock()
+
+	now := time.Now()
+	at := rl.next
+
+	if at.Before(now) {
+		at = now
+	}
+
+	rl.next = at.Add(rl.interval)
+	rl.mu.Unlock()
+
+	return sleep(ctx, time.Until(at))
+
+}
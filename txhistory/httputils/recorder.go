@@ -0,0 +1,103 @@
+package httputils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/mariotoffia/gocryptoadmin/utils"
+)
+
+// Recorder receives each response that the `Client` gets.
+//
+// This is useful to capture fixture responses that later on
+// is served by a `httptest.Server` in unit tests.
+type Recorder interface {
+	Record(url string, status int, body []byte)
+}
+
+// RecordedResponse is a single response captured by the `MemoryRecorder`.
+type RecordedResponse struct {
+	URL    string
+	Status int
+	Body   []byte
+}
+
+// MemoryRecorder keeps all responses in memory.
+type MemoryRecorder struct {
+	mu        sync.Mutex
+	responses []RecordedResponse
+}
+
+func NewMemoryRecorder() *MemoryRecorder {
+	return &MemoryRecorder{}
+}
+
+func (rec *MemoryRecorder) Record(url string, status int, body []byte) {
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	rec.responses = append(rec.responses, RecordedResponse{
+		URL:    url,
+		Status: status,
+		Body:   body,
+	})
+
+}
+
+// GetResponses returns a copy of all recorded responses in the order they
+// were recorded.
+func (rec *MemoryRecorder) GetResponses() []RecordedResponse {
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	return append([]RecordedResponse{}, rec.responses...)
+
+}
+
+// DirRecorder writes each successful response body into a directory.
+//
+// The file name is the hash of the _URL_ and the extension is _.json_, e.g.
+// _kraken_2740176395.json_ when prefix is _kraken_.
+type DirRecorder struct {
+	dir    string
+	prefix string
+}
+
+// NewDirRecorder creates a recorder that stores responses into _dir_. The
+// directory is created if it does not exist.
+func NewDirRecorder(dir, prefix string) *DirRecorder {
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		panic(err)
+	}
+
+	return &DirRecorder{
+		dir:    dir,
+		prefix: prefix,
+	}
+
+}
+
+func (rec *DirRecorder) Record(url string, status int, body []byte) {
+
+	if status < 200 || status >= 300 {
+		return
+	}
+
+	file := filepath.Join(rec.dir, FixtureFileName(rec.prefix, url))
+
+	if err := ioutil.WriteFile(file, body, 0644); err != nil {
+		panic(err)
+	}
+
+}
+
+// FixtureFileName renders the file name used by `DirRecorder` for _url_.
+func FixtureFileName(prefix, url string) string {
+	return fmt.Sprintf("%s_%d.json", prefix, utils.HashFromString(url))
+}
@@ -1,13 +1,14 @@
 package kraken
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
+	"strings"
 	"time"
 
 	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/txhistory/httputils"
 	"github.com/mariotoffia/gocryptoadmin/utils"
 )
 
@@ -15,20 +16,35 @@ import (
 type Kraken struct {
 	baseURL  string
 	exchange string
+	client   *httputils.Client
 }
 
+// New creates a new _Kraken_ reader.
+//
+// If _baseURL_ is empty, the public _Kraken_ API is used. By default, it will
+// use a `httputils.Client` that allows one request per second (the public API
+// rate limit).
 func New(baseURL string) *Kraken {
 
 	if baseURL == "" {
-		baseURL = "https://api.kraken.com/0/public/"
+		baseURL = "https://api.kraken.com/0/public"
 	}
 
 	return &Kraken{
-		baseURL:  baseURL,
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
 		exchange: "kraken",
+		client:   httputils.NewClient(nil).UseRateLimit(1, time.Second),
 	}
 }
 
+// UseClient replaces the default `httputils.Client`.
+func (k *Kraken) UseClient(client *httputils.Client) *Kraken {
+
+	k.client = client
+	return k
+
+}
+
 func (k *Kraken) SetExchangeName(name string) {
 	k.exchange = name
 }
@@ -39,51 +55,119 @@ func (k *Kraken) Read(
 	interval time.Duration,
 ) []common.TxOHCHistory {
 
+	list, err := k.ReadContext(context.Background(), pair, since, interval)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return list
+}
+
+// ReadContext reads all entries from _since_ until now.
+//
+// The _Kraken_ API returns at most 720 entries per request and a _last_ cursor
+// that is used as _since_ in next request. It will continue to page until no
+// new entries are returned.
+func (k *Kraken) ReadContext(
+	ctx context.Context,
+	pair common.AssetPair,
+	since time.Time,
+	interval time.Duration,
+) ([]common.TxOHCHistory, error) {
+
 	list := []common.TxOHCHistory{}
+	cursor := since.Unix()
+	seen := map[int64]bool{}
 
-	req := fmt.Sprintf(
-		"%s/OHLC?pair=%s%s&interval=%d&since=%d",
-		k.baseURL, pair.Asset, pair.CostUnit,
-		interval/time.Minute,
-		since.Unix(),
-	)
+	for {
 
-	response, err := http.Get(req)
+		req := fmt.Sprintf(
+			"%s/OHLC?pair=%s%s&interval=%d&since=%d",
+			k.baseURL, pair.Asset, pair.CostUnit,
+			interval/time.Minute,
+			cursor,
+		)
 
-	if err != nil {
-		panic(err)
+		data, err := k.client.Get(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		entries, last, err := k.parse(data, pair, interval)
+		if err != nil {
+			return nil, err
+		}
+
+		added := 0
+		for i := range entries {
+
+			ts := entries[i].DateTime.Unix()
+
+			if seen[ts] {
+				continue
+			}
+
+			seen[ts] = true
+			list = append(list, entries[i])
+			added++
+
+		}
+
+		if added == 0 || last <= cursor {
+			break
+		}
+
+		cursor = last
 	}
 
-	defer response.Body.Close()
+	return list, nil
+}
 
-	data, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		panic(err)
+func (k *Kraken) parse(
+	data []byte,
+	pair common.AssetPair,
+	interval time.Duration,
+) ([]common.TxOHCHistory, int64, error) {
+
+	var m struct {
+		Error  []string                   `json:"error"`
+		Result map[string]json.RawMessage `json:"result"`
 	}
 
-	var m map[string]interface{}
-	if err = json.Unmarshal(data, &m); err != nil {
-		panic(err)
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, 0, fmt.Errorf("data: %s, err: %s", string(data), err.Error())
+	}
+
+	if len(m.Error) > 0 {
+		return nil, 0, fmt.Errorf("kraken: %s", strings.Join(m.Error, ", "))
 	}
 
-	results := m["result"].(map[string]interface{})
+	list := []common.TxOHCHistory{}
+	last := int64(0)
 
-	for name, v := range results {
+	for name, v := range m.Result {
 
 		if name == "last" {
+
+			if err := json.Unmarshal(v, &last); err != nil {
+				return nil, 0, err
+			}
+
 			continue
 		}
 
-		ohlc := v.([]interface{})
-		for i := range ohlc {
-
-			arr := ohlc[i].([]interface{})
-			list = append(list, k.toEntry(arr, pair, interval))
+		var ohlc [][]interface{}
+		if err := json.Unmarshal(v, &ohlc); err != nil {
+			return nil, 0, err
+		}
 
+		for i := range ohlc {
+			list = append(list, k.toEntry(ohlc[i], pair, interval))
 		}
 	}
 
-	return list
+	return list, last, nil
 }
 
 func (k *Kraken) toEntry(
@@ -0,0 +1,89 @@
+package kraken
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/txhistory/httputils"
+	"github.com/mariotoffia/gocryptoadmin/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fixtureServer(t *testing.T) *httptest.Server {
+
+	pages := map[string]string{
+		"1609459200": "testfiles/ohlc-page1.json",
+		"1609545600": "testfiles/ohlc-page2.json",
+		"1609632000": "testfiles/ohlc-page3.json",
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		assert.Equal(t, "/OHLC", r.URL.Path)
+		assert.Equal(t, "BTCEUR", r.URL.Query().Get("pair"))
+		assert.Equal(t, "1440", r.URL.Query().Get("interval"))
+
+		file, ok := pages[r.URL.Query().Get("since")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Write(utils.ReadFile(file))
+
+	}))
+
+}
+
+func TestReadPagesUntilNoNewEntries(t *testing.T) {
+
+	srv := fixtureServer(t)
+	defer srv.Close()
+
+	reader := New(srv.URL + "/").UseClient(
+		httputils.NewClient(srv.Client()).UseRetries(0, time.Millisecond),
+	)
+
+	reader.SetExchangeName("kr")
+
+	since := time.Unix(1609459200, 0).UTC()
+
+	entries, err := reader.ReadContext(context.Background(), common.AssetPair{
+		Asset:    common.AssetTypeBTC,
+		CostUnit: common.AssetTypeEuro,
+	}, since, time.Hour*24)
+
+	require.Equal(t, nil, err)
+	require.Equal(t, 3, len(entries), "duplicate entries across pages are removed")
+
+	assert.Equal(t, "2021-01-01T00:00:00Z", entries[0].DateTime.Format(time.RFC3339))
+	assert.Equal(t, "2021-01-03T00:00:00Z", entries[2].DateTime.Format(time.RFC3339))
+	assert.Equal(t, "kr", entries[0].Exchange)
+	assert.Equal(t, 1440, entries[0].Resolution)
+	assert.Equal(t, float64(26000), entries[1].High)
+	assert.Equal(t, float64(24100), entries[1].Low)
+}
+
+func TestReadReportsKrakenError(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":["EQuery:Unknown asset pair"]}`))
+	}))
+
+	defer srv.Close()
+
+	reader := New(srv.URL).UseClient(httputils.NewClient(srv.Client()))
+
+	_, err := reader.ReadContext(context.Background(), common.AssetPair{
+		Asset:    common.AssetTypeBTC,
+		CostUnit: common.AssetTypeEuro,
+	}, time.Unix(1609459200, 0), time.Hour*24)
+
+	require.NotEqual(t, nil, err)
+	assert.Equal(t, "kraken: EQuery:Unknown asset pair", err.Error())
+}
@@ -1,13 +1,13 @@
 package ofx
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"time"
 
 	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/txhistory/httputils"
 	"github.com/mariotoffia/gocryptoadmin/utils"
 )
 
@@ -16,6 +16,7 @@ import (
 type Ofx struct {
 	baseURL  string
 	exchange string
+	client   *httputils.Client
 }
 
 type Point struct {
@@ -31,6 +32,10 @@ type Response struct {
 	Historical         []Point `json:"HistoricalPoints"`
 }
 
+// New creates a new _OFX_ reader.
+//
+// If _baseURL_ is empty, the public _OFX_ spot rate history API is used. By
+// default, it will use a `httputils.Client` that allows one request per second.
 func New(baseURL string) *Ofx {
 
 	if baseURL == "" {
@@ -40,9 +45,18 @@ func New(baseURL string) *Ofx {
 	return &Ofx{
 		baseURL:  baseURL,
 		exchange: "ofx",
+		client:   httputils.NewClient(nil).UseRateLimit(1, time.Second),
 	}
 }
 
+// UseClient replaces the default `httputils.Client`.
+func (ofx *Ofx) UseClient(client *httputils.Client) *Ofx {
+
+	ofx.client = client
+	return ofx
+
+}
+
 func (ofx *Ofx) SetExchangeName(name string) {
 	ofx.exchange = name
 }
@@ -101,6 +115,22 @@ func (ofx *Ofx) Read(
 	interval time.Duration,
 ) []common.TxOHCHistory {
 
+	list, err := ofx.ReadContext(context.Background(), pair, since, interval)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return list
+}
+
+func (ofx *Ofx) ReadContext(
+	ctx context.Context,
+	pair common.AssetPair,
+	since time.Time,
+	interval time.Duration,
+) ([]common.TxOHCHistory, error) {
+
 	list := []common.TxOHCHistory{}
 
 	req := fmt.Sprintf(
@@ -110,22 +140,14 @@ func (ofx *Ofx) Read(
 		toReportingInterval(interval),
 	)
 
-	response, err := http.Get(req)
-
-	if err != nil {
-		panic(err)
-	}
-
-	defer response.Body.Close()
-
-	data, err := ioutil.ReadAll(response.Body)
+	data, err := ofx.client.Get(ctx, req)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	var result Response
 	if err = json.Unmarshal(data, &result); err != nil {
-		panic(err)
+		return nil, fmt.Errorf("data: %s, err: %s", string(data), err.Error())
 	}
 
 	for _, v := range result.Historical {
@@ -134,7 +156,7 @@ func (ofx *Ofx) Read(
 
 	}
 
-	return list
+	return list, nil
 }
 
 func (ofx *Ofx) toEntry(
@@ -0,0 +1,93 @@
+package txhistory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/utils"
+)
+
+// Resample aggregates _entries_ into candles of _resolution_ minutes.
+//
+// Each resulting candle gets the open of the first entry, the close of the last
+// entry, the max high, the min low and the summed volumes of all entries that
+// starts within the candle period.
+//
+// Entries of coarser resolution than _resolution_ are skipped. The candles are
+// aligned on the zero time, i.e. days starts at midnight _UTC_ and weeks on a
+// monday.
+//
+// NOTE: The _entries_ must be of the same exchange and `common.AssetPair`.
+func Resample(entries []common.TxOHCHistory, resolution int) []common.TxOHCHistory {
+
+	period := time.Duration(resolution) * time.Minute
+
+	fine := make([]common.TxOHCHistory, 0, len(entries))
+	for i := range entries {
+
+		if entries[i].Resolution <= resolution {
+			fine = append(fine, entries[i])
+		}
+
+	}
+
+	sort.SliceStable(fine, func(i, j int) bool {
+		return fine[i].DateTime.Before(fine[j].DateTime)
+	})
+
+	list := []common.TxOHCHistory{}
+
+	for i := range fine {
+
+		start := fine[i].DateTime.UTC().Truncate(period)
+
+		if len(list) > 0 && list[len(list)-1].DateTime.Equal(start) {
+
+			candle := &list[len(list)-1]
+
+			if fine[i].High > candle.High {
+				candle.High = fine[i].High
+			}
+
+			if fine[i].Low < candle.Low {
+				candle.Low = fine[i].Low
+			}
+
+			candle.Close = fine[i].Close
+			candle.AssetVolume += fine[i].AssetVolume
+			candle.CostUnitVolume += fine[i].CostUnitVolume
+
+			continue
+		}
+
+		candle := fine[i]
+		candle.DateTime = start
+		candle.Resolution = resolution
+		candle.ID = utils.ToString(utils.HashFromTime(start))
+
+		list = append(list, candle)
+
+	}
+
+	return list
+}
+
+// resolutionsOf returns the distinct resolutions in _entries_ in ascending order.
+func resolutionsOf(entries []common.TxOHCHistory) []int {
+
+	seen := map[int]bool{}
+	resolutions := []int{}
+
+	for i := range entries {
+
+		if !seen[entries[i].Resolution] {
+			seen[entries[i].Resolution] = true
+			resolutions = append(resolutions, entries[i].Resolution)
+		}
+
+	}
+
+	sort.Ints(resolutions)
+	return resolutions
+}
@@ -0,0 +1,126 @@
+package txhistory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPair = common.AssetPair{
+	Asset:    common.AssetTypeBTC,
+	CostUnit: common.AssetTypeEuro,
+}
+
+func hourly(start time.Time, hours int) []common.TxOHCHistory {
+
+	list := []common.TxOHCHistory{}
+
+	for i := 0; i < hours; i++ {
+
+		list = append(list, common.TxOHCHistory{
+			Exchange:       "kr",
+			AssetPair:      testPair,
+			Resolution:     60,
+			DateTime:       start.Add(time.Duration(i) * time.Hour),
+			Open:           float64(100 + i),
+			High:           float64(110 + i),
+			Low:            float64(90 + i),
+			Close:          float64(101 + i),
+			AssetVolume:    1,
+			CostUnitVolume: 100,
+		})
+
+	}
+
+	return list
+}
+
+func TestResampleHourlyToDaily(t *testing.T) {
+
+	start, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+
+	days := Resample(hourly(start, 48), 1440)
+
+	require.Equal(t, 2, len(days))
+
+	assert.Equal(t, "2021-01-01T00:00:00Z", days[0].DateTime.Format(time.RFC3339))
+	assert.Equal(t, 1440, days[0].Resolution)
+	assert.Equal(t, float64(100), days[0].Open, "open of first")
+	assert.Equal(t, float64(124), days[0].Close, "close of last")
+	assert.Equal(t, float64(133), days[0].High, "max high")
+	assert.Equal(t, float64(90), days[0].Low, "min low")
+	assert.Equal(t, float64(24), days[0].AssetVolume)
+	assert.Equal(t, float64(2400), days[0].CostUnitVolume)
+
+	assert.Equal(t, "2021-01-02T00:00:00Z", days[1].DateTime.Format(time.RFC3339))
+	assert.Equal(t, float64(124), days[1].Open)
+}
+
+func TestResampleWeeksStartsOnMonday(t *testing.T) {
+
+	// 2021-01-01 is a friday
+	start, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+
+	weeks := Resample(hourly(start, 24*4), 10080)
+
+	require.Equal(t, 2, len(weeks))
+	assert.Equal(t, "2020-12-28T00:00:00Z", weeks[0].DateTime.Format(time.RFC3339))
+	assert.Equal(t, "2021-01-04T00:00:00Z", weeks[1].DateTime.Format(time.RFC3339))
+}
+
+func TestPreferredResolutionFallsBackToFinerAndCoarser(t *testing.T) {
+
+	start, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+	weekly := common.TxOHCHistory{
+		Exchange:   "kr",
+		AssetPair:  testPair,
+		Resolution: 10080,
+		DateTime:   start.AddDate(0, 0, -4),
+		Open:       1, High: 2, Low: 0.5, Close: 1.5,
+	}
+
+	cache := NewTxOHCCache().
+		Add(hourly(start, 48)).
+		Add([]common.TxOHCHistory{weekly})
+
+	assert.Equal(t, []int{60, 10080}, cache.GetResolutions(testPair, "kr"))
+
+	at, _ := time.Parse(time.RFC3339, "2021-01-02T13:30:00Z")
+
+	// Exact
+	entry, ex := cache.GetEntryForAssetWithResolution(testPair, at, 60, "kr")
+	require.NotNil(t, entry)
+	assert.Equal(t, "kr", ex)
+	assert.Equal(t, 60, entry.Resolution)
+	assert.Equal(t, "2021-01-02T13:00:00Z", entry.DateTime.Format(time.RFC3339))
+
+	// Finer resampled
+	entry, _ = cache.GetEntryForAssetWithResolution(testPair, at, 1440, "kr")
+	require.NotNil(t, entry)
+	assert.Equal(t, 1440, entry.Resolution)
+	assert.Equal(t, "2021-01-02T00:00:00Z", entry.DateTime.Format(time.RFC3339))
+	assert.Equal(t, float64(124), entry.Open)
+
+	first, _ := cache.GetEntryForAssetWithResolution(testPair, start, 1440, "kr")
+	next, ok := cache.GetNextEntry(first, "kr")
+	require.True(t, ok)
+	assert.Equal(t, entry.DateTime, next.DateTime)
+
+	_, ok = cache.GetNextEntry(entry, "kr")
+	assert.False(t, ok, "no entries after last day")
+
+	// Coarser when no finer is available
+	before, _ := time.Parse(time.RFC3339, "2020-12-30T12:00:00Z")
+	entry, _ = cache.GetEntryForAssetWithResolution(testPair, before, 1440, "kr")
+	require.NotNil(t, entry)
+	assert.Equal(t, 10080, entry.Resolution)
+
+	// Preferred resolution is used by the plain lookup
+	entry, _ = cache.UsePreferredResolution(240).GetEntryForAssset(testPair, at, "kr")
+	require.NotNil(t, entry)
+	assert.Equal(t, 240, entry.Resolution)
+	assert.Equal(t, "2021-01-02T12:00:00Z", entry.DateTime.Format(time.RFC3339))
+}
@@ -0,0 +1,116 @@
+package txhistory
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/parsers"
+)
+
+// ResolverValidationError is a single problem found by `ValidateResolverExpressions`.
+type ResolverValidationError struct {
+	parsers.Position
+	Message string
+}
+
+func (e ResolverValidationError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// ResolverValidationErrors are all problems found by `ValidateResolverExpressions`.
+type ResolverValidationErrors []ResolverValidationError
+
+func (e ResolverValidationErrors) Error() string {
+
+	msg := make([]string, len(e))
+	for i := range e {
+		msg[i] = e[i].Error()
+	}
+
+	return strings.Join(msg, "\n")
+}
+
+// ValidateResolverExpressions checks that all exchanges and asset pairs, referenced
+// by the _expr_, exists in the _cache_.
+//
+// Exchange prefixes, except _all_ and _*_, must exist in the cache. Each path item
+// must have its asset pair in at least one of its exchanges, where _*_ matches any
+// exchange.
+//
+// If everything is fine, `nil` is returned, otherwise `ResolverValidationErrors`.
+func ValidateResolverExpressions(
+	cache *TxOHCCache,
+	expr ...parsers.ResolverExpression,
+) error {
+
+	errs := ResolverValidationErrors{}
+
+	known := map[string]bool{}
+	for _, ex := range cache.GetExchanges() {
+		known[ex] = true
+	}
+
+	checkPrefixes := func(pos parsers.Position, prefixes []string) {
+
+		for _, prefix := range prefixes {
+
+			if prefix == common.ExchangeAll || prefix == common.ExchangeAny || known[prefix] {
+				continue
+			}
+
+			errs = append(errs, ResolverValidationError{
+				Position: pos,
+				Message:  fmt.Sprintf("unknown exchange: %s", prefix),
+			})
+
+		}
+
+	}
+
+	for _, e := range expr {
+
+		checkPrefixes(e.Position, e.AssetPrefixes)
+
+		for _, path := range e.Path {
+
+			checkPrefixes(path.Position, path.AssetPrefixes)
+
+			if !cache.hasAssetPair(path.AssetPair, path.AssetPrefixes...) {
+
+				errs = append(errs, ResolverValidationError{
+					Position: path.Position,
+					Message: fmt.Sprintf(
+						"asset pair: %s do not exist in exchange(s): %s",
+						path.AssetPair.String(), strings.Join(path.AssetPrefixes, ","),
+					),
+				})
+
+			}
+
+		}
+
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+// hasAssetPair returns `true` if any of the _exchange_ has entries for _assetPair_.
+func (cache *TxOHCCache) hasAssetPair(assetPair common.AssetPair, exchange ...string) bool {
+
+	ap := assetPair.String()
+
+	for _, ex := range cache.expandExchanges(exchange) {
+
+		if entries, ok := cache.entries[ex]; ok && len(entries.entries[ap]) > 0 {
+			return true
+		}
+
+	}
+
+	return false
+}
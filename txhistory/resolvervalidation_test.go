@@ -0,0 +1,42 @@
+package txhistory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/parsers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateResolverExpressions(t *testing.T) {
+
+	start, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+	cache := NewTxOHCCache().Add(hourly(start, 2))
+
+	expr := parsers.NewResolverParser().
+		Parse("kr:BTC = kr:EUR").
+		Parse("*:BTC = *:EUR").
+		Parse("# comment\nETH = kr:BTC\nxyz:BTC = kr:EUR").
+		GetExpressions()
+
+	err := ValidateResolverExpressions(cache, expr...)
+	require.NotNil(t, err)
+
+	errs := err.(ResolverValidationErrors)
+	require.Equal(t, 2, len(errs))
+	assert.Equal(t, "line 2, column 7: asset pair: ETH-BTC do not exist in exchange(s): kr", errs[0].Error())
+	assert.Equal(t, "line 3, column 1: unknown exchange: xyz", errs[1].Error())
+
+	assert.Nil(t, ValidateResolverExpressions(cache, expr[:2]...))
+
+	// Wildcard resolves on any exchange
+	resolver := NewTxOHCResolver(cache).AddTranslations(expr[1])
+	res, ok := resolver.ResolveToTarget(
+		start.Add(time.Minute), common.AssetTypeBTC, common.AssetTypeEuro, "other",
+	)
+
+	require.True(t, ok)
+	assert.Equal(t, "kr", res[0].Exchange)
+}
@@ -0,0 +1,410 @@
+package riksbank
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/txhistory/httputils"
+	"github.com/mariotoffia/gocryptoadmin/utils"
+)
+
+// https://api.riksbank.se/swea/v1/Observations/SEKEURPMI/2017-08-31/2021-05-24
+
+// Riksbank reads the official _SWEA_ exchange rates against _SEK_.
+//
+// Each currency is a series named _SEK<currency>PMI_ that is quoted as _SEK_ per
+// unit of the currency. Days without a published rate are filled with the
+// previous day rate.
+//
+// All pairs where _SEK_ is either asset or cost unit is supported. Other pairs,
+// e.g. _USD-EUR_, are calculated as cross rates via _SEK_.
+type Riksbank struct {
+	baseURL  string
+	exchange string
+	files    map[common.AssetType]string
+	units    map[common.AssetType]float64
+	client   *httputils.Client
+	now      func() time.Time
+}
+
+// Observation is a single daily rate.
+type Observation struct {
+	Date  string  `json:"date"  xml:"date"`
+	Value float64 `json:"value" xml:"value"`
+}
+
+// New creates a new _Riksbank_ reader.
+//
+// If _baseURL_ is empty, the public _SWEA_ API is used.
+func New(baseURL string) *Riksbank {
+
+	if baseURL == "" {
+		baseURL = "https://api.riksbank.se/swea/v1"
+	}
+
+	return &Riksbank{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		exchange: "rbk",
+		files:    map[common.AssetType]string{},
+		units:    map[common.AssetType]float64{},
+		client:   httputils.NewClient(nil).UseRateLimit(1, time.Second),
+		now:      time.Now,
+	}
+}
+
+// UseClient replaces the default `httputils.Client`.
+func (rb *Riksbank) UseClient(client *httputils.Client) *Riksbank {
+
+	rb.client = client
+	return rb
+
+}
+
+// UseFile will read the _currency_ series from a downloaded file instead of the API.
+//
+// The file may be the _CSV_ export from the _Riksbank_ web site (semicolon or
+// comma separated, decimal comma is allowed), the _SWEA_ _JSON_ or the _XML_
+// result rows from the _SWEA_ web service.
+func (rb *Riksbank) UseFile(currency common.AssetType, path string) *Riksbank {
+
+	rb.files[currency] = path
+	return rb
+
+}
+
+// UseSeriesUnit is used when a series is quoted per e.g. 100 units of the
+// _currency_ instead of one.
+func (rb *Riksbank) UseSeriesUnit(currency common.AssetType, unit float64) *Riksbank {
+
+	rb.units[currency] = unit
+	return rb
+
+}
+
+func (rb *Riksbank) SetExchangeName(name string) {
+	rb.exchange = name
+}
+
+func (rb *Riksbank) Read(
+	pair common.AssetPair,
+	since time.Time,
+	interval time.Duration,
+) []common.TxOHCHistory {
+
+	list, err := rb.ReadContext(context.Background(), pair, since, interval)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return list
+}
+
+// ReadContext reads the daily rates for _pair_ from _since_ until today.
+//
+// The _interval_ is ignored since the rates are only published daily.
+func (rb *Riksbank) ReadContext(
+	ctx context.Context,
+	pair common.AssetPair,
+	since time.Time,
+	interval time.Duration,
+) ([]common.TxOHCHistory, error) {
+
+	since = time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.UTC)
+
+	// Read earlier to have a rate for _since_ when not a banking day
+	from := since.AddDate(0, 0, -common.DailyRatesLookback)
+
+	series := map[common.AssetType]map[time.Time]float64{}
+
+	for _, currency := range []common.AssetType{pair.Asset, pair.CostUnit} {
+
+		if currency == common.AssetTypeSvenskKrona {
+			continue
+		}
+
+		observations, err := rb.readSeries(ctx, currency, from)
+		if err != nil {
+			return nil, err
+		}
+
+		unit := rb.units[currency]
+		if unit == 0 {
+			unit = 1
+		}
+
+		rates := map[time.Time]float64{}
+		for _, obs := range observations {
+
+			day, err := time.Parse("2006-01-02", obs.Date)
+			if err != nil {
+				return nil, err
+			}
+
+			rates[day] = obs.Value / unit
+
+		}
+
+		series[currency] = rates
+	}
+
+	return common.FillDailyGapsSince(rb.toEntries(series, pair, from), since, rb.now()), nil
+}
+
+func (rb *Riksbank) readSeries(
+	ctx context.Context,
+	currency common.AssetType,
+	since time.Time,
+) ([]Observation, error) {
+
+	if file, ok := rb.files[currency]; ok {
+
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		return Parse(data)
+	}
+
+	req := fmt.Sprintf(
+		"%s/Observations/%s/%s/%s",
+		rb.baseURL, SeriesID(currency),
+		since.Format("2006-01-02"),
+		rb.now().UTC().Format("2006-01-02"),
+	)
+
+	data, err := rb.client.Get(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(data)
+}
+
+func (rb *Riksbank) toEntries(
+	series map[common.AssetType]map[time.Time]float64,
+	pair common.AssetPair,
+	since time.Time,
+) []common.TxOHCHistory {
+
+	// SEK per unit of currency
+	perSEK := func(c common.AssetType, day time.Time) (float64, bool) {
+
+		if c == common.AssetTypeSvenskKrona {
+			return 1, true
+		}
+
+		r, ok := series[c][day]
+		return r, ok && r != 0
+
+	}
+
+	days := map[time.Time]bool{}
+	for _, rates := range series {
+
+		for day := range rates {
+			days[day] = true
+		}
+
+	}
+
+	list := []common.TxOHCHistory{}
+
+	for day := range days {
+
+		if day.Before(since) {
+			continue
+		}
+
+		asset, ok := perSEK(pair.Asset, day)
+		if !ok {
+			continue
+		}
+
+		costUnit, ok := perSEK(pair.CostUnit, day)
+		if !ok {
+			continue
+		}
+
+		rate := asset / costUnit
+
+		entry := common.TxOHCHistory{
+			Exchange:   rb.exchange,
+			AssetPair:  pair,
+			DateTime:   day,
+			Resolution: 1440,
+			Open:       rate,
+			High:       rate,
+			Low:        rate,
+			Close:      rate,
+		}
+
+		entry.ID = utils.ToString(utils.HashFromTime(entry.DateTime))
+		list = append(list, entry)
+
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].DateTime.Before(list[j].DateTime)
+	})
+
+	return list
+}
+
+// SeriesID returns the _SWEA_ series identifier for _currency_, e.g. _SEKEURPMI_.
+func SeriesID(currency common.AssetType) string {
+	return fmt.Sprintf("SEK%sPMI", strings.ToUpper(string(currency)))
+}
+
+// Parse detects the format (_JSON_, _XML_ or _CSV_) and parses the observations.
+func Parse(data []byte) ([]Observation, error) {
+
+	trimmed := bytes.TrimSpace(data)
+
+	if len(trimmed) == 0 {
+		return []Observation{}, nil
+	}
+
+	switch trimmed[0] {
+	case '[':
+		return parseJSON(trimmed)
+	case '<':
+		return parseXML(trimmed)
+	}
+
+	return parseCSV(trimmed)
+}
+
+func parseJSON(data []byte) ([]Observation, error) {
+
+	var observations []Observation
+	if err := json.Unmarshal(data, &observations); err != nil {
+		return nil, fmt.Errorf("data: %s, err: %s", string(data), err.Error())
+	}
+
+	return observations, nil
+}
+
+// parseXML parses the _resultrows_ elements, wherever they occur in the document.
+func parseXML(data []byte) ([]Observation, error) {
+
+	observations := []Observation{}
+	dec := xml.NewDecoder(bytes.NewReader(data))
+
+	for {
+
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "resultrows" {
+			continue
+		}
+
+		var row struct {
+			Date  string `xml:"date"`
+			Value string `xml:"value"`
+		}
+
+		if err := dec.DecodeElement(&row, &start); err != nil {
+			return nil, err
+		}
+
+		v, err := parseNumber(row.Value)
+		if err != nil {
+			continue // No observation
+		}
+
+		observations = append(observations, Observation{
+			Date:  strings.TrimSpace(row.Date),
+			Value: v,
+		})
+
+	}
+
+	return observations, nil
+}
+
+func parseCSV(data []byte) ([]Observation, error) {
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+
+	if firstLine := strings.SplitN(string(data), "\n", 2)[0]; strings.Contains(firstLine, ";") {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	date, value := -1, -1
+
+	for i, h := range header {
+
+		switch strings.ToLower(strings.TrimSpace(h)) {
+		case "date", "datum", "period":
+			date = i
+		case "value", "värde":
+			value = i
+		}
+
+	}
+
+	if date == -1 || value == -1 {
+		return nil, fmt.Errorf("missing date and/or value column in header: %v", header)
+	}
+
+	observations := []Observation{}
+
+	for {
+
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if len(record) <= date || len(record) <= value {
+			continue
+		}
+
+		v, err := parseNumber(record[value])
+		if err != nil {
+			continue // No observation
+		}
+
+		observations = append(observations, Observation{
+			Date:  strings.TrimSpace(record[date]),
+			Value: v,
+		})
+
+	}
+
+	return observations, nil
+}
+
+func parseNumber(s string) (float64, error) {
+
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", ".")
+	return strconv.ParseFloat(s, 64)
+
+}
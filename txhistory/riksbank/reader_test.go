@@ -0,0 +1,115 @@
+package riksbank
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/txhistory/httputils"
+	"github.com/mariotoffia/gocryptoadmin/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFromSweaFillsNonBankingDays(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// Read earlier to have a rate when since is not a banking day
+		assert.Equal(t, "/Observations/SEKEURPMI/2020-12-28/2021-01-11", r.URL.Path)
+		w.Write(utils.ReadFile("testfiles/sekeurpmi.json"))
+
+	}))
+
+	defer srv.Close()
+
+	reader := New(srv.URL).UseClient(httputils.NewClient(srv.Client()))
+	reader.now = func() time.Time { return time.Date(2021, 1, 11, 12, 0, 0, 0, time.UTC) }
+
+	since, _ := time.Parse(time.RFC3339, "2021-01-07T00:00:00Z")
+
+	entries, err := reader.ReadContext(context.Background(), common.AssetPair{
+		Asset:    common.AssetTypeEuro,
+		CostUnit: common.AssetTypeSvenskKrona,
+	}, since, time.Hour*24)
+
+	require.Equal(t, nil, err)
+	require.Equal(t, 5, len(entries))
+
+	assert.Equal(t, float64(10.0655), entries[0].Close)
+	assert.Equal(t, float64(10.0743), entries[1].Close)
+	assert.Equal(t, float64(10.0743), entries[2].Close, "saturday")
+	assert.Equal(t, float64(10.0743), entries[3].Close, "sunday")
+	assert.Equal(t, float64(10.0963), entries[4].Close)
+	assert.Equal(t, "rbk", entries[0].Exchange)
+}
+
+func TestReadSinceWeekendUsesRateBefore(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(utils.ReadFile("testfiles/sekeurpmi.json"))
+	}))
+
+	defer srv.Close()
+
+	reader := New(srv.URL).UseClient(httputils.NewClient(srv.Client()))
+	reader.now = func() time.Time { return time.Date(2021, 1, 11, 12, 0, 0, 0, time.UTC) }
+
+	since, _ := time.Parse(time.RFC3339, "2021-01-10T00:00:00Z")
+
+	entries, err := reader.ReadContext(context.Background(), common.AssetPair{
+		Asset:    common.AssetTypeEuro,
+		CostUnit: common.AssetTypeSvenskKrona,
+	}, since, time.Hour*24)
+
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, len(entries))
+
+	assert.Equal(t, "2021-01-10", entries[0].DateTime.Format("2006-01-02"))
+	assert.Equal(t, float64(10.0743), entries[0].Close, "sunday")
+	assert.Equal(t, float64(10.0963), entries[1].Close)
+}
+
+func TestReadCrossRateFromDownloadedFiles(t *testing.T) {
+
+	reader := New("").
+		UseFile(common.AssetTypeEuro, "testfiles/sekeurpmi.xml").
+		UseFile(common.AssetTypeUsDollar, "testfiles/sekusdpmi.csv")
+
+	reader.now = func() time.Time { return time.Date(2021, 1, 8, 0, 0, 0, 0, time.UTC) }
+
+	since, _ := time.Parse(time.RFC3339, "2021-01-07T00:00:00Z")
+
+	entries, err := reader.ReadContext(context.Background(), common.AssetPair{
+		Asset:    common.AssetTypeEuro,
+		CostUnit: common.AssetTypeUsDollar,
+	}, since, time.Hour*24)
+
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, len(entries))
+
+	assert.InDelta(t, 10.0655/8.2029, entries[0].Close, 1e-12)
+	assert.InDelta(t, 10.0743/8.2239, entries[1].Close, 1e-12)
+}
+
+func TestSeriesUnitIsApplied(t *testing.T) {
+
+	reader := New("").
+		UseFile(common.AssetType("JPY"), "testfiles/sekusdpmi.csv").
+		UseSeriesUnit(common.AssetType("JPY"), 100)
+
+	reader.now = func() time.Time { return time.Date(2021, 1, 7, 0, 0, 0, 0, time.UTC) }
+
+	since, _ := time.Parse(time.RFC3339, "2021-01-07T00:00:00Z")
+
+	entries := reader.Read(common.AssetPair{
+		Asset:    common.AssetTypeSvenskKrona,
+		CostUnit: common.AssetType("JPY"),
+	}, since, time.Hour*24)
+
+	require.Equal(t, 2, len(entries))
+	assert.InDelta(t, 100/8.2029, entries[0].Close, 1e-12)
+}
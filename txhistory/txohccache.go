@@ -22,18 +22,47 @@ type ExchangeOHCEntries struct {
 //
 // `common.ExchangeAll` can used when global rates
 // is accepted.
+//
+// Entries of different resolutions may be mixed for the same exchange and
+// `common.AssetPair`. When a preferred resolution is set, using
+// `UsePreferredResolution`, lookups will resample entries to that resolution
+// (see `GetEntryForAssetWithResolution`).
 type TxOHCCache struct {
-	entries map[string]*ExchangeOHCEntries
+	entries   map[string]*ExchangeOHCEntries
+	preferred int
+	resampled map[string][]common.TxOHCHistory
 }
 
 func NewTxOHCCache() *TxOHCCache {
 
 	return &TxOHCCache{
-		entries: map[string]*ExchangeOHCEntries{},
+		entries:   map[string]*ExchangeOHCEntries{},
+		resampled: map[string][]common.TxOHCHistory{},
 	}
 
 }
 
+// UsePreferredResolution makes `GetEntryForAssset` to use `GetEntryForAssetWithResolution`
+// with _resolution_ (in minutes). If zero, the entries are used as is, regardless of
+// their resolution (default).
+func (cache *TxOHCCache) UsePreferredResolution(resolution int) *TxOHCCache {
+
+	cache.preferred = resolution
+	return cache
+
+}
+
+// GetResolutions returns all resolutions (in minutes) that exists for the _assetPair_
+// on the _exchange_ in ascending order.
+func (cache *TxOHCCache) GetResolutions(assetPair common.AssetPair, exchange string) []int {
+
+	if entries, ok := cache.entries[exchange]; ok {
+		return resolutionsOf(entries.entries[assetPair.String()])
+	}
+
+	return []int{}
+}
+
 func (cache *TxOHCCache) GetExchanges(except ...string) []string {
 
 	exchanges := []string{}
@@ -57,6 +86,29 @@ func (cache *TxOHCCache) GetExchanges(except ...string) []string {
 	return exchanges
 }
 
+// expandExchanges replaces any `common.ExchangeAny` in _exchange_ with all
+// exchanges in the cache (sorted) that are not already part of _exchange_.
+func (cache *TxOHCCache) expandExchanges(exchange []string) []string {
+
+	expanded := make([]string, 0, len(exchange))
+
+	for _, ex := range exchange {
+
+		if ex != common.ExchangeAny {
+			expanded = append(expanded, ex)
+			continue
+		}
+
+		rest := cache.GetExchanges(exchange...)
+		sort.Strings(rest)
+
+		expanded = append(expanded, rest...)
+
+	}
+
+	return expanded
+}
+
 func (cache *TxOHCCache) Clear(path string, except ...string) *TxOHCCache {
 
 	err := filepath.Walk(path, func(path string, info fs.FileInfo, err error) error {
@@ -176,10 +228,19 @@ func (cache *TxOHCCache) GetEntryForAssset(
 	exchange ...string,
 ) (*common.TxOHCHistory, string) {
 
+	if cache.preferred > 0 {
+
+		return cache.GetEntryForAssetWithResolution(
+			assetPair, at, cache.preferred, exchange...,
+		)
+
+	}
+
 	if len(exchange) == 0 {
 		exchange = []string{common.ExchangeAll}
 	}
 
+	exchange = cache.expandExchanges(exchange)
 	ap := assetPair.String()
 
 	for _, ex := range exchange {
@@ -203,6 +264,176 @@ func (cache *TxOHCCache) GetEntryForAssset(
 	return nil, ""
 }
 
+// GetEntryForAssetWithResolution is the same as `GetEntryForAssset` but it will look for
+// an entry of _resolution_ minutes on each exchange in the following order.
+//
+// 1. Entries of exactly _resolution_
+// 2. Finer entries (coarsest first) that are resampled into _resolution_
+// 3. Coarser entries (finest first) as is
+//
+// Finer entries are only used when _resolution_ is a multiple of their resolution.
+func (cache *TxOHCCache) GetEntryForAssetWithResolution(
+	assetPair common.AssetPair,
+	at time.Time,
+	resolution int,
+	exchange ...string,
+) (*common.TxOHCHistory, string) {
+
+	if len(exchange) == 0 {
+		exchange = []string{common.ExchangeAll}
+	}
+
+	exchange = cache.expandExchanges(exchange)
+	ap := assetPair.String()
+
+	for _, ex := range exchange {
+
+		entries, ok := cache.entries[ex]
+		if !ok {
+			continue
+		}
+
+		c, ok := entries.entries[ap]
+		if !ok {
+			continue
+		}
+
+		for _, res := range lookupOrder(resolutionsOf(c), resolution) {
+
+			if entry, ok := cache.FindEntry(
+				cache.getResampled(ex, ap, c, res, resolution), at,
+			); ok {
+
+				return entry, ex
+
+			}
+
+		}
+
+	}
+
+	return nil, ""
+}
+
+// GetNextEntry returns the entry, of the same resolution, that follows _entry_
+// on the _exchange_.
+//
+// If _entry_ is a resampled entry, the next entry is resampled from the same
+// finer entries.
+func (cache *TxOHCCache) GetNextEntry(
+	entry *common.TxOHCHistory,
+	exchange string,
+) (*common.TxOHCHistory, bool) {
+
+	entries, ok := cache.entries[exchange]
+	if !ok {
+		return nil, false
+	}
+
+	ap := entry.GetAssetPair().String()
+
+	c, ok := entries.entries[ap]
+	if !ok {
+		return nil, false
+	}
+
+	for _, res := range lookupOrder(resolutionsOf(c), entry.Resolution) {
+
+		if res > entry.Resolution {
+			break
+		}
+
+		list := cache.getResampled(exchange, ap, c, res, entry.Resolution)
+
+		for i := range list {
+
+			if list[i].DateTime.After(entry.DateTime) {
+				return &list[i], true
+			}
+
+		}
+
+	}
+
+	return nil, false
+}
+
+// lookupOrder orders the available _resolutions_ by the order
+// they should be tried when _wanted_ is requested.
+func lookupOrder(resolutions []int, wanted int) []int {
+
+	order := []int{}
+
+	for _, res := range resolutions {
+
+		if res == wanted {
+			order = append(order, res)
+		}
+
+	}
+
+	for i := len(resolutions) - 1; i >= 0; i-- {
+
+		if res := resolutions[i]; res < wanted && res > 0 && wanted%res == 0 {
+			order = append(order, res)
+		}
+
+	}
+
+	for _, res := range resolutions {
+
+		if res > wanted {
+			order = append(order, res)
+		}
+
+	}
+
+	return order
+}
+
+// getResampled returns the entries of _resolution_ when _resolution_ is equal or coarser
+// than _wanted_. Otherwise it will resample the entries of _resolution_ into _wanted_.
+func (cache *TxOHCCache) getResampled(
+	exchange, assetPair string,
+	entries []common.TxOHCHistory,
+	resolution, wanted int,
+) []common.TxOHCHistory {
+
+	key := fmt.Sprintf("%s|%s|%d|%d", exchange, assetPair, resolution, wanted)
+
+	if list, ok := cache.resampled[key]; ok {
+		return list
+	}
+
+	list := []common.TxOHCHistory{}
+	for i := range entries {
+
+		if entries[i].Resolution == resolution {
+			list = append(list, entries[i])
+		}
+
+	}
+
+	if resolution < wanted {
+
+		list = Resample(list, wanted)
+
+	} else {
+
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].DateTime.Before(list[j].DateTime)
+		})
+
+	}
+
+	cache.resampled[key] = list
+	return list
+}
+
+// FindEntry finds the entry that _at_ is within in the ascending sorted _entries_.
+//
+// When _at_ is after the last entry, the last entry is only returned when _at_ is
+// within its resolution period.
 func (cache *TxOHCCache) FindEntry(
 	entries []common.TxOHCHistory,
 	at time.Time,
@@ -230,12 +461,27 @@ func (cache *TxOHCCache) FindEntry(
 
 	}
 
+	if found == nil && len(entries) > 0 {
+
+		last := &entries[len(entries)-1]
+		end := last.DateTime.Add(time.Duration(last.Resolution) * time.Minute)
+
+		if at.After(last.DateTime) && at.Before(end) {
+			found = last
+		}
+
+	}
+
 	return found, found != nil
 
 }
 
 func (cache *TxOHCCache) Add(entries []common.TxOHCHistory, exchange ...string) *TxOHCCache {
 
+	if len(entries) > 0 {
+		cache.resampled = map[string][]common.TxOHCHistory{}
+	}
+
 	for i := range entries {
 
 		ap := entries[i].GetAssetPair().String()
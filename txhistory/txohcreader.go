@@ -1,6 +1,8 @@
 package txhistory
 
 import (
+	"context"
+	"fmt"
 	"sort"
 	"time"
 
@@ -35,12 +37,48 @@ func (txr *TxOHCReader) Read(
 	reader ...string,
 ) []common.TxOHCHistory {
 
+	list, err := txr.ReadContext(context.Background(), pair, since, interval, reader...)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return list
+}
+
+// ReadContext is the same as `Read` but it will pass the _ctx_ to all readers that
+// implements the `common.TxOHCContextReader` and return any error instead of
+// panicking.
+func (txr *TxOHCReader) ReadContext(
+	ctx context.Context,
+	pair common.AssetPair,
+	since time.Time,
+	interval time.Duration,
+	reader ...string,
+) ([]common.TxOHCHistory, error) {
+
 	list := []common.TxOHCHistory{}
 
 	for i := range reader {
 
-		entries := txr.readers[reader[i]].Read(pair, since, interval)
-		list = append(list, entries...)
+		r, ok := txr.readers[reader[i]]
+		if !ok {
+			return nil, fmt.Errorf("could not find reader named: %s", reader[i])
+		}
+
+		if cr, ok := r.(common.TxOHCContextReader); ok {
+
+			entries, err := cr.ReadContext(ctx, pair, since, interval)
+			if err != nil {
+				return nil, err
+			}
+
+			list = append(list, entries...)
+			continue
+
+		}
+
+		list = append(list, r.Read(pair, since, interval)...)
 
 	}
 
@@ -52,5 +90,5 @@ func (txr *TxOHCReader) Read(
 
 	})
 
-	return list
+	return list, nil
 }
@@ -58,6 +58,9 @@ type ExchangeAssetTranslation map[string]AssetTranslation
 // first tried with the submitted _exchange_ parameter, if fails, it will try
 // `common.ExchangeAll` (if _all_ is submitted).
 //
+// The wildcard prefix `common.ExchangeAny` (_*_) before equal sign is used when
+// no other prefix matched. After equal sign, it matches any exchange in cache.
+//
 // All patterns are terminated with a new-line.
 type TxOHCResolver struct {
 	assets ExchangeAssetTranslation
@@ -124,11 +127,17 @@ func (resolver *TxOHCResolver) AddTranslations(expr ...parsers.ResolverExpressio
 	return resolver
 }
 
+// ResolvedOHCEntry is a single resolved hop in a resolve path.
 type ResolvedOHCEntry struct {
-	Entry             common.TxOHCHistoryEntry
-	Exchange          string
+	Entry     common.TxOHCHistoryEntry
+	Exchange  string
+	AssetPair common.AssetPair
+	// At is the point in time that was resolved.
+	At time.Time
+	// Next is the entry following `Entry` (if any). This is useful when
+	// interpolating between two entries.
+	Next              common.TxOHCHistoryEntry
 	exchangeSelection []string
-	AssetPair         common.AssetPair
 }
 
 // ResolveTarget will search beginning with _asset_ and path down to _target_ by
@@ -215,7 +224,7 @@ func (resolver *TxOHCResolver) Resolve(
 	var res []ResolvedOHCEntry
 
 	walkedPath := false
-	for _, ex := range exchange {
+	for _, ex := range append(append([]string{}, exchange...), common.ExchangeAny) {
 
 		if walkedPath {
 			break
@@ -237,12 +246,19 @@ func (resolver *TxOHCResolver) Resolve(
 						return nil, false
 					}
 
-					res = append(res, ResolvedOHCEntry{
+					resolved := ResolvedOHCEntry{
 						Entry:             entry,
 						Exchange:          foundExchange,
 						AssetPair:         path.AssetPair,
+						At:                at,
 						exchangeSelection: path.AssetPrefixes,
-					})
+					}
+
+					if next, ok := resolver.cache.GetNextEntry(entry, foundExchange); ok {
+						resolved.Next = next
+					}
+
+					res = append(res, resolved)
 
 					switch accept(path, entry) {
 					case ResolveAcceptResultFail:
@@ -136,14 +136,15 @@ func (c *bst) Transform(v *BstTransaction, sideIdentifier string) common.Transac
 
 	}
 
-	if fee, costunit, pok := toAmountAndAssetType(v.Fee); pok {
+	if fee, feeAsset, pok := toAmountAndAssetType(v.Fee); pok {
 
 		tx.Fee = fee
-		tx.CostUnit = costunit
+		tx.FeeAsset = feeAsset
 
 	}
 
-	if tx.TotalPrice != 0 && tx.Fee != 0 {
+	// Fee is only included in total when same asset as the cost unit.
+	if tx.TotalPrice != 0 && tx.Fee != 0 && tx.GetFeeAsset() == tx.CostUnit {
 
 		tx.TotalPrice = toTotalPrice(tx.TotalPrice, tx.Fee, tx.Side)
 
@@ -107,6 +107,7 @@ func (c *btx) Transform(v *BtxTransaction, sideIdentifier string) common.Transac
 		AssetPair:      toAssetPair(v.Pair),
 	}
 
+	tx.FeeAsset = tx.CostUnit
 	tx.TotalPrice = toTotalPrice(v.Total, v.Fee, tx.Side)
 
 	if tx.Side == common.SideTypeBuy || tx.Side == common.SideTypeTransfer {
@@ -0,0 +1,334 @@
+// Package canonical implements the _gocryptoadmin_ canonical _CSV_ format for
+// `common.TransactionLog`. All fields, including the translated prices, fees and
+// provenance, are written so that reading it back yields the same transactions.
+//
+// The header has the fixed columns in `Header` followed by three columns for each
+// translated asset: _total:EUR_, _fee:EUR_ and _provenance:EUR_ (the
+// `common.TranslationProvenance` as _JSON_). The translated assets are sorted.
+//
+// Time is written as _RFC3339_ with nanoseconds in _UTC_ and floats with the shortest
+// representation that parses back to the same value. Empty translated columns means
+// that the transaction has no translation into that asset.
+package canonical
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+)
+
+// Header is the fixed columns, in order.
+var Header = []string{
+	"id", "exchange", "side", "sideid", "created", "asset", "costunit",
+	"size", "price", "fee", "feeasset", "total",
+}
+
+const (
+	totalPrefix      = "total:"
+	feePrefix        = "fee:"
+	provenancePrefix = "provenance:"
+)
+
+// reader implements the `common.TransactionLogReader` interface
+type reader struct {
+	exchange string
+}
+
+// NewTransactionLogReader creates a reader of the canonical format.
+//
+// The exchange column is kept as is. Only rows with a empty exchange gets the name set
+// by `SetExchange`.
+func NewTransactionLogReader() common.TransactionLogReader {
+	return &reader{exchange: "gca"}
+}
+
+func (r *reader) SetExchange(name string) common.TransactionLogReader {
+
+	r.exchange = name
+
+	return r
+}
+
+func (r *reader) Unmarshal(data []byte) []common.TransactionLog {
+
+	tx, err := Read(bytes.NewReader(data))
+
+	if err != nil {
+		panic(err)
+	}
+
+	for i := range tx {
+
+		if tx[i].Exchange == "" {
+			tx[i].Exchange = r.exchange
+		}
+
+	}
+
+	return tx
+}
+
+// Marshal renders _tx_ in the canonical format.
+func Marshal(tx []common.TransactionLog) ([]byte, error) {
+
+	var buff bytes.Buffer
+
+	if err := Write(&buff, tx); err != nil {
+		return nil, err
+	}
+
+	return buff.Bytes(), nil
+}
+
+// Write writes _tx_, including the header, in the canonical format onto _w_.
+func Write(w io.Writer, tx []common.TransactionLog) error {
+
+	assets := translatedAssets(tx)
+
+	header := append([]string{}, Header...)
+	for _, asset := range assets {
+		header = append(header, totalPrefix+asset, feePrefix+asset, provenancePrefix+asset)
+	}
+
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for i := range tx {
+
+		row, err := marshalRow(&tx[i], assets)
+		if err != nil {
+			return fmt.Errorf("[id: %s] %s", tx[i].ID, err.Error())
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// Read reads transactions in the canonical format.
+func Read(r io.Reader) ([]common.TransactionLog, error) {
+
+	cr := csv.NewReader(r)
+
+	header, err := cr.Read()
+
+	if err == io.EOF {
+		return []common.TransactionLog{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(header) < len(Header) || strings.Join(header[:len(Header)], ",") != strings.Join(Header, ",") {
+		return nil, fmt.Errorf("not a canonical header: %s", strings.Join(header, ","))
+	}
+
+	tx := []common.TransactionLog{}
+
+	for line := 2; ; line++ {
+
+		row, err := cr.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		log, err := unmarshalRow(header, row)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err.Error())
+		}
+
+		tx = append(tx, log)
+	}
+
+	return tx, nil
+}
+
+func marshalRow(tx *common.TransactionLog, assets []string) ([]string, error) {
+
+	row := []string{
+		tx.ID,
+		tx.Exchange,
+		string(tx.Side),
+		tx.SideIdentifier,
+		tx.CreatedAt.UTC().Format(time.RFC3339Nano),
+		string(tx.Asset),
+		string(tx.CostUnit),
+		formatFloat(tx.AssetSize),
+		formatFloat(tx.PricePerUnit),
+		formatFloat(tx.Fee),
+		string(tx.FeeAsset),
+		formatFloat(tx.TotalPrice),
+	}
+
+	for _, asset := range assets {
+
+		total, ok := tx.TranslatedTotalPrice[asset]
+		if !ok {
+
+			row = append(row, "", "", "")
+			continue
+
+		}
+
+		provenance := ""
+
+		if p, ok := tx.Provenance[asset]; ok {
+
+			data, err := json.Marshal(p)
+			if err != nil {
+				return nil, err
+			}
+
+			provenance = string(data)
+
+		}
+
+		row = append(row, formatFloat(total), formatFloat(tx.TranslatedFee[asset]), provenance)
+	}
+
+	return row, nil
+}
+
+func unmarshalRow(header, row []string) (common.TransactionLog, error) {
+
+	created, err := time.Parse(time.RFC3339Nano, row[4])
+	if err != nil {
+		return common.TransactionLog{}, err
+	}
+
+	tx := common.TransactionLog{
+		ID:             row[0],
+		Exchange:       row[1],
+		Side:           common.SideType(row[2]),
+		SideIdentifier: row[3],
+		CreatedAt:      created,
+		FeeAsset:       common.AssetType(row[10]),
+		AssetPair: common.AssetPair{
+			Asset:    common.AssetType(row[5]),
+			CostUnit: common.AssetType(row[6]),
+		},
+	}
+
+	fields := []*float64{&tx.AssetSize, &tx.PricePerUnit, &tx.Fee, nil, &tx.TotalPrice}
+
+	for i, field := range fields {
+
+		if field == nil {
+			continue
+		}
+
+		if *field, err = parseFloat(header[7+i], row[7+i]); err != nil {
+			return common.TransactionLog{}, err
+		}
+
+	}
+
+	for i := len(Header); i < len(header); i++ {
+
+		if row[i] == "" {
+			continue
+		}
+
+		if tx.TranslatedTotalPrice == nil {
+
+			tx.TranslatedTotalPrice = map[string]float64{}
+			tx.TranslatedFee = map[string]float64{}
+
+		}
+
+		switch {
+		case strings.HasPrefix(header[i], totalPrefix):
+
+			asset := strings.TrimPrefix(header[i], totalPrefix)
+			if tx.TranslatedTotalPrice[asset], err = parseFloat(header[i], row[i]); err != nil {
+				return common.TransactionLog{}, err
+			}
+
+		case strings.HasPrefix(header[i], feePrefix):
+
+			asset := strings.TrimPrefix(header[i], feePrefix)
+			if tx.TranslatedFee[asset], err = parseFloat(header[i], row[i]); err != nil {
+				return common.TransactionLog{}, err
+			}
+
+		case strings.HasPrefix(header[i], provenancePrefix):
+
+			var p common.TranslationProvenance
+			if err := json.Unmarshal([]byte(row[i]), &p); err != nil {
+				return common.TransactionLog{}, fmt.Errorf("%s: %s", header[i], err.Error())
+			}
+
+			if tx.Provenance == nil {
+				tx.Provenance = map[string]common.TranslationProvenance{}
+			}
+
+			tx.Provenance[strings.TrimPrefix(header[i], provenancePrefix)] = p
+
+		default:
+			return common.TransactionLog{}, fmt.Errorf("unknown column: %s", header[i])
+		}
+
+	}
+
+	return tx, nil
+}
+
+func translatedAssets(tx []common.TransactionLog) []string {
+
+	seen := map[string]bool{}
+	assets := []string{}
+
+	for i := range tx {
+
+		for asset := range tx[i].TranslatedTotalPrice {
+
+			if !seen[asset] {
+
+				seen[asset] = true
+				assets = append(assets, asset)
+
+			}
+
+		}
+
+	}
+
+	sort.Strings(assets)
+	return assets
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func parseFloat(column, s string) (float64, error) {
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %s", column, err.Error())
+	}
+
+	return f, nil
+}
@@ -0,0 +1,120 @@
+package canonical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/txlog"
+	"github.com/mariotoffia/gocryptoadmin/txlog/coinbasepro"
+	"github.com/mariotoffia/gocryptoadmin/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTripIsLossless(t *testing.T) {
+
+	tx := coinbasepro.NewTransactionLogReader().
+		Unmarshal(utils.ReadFile("../testfiles/cbx/cbx_buysell.csv"))
+
+	require.Equal(t, 3, len(tx))
+
+	candle, _ := time.Parse(time.RFC3339, "2019-06-26T00:00:00Z")
+
+	tx[0].SideIdentifier = "grp,1"
+	tx[0].TranslatedTotalPrice = map[string]float64{"EUR": -201.337884495, "SEK": -2123.1}
+	tx[0].TranslatedFee = map[string]float64{"EUR": 0.301554495, "SEK": 3.1}
+	tx[0].Provenance = map[string]common.TranslationProvenance{
+		"SEK": {
+			TxID:       tx[0].ID,
+			Asset:      common.AssetTypeSvenskKrona,
+			Calculator: "midpoint",
+			Hops: []common.TranslationHop{{
+				Exchange:   "ofx",
+				AssetPair:  common.AssetPair{Asset: common.AssetTypeEuro, CostUnit: common.AssetTypeSvenskKrona},
+				CandleTime: candle,
+				Resolution: 1440,
+				Price:      10.545,
+				Source:     common.TranslationSourceCandle,
+			}},
+		},
+	}
+
+	tx[1].TranslatedTotalPrice = map[string]float64{"EUR": 14.878898125}
+	tx[1].TranslatedFee = map[string]float64{"EUR": 0.022351875}
+
+	data, err := Marshal(tx)
+	require.NoError(t, err)
+
+	lines := strings.Split(string(data), "\n")
+	assert.Equal(t,
+		"id,exchange,side,sideid,created,asset,costunit,size,price,fee,feeasset,total,"+
+			"total:EUR,fee:EUR,provenance:EUR,total:SEK,fee:SEK,provenance:SEK",
+		lines[0],
+	)
+
+	back, err := Read(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, len(tx), len(back))
+
+	for i := range tx {
+
+		assert.True(t, tx[i].CreatedAt.Equal(back[i].CreatedAt))
+		back[i].CreatedAt = tx[i].CreatedAt
+
+		assert.Equal(t, tx[i], back[i])
+
+	}
+
+}
+
+func TestReadThroughTxLogReader(t *testing.T) {
+
+	created, _ := time.Parse(time.RFC3339, "2021-01-01T10:00:00Z")
+
+	data, err := Marshal([]common.TransactionLog{
+		{
+			ID: "1", Exchange: "krk", Side: common.SideTypeBuy, CreatedAt: created,
+			AssetSize: 1, PricePerUnit: 100, Fee: 0.1, FeeAsset: common.AssetTypeBTC, TotalPrice: -100,
+			AssetPair: common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeEuro},
+		},
+		{
+			ID: "2", Side: common.SideTypeSell, CreatedAt: created.Add(time.Hour),
+			AssetSize: 1, PricePerUnit: 110, TotalPrice: 110,
+			AssetPair: common.AssetPair{Asset: common.AssetTypeBTC, CostUnit: common.AssetTypeEuro},
+		},
+	})
+
+	require.NoError(t, err)
+
+	tx := txlog.NewTxLogReader(nil).
+		RegisterReader("gca", NewTransactionLogReader()).
+		ReadBuffer("gca", data)
+
+	require.Equal(t, 2, len(tx))
+
+	// Exchange column is kept and only a empty one is set from the reader name
+	assert.Equal(t, "krk", tx[0].Exchange)
+	assert.Equal(t, "gca", tx[1].Exchange)
+	assert.Equal(t, common.AssetTypeBTC, tx[0].GetFeeAsset())
+	assert.Equal(t, 0.1, tx[0].Fee)
+
+}
+
+func TestReadRejectsInvalidData(t *testing.T) {
+
+	_, err := Read(strings.NewReader("trade id,side\n1,BUY\n"))
+	require.Error(t, err)
+	assert.Equal(t, "not a canonical header: trade id,side", err.Error())
+
+	_, err = Read(strings.NewReader(
+		"id,exchange,side,sideid,created,asset,costunit,size,price,fee,feeasset,total\n" +
+			"1,cbx,BUY,,2021-01-01T00:00:00Z,BTC,EUR,abc,1,0,EUR,-1\n",
+	))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2: size:")
+
+}
@@ -90,6 +90,7 @@ func (c *cbp) Transform(v *CbpTransaction, sideIdentifier string) common.Transac
 		AssetSize:      v.Size,
 		PricePerUnit:   v.Price,
 		Fee:            v.Fee,
+		FeeAsset:       common.AssetType(v.CostUnit),
 		TotalPrice:     v.Total,
 		AssetPair: common.AssetPair{
 			Asset:    common.AssetType(v.Unit),
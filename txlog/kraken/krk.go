@@ -106,6 +106,7 @@ func (c *krk) Transform(v *KrkTransaction, sideIdentifier string) common.Transac
 		AssetPair:      toAssetPair(v.Pair),
 	}
 
+	tx.FeeAsset = tx.CostUnit
 	tx.TotalPrice = toTotalPrice(v.Total, v.Fee, tx.Side)
 
 	if tx.Side == common.SideTypeBuy || tx.Side == common.SideTypeTransfer {
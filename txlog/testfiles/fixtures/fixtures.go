@@ -0,0 +1,78 @@
+// Package fixtures reads the exchange test files in _txlog/testfiles_ so that tests
+// in any package may share the same transactions.
+package fixtures
+
+import (
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/mariotoffia/gocryptoadmin/common"
+	"github.com/mariotoffia/gocryptoadmin/parsers"
+	"github.com/mariotoffia/gocryptoadmin/processors"
+	"github.com/mariotoffia/gocryptoadmin/txhistory"
+	"github.com/mariotoffia/gocryptoadmin/txlog"
+	"github.com/mariotoffia/gocryptoadmin/txlog/bitstamp"
+	"github.com/mariotoffia/gocryptoadmin/txlog/coinbasepro"
+	"github.com/mariotoffia/gocryptoadmin/utils"
+)
+
+// EURSEK is the fixed _EUR-SEK_ rate used when translating into _SEK_.
+const EURSEK = 10
+
+// Path returns the absolute path to the _file_ relative the _txlog/testfiles_ directory,
+// e.g. _cbx/cbx_buysell.csv_.
+func Path(file string) string {
+
+	_, self, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(self), "..", file)
+
+}
+
+// CoinbasePro reads the _coinbase pro_ buy and sell test file as exchange _cbx_, translated
+// into _asset_. It is either `common.AssetTypeEuro` or `common.AssetTypeSvenskKrona`, the
+// latter uses the `EURSEK` rate.
+//
+// The transactions are the buy followed by the two sells.
+func CoinbasePro(asset common.AssetType) []common.TransactionLog {
+
+	day, _ := time.Parse(time.RFC3339, "2019-06-26T00:00:00Z")
+
+	cache := txhistory.NewTxOHCCache().Add([]common.TxOHCHistory{{
+		Exchange: "ecb", Resolution: 1440, DateTime: day, Open: EURSEK, High: EURSEK, Low: EURSEK, Close: EURSEK,
+		AssetPair: common.AssetPair{Asset: common.AssetTypeEuro, CostUnit: common.AssetTypeSvenskKrona},
+	}})
+
+	resolver := txhistory.NewTxOHCResolver(cache).AddTranslations(
+		parsers.NewResolverParser().Parse("EUR = ecb:SEK").GetExpressions()...,
+	)
+
+	proc := processors.NewCostUnitProcessorByName(resolver, processors.PriceCalculatorClose)
+	proc.RegisterAsset(asset)
+
+	return txlog.NewTxLogReader(proc).
+		RegisterReader("cbx", coinbasepro.NewTransactionLogReader()).
+		ReadBufferAsExchange("cbx", utils.ReadFile(Path("cbx/cbx_buysell.csv")))
+
+}
+
+// Bitstamp reads the _bitstamp_ buy, sell, transfer and receive test file as _exchange_. The
+// transactions are sorted but not translated.
+func Bitstamp(exchange string) []common.TransactionLog {
+
+	return txlog.NewTxLogReader(processors.NewChronologicalTxEntryProcessor()).
+		RegisterReader(exchange, bitstamp.NewTransactionLogReader()).
+		ReadBufferAsExchange(exchange, utils.ReadFile(Path("bst/bst_buyselltransferrecieve.csv")))
+
+}
+
+// Entries returns the _tx_ as `common.TransactionEntry` instances pointing into _tx_.
+func Entries(tx []common.TransactionLog) []common.TransactionEntry {
+
+	entries := make([]common.TransactionEntry, len(tx))
+	for i := range tx {
+		entries[i] = &tx[i]
+	}
+
+	return entries
+}
@@ -180,6 +180,20 @@ func logReaderNameFromFileName(name string) string {
 	return strings.SplitN(name, "_", 2)[0]
 }
 
+// ReaderNameFromFileName returns the reader name, i.e. the prefix before the first
+// underscore, that `Read` uses to select reader for a file, e.g. _cbx_ for _cbx_2021.csv_.
+func ReaderNameFromFileName(name string) string {
+	return logReaderNameFromFileName(filepath.Base(name))
+}
+
+// HasReader returns `true` if a reader is registered under _name_.
+func (lr *TxLogReaderImpl) HasReader(name string) bool {
+
+	_, ok := lr.readers[name]
+	return ok
+
+}
+
 func (lr *TxLogReaderImpl) preProcess(logs []common.TransactionLog) []common.TransactionLog {
 
 	if lr.postProcessor == nil {